@@ -0,0 +1,112 @@
+package inMemoryAPIKeyStorage
+
+import (
+	"fmt"
+	"geo/db/apiKeyStorage"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Storage struct {
+	// Keys maps the id to the key
+	Keys map[string]apiKeyStorage.Key
+	// hashes maps the hash of the secret to the id
+	hashes map[string]string
+	mu     sync.RWMutex
+}
+
+func New() *Storage {
+	return &Storage{
+		Keys:   make(map[string]apiKeyStorage.Key, 100),
+		hashes: make(map[string]string, 100),
+	}
+}
+
+func (s *Storage) Add(k apiKeyStorage.Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.Keys[k.ID]; exists {
+		return fmt.Errorf("api key \"%s\": %w", k.ID, apiKeyStorage.ErrAlreadyExists)
+	}
+	if _, exists := s.hashes[k.Hash]; exists {
+		return fmt.Errorf("api key \"%s\" hash: %w", k.ID, apiKeyStorage.ErrAlreadyExists)
+	}
+	s.Keys[k.ID] = clone(k)
+	s.hashes[k.Hash] = k.ID
+	return nil
+}
+
+func (s *Storage) Get(owner, id string) (apiKeyStorage.Key, error) {
+	s.mu.RLock()
+	k, ok := s.Keys[id]
+	s.mu.RUnlock()
+	if !ok || k.Owner != owner {
+		return apiKeyStorage.Key{}, fmt.Errorf("api key \"%s\": %w", id, apiKeyStorage.ErrNotFound)
+	}
+	return clone(k), nil
+}
+
+// ByHash returns the key with the secret hash and records that it was used at usedAt.
+func (s *Storage) ByHash(hash string, usedAt time.Time) (apiKeyStorage.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.hashes[hash]
+	if !ok {
+		return apiKeyStorage.Key{}, fmt.Errorf("api key hash: %w", apiKeyStorage.ErrNotFound)
+	}
+	k := s.Keys[id]
+	k.LastUsedAt = usedAt
+	s.Keys[id] = k
+	return clone(k), nil
+}
+
+// List returns the keys of owner, oldest first.
+func (s *Storage) List(owner string) ([]apiKeyStorage.Key, error) {
+	s.mu.RLock()
+	var res []apiKeyStorage.Key
+	for _, k := range s.Keys {
+		if k.Owner == owner {
+			res = append(res, clone(k))
+		}
+	}
+	s.mu.RUnlock()
+	slices.SortFunc(res, func(a, b apiKeyStorage.Key) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res, nil
+}
+
+func (s *Storage) SetLabel(owner, id, label string) (apiKeyStorage.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.Keys[id]
+	if !ok || k.Owner != owner {
+		return apiKeyStorage.Key{}, fmt.Errorf("api key \"%s\": %w", id, apiKeyStorage.ErrNotFound)
+	}
+	k.Label = label
+	s.Keys[id] = k
+	return clone(k), nil
+}
+
+func (s *Storage) Delete(owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.Keys[id]
+	if !ok || k.Owner != owner {
+		return fmt.Errorf("api key \"%s\": %w", id, apiKeyStorage.ErrNotFound)
+	}
+	delete(s.Keys, id)
+	delete(s.hashes, k.Hash)
+	return nil
+}
+
+// clone copies the scopes so that the stored key is not shared with callers.
+func clone(k apiKeyStorage.Key) apiKeyStorage.Key {
+	k.Scopes = slices.Clone(k.Scopes)
+	return k
+}
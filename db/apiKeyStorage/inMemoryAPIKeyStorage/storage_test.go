@@ -0,0 +1,74 @@
+package inMemoryAPIKeyStorage
+
+import (
+	"errors"
+	"geo/db/apiKeyStorage"
+	"testing"
+	"time"
+)
+
+func TestStorage_ByHash(t *testing.T) {
+	s := New()
+	k := apiKeyStorage.Key{ID: "1", Owner: "alice", Label: "batch", Hash: "h1", Scopes: []string{"address"}}
+	if err := s.Add(k); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(apiKeyStorage.Key{ID: "2", Owner: "bob", Hash: "h1"}); !errors.Is(err, apiKeyStorage.ErrAlreadyExists) {
+		t.Errorf("Add() with a taken hash error = %v, wantErr %v", err, apiKeyStorage.ErrAlreadyExists)
+	}
+
+	usedAt := time.Now()
+	got, err := s.ByHash("h1", usedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "1" || !got.LastUsedAt.Equal(usedAt) {
+		t.Errorf("ByHash() = %+v", got)
+	}
+	got.Scopes[0] = "changed"
+	if again, _ := s.Get("alice", "1"); again.Scopes[0] != "address" || !again.LastUsedAt.Equal(usedAt) {
+		t.Errorf("Get() = %+v", again)
+	}
+	if _, err := s.ByHash("h2", usedAt); !errors.Is(err, apiKeyStorage.ErrNotFound) {
+		t.Errorf("ByHash() error = %v, wantErr %v", err, apiKeyStorage.ErrNotFound)
+	}
+}
+
+func TestStorage_Owner(t *testing.T) {
+	s := New()
+	now := time.Now()
+	for _, k := range []apiKeyStorage.Key{
+		{ID: "b", Owner: "alice", Hash: "hb", CreatedAt: now},
+		{ID: "a", Owner: "alice", Hash: "ha", CreatedAt: now.Add(-time.Hour)},
+		{ID: "c", Owner: "bob", Hash: "hc", CreatedAt: now},
+	} {
+		if err := s.Add(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := s.List("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID != "a" || keys[1].ID != "b" {
+		t.Errorf("List() = %+v", keys)
+	}
+
+	if _, err := s.SetLabel("bob", "a", "stolen"); !errors.Is(err, apiKeyStorage.ErrNotFound) {
+		t.Errorf("SetLabel() of another owner error = %v, wantErr %v", err, apiKeyStorage.ErrNotFound)
+	}
+	if k, err := s.SetLabel("alice", "a", "nightly"); err != nil || k.Label != "nightly" {
+		t.Errorf("SetLabel() = %+v, %v", k, err)
+	}
+
+	if err := s.Delete("bob", "a"); !errors.Is(err, apiKeyStorage.ErrNotFound) {
+		t.Errorf("Delete() of another owner error = %v, wantErr %v", err, apiKeyStorage.ErrNotFound)
+	}
+	if err := s.Delete("alice", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ByHash("ha", now); !errors.Is(err, apiKeyStorage.ErrNotFound) {
+		t.Errorf("ByHash() of a deleted key error = %v, wantErr %v", err, apiKeyStorage.ErrNotFound)
+	}
+}
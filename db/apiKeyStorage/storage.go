@@ -0,0 +1,25 @@
+package apiKeyStorage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("api key not found")
+	ErrAlreadyExists = errors.New("api key already exists")
+)
+
+// Key is an API key of its owner, the login of the user. Only the hash of the secret is kept.
+type Key struct {
+	ID     string
+	Owner  string
+	Label  string
+	Hash   string
+	Prefix string
+	Scopes []string
+	// ExpiresAt is zero for the keys that never expire
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
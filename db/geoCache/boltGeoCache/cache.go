@@ -0,0 +1,283 @@
+package boltGeoCache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"geo/db/geoCache"
+	"geo/internal/lib/logger/sl"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// entries maps a key to the expiration time followed by the value
+	entries = []byte("entries")
+	// expiry orders the keys by expiration time, its keys are the expiration time followed by the entry key
+	expiry = []byte("expiry")
+)
+
+const stampLen = 8
+
+type Settings struct {
+	// MaxEntries is the maximum number of stored entries, the ones expiring first
+	// are evicted when it is exceeded. Zero means no limit.
+	MaxEntries int
+	// CompactInterval is the period expired entries are removed with, zero disables compaction
+	CompactInterval time.Duration
+}
+
+// Cache is a key-value storage in a bbolt file whose entries expire.
+type Cache struct {
+	log      *slog.Logger
+	db       *bolt.DB
+	settings Settings
+	now      func() time.Time
+
+	// count is only changed within write transactions, update restores it
+	// when a transaction is rolled back
+	count int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// Open opens the file at path, creating it and its directory if needed,
+// and starts the compaction of expired entries.
+func Open(log *slog.Logger, path string, settings Settings) (*Cache, error) {
+	const op = "db.geoCache.boltGeoCache.Open"
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	c := &Cache{
+		log:      log,
+		db:       db,
+		settings: settings,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(entries)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(expiry); err != nil {
+			return err
+		}
+		c.count = b.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if settings.CompactInterval > 0 {
+		c.wg.Add(1)
+		go c.compactLoop()
+	}
+	return c, nil
+}
+
+// Get returns the value stored by key, expired entries are not returned.
+func (c *Cache) Get(key string) ([]byte, error) {
+	const op = "db.geoCache.boltGeoCache.Get"
+	var value []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(entries).Get([]byte(key))
+		if v == nil || !c.now().Before(decodeStamp(v)) {
+			return geoCache.NotFound
+		}
+		value = bytes.Clone(v[stampLen:])
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", op, err, key)
+	}
+	return value, nil
+}
+
+// Put stores the value by key until expiresAt replacing the previous one.
+func (c *Cache) Put(key string, value []byte, expiresAt time.Time) error {
+	const op = "db.geoCache.boltGeoCache.Put"
+	err := c.update(func(tx *bolt.Tx) error {
+		b, idx := tx.Bucket(entries), tx.Bucket(expiry)
+		k := []byte(key)
+		if old := b.Get(k); old != nil {
+			if err := idx.Delete(expiryKey(decodeStamp(old), k)); err != nil {
+				return err
+			}
+		} else {
+			if c.settings.MaxEntries > 0 && c.count >= c.settings.MaxEntries {
+				if err := c.evict(b, idx, c.count-c.settings.MaxEntries+1); err != nil {
+					return err
+				}
+			}
+			c.count++
+		}
+
+		v := make([]byte, stampLen+len(value))
+		binary.BigEndian.PutUint64(v, uint64(expiresAt.UnixNano()))
+		copy(v[stampLen:], value)
+		if err := b.Put(k, v); err != nil {
+			return err
+		}
+		return idx.Put(expiryKey(expiresAt, k), nil)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// evict removes n entries expiring first.
+func (c *Cache) evict(b, idx *bolt.Bucket, n int) error {
+	cur := idx.Cursor()
+	for k, _ := cur.First(); k != nil && n > 0; k, _ = cur.First() {
+		if err := c.remove(b, idx, k); err != nil {
+			return err
+		}
+		n--
+	}
+	return nil
+}
+
+// Compact removes the entries expired by now and returns their number.
+func (c *Cache) Compact() (int, error) {
+	const op = "db.geoCache.boltGeoCache.Compact"
+	removed := 0
+	err := c.update(func(tx *bolt.Tx) error {
+		b, idx := tx.Bucket(entries), tx.Bucket(expiry)
+		bound := make([]byte, stampLen)
+		binary.BigEndian.PutUint64(bound, uint64(c.now().UnixNano()))
+
+		cur := idx.Cursor()
+		for k, _ := cur.First(); k != nil && bytes.Compare(k[:stampLen], bound) <= 0; k, _ = cur.First() {
+			if err := c.remove(b, idx, k); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return removed, nil
+}
+
+func (c *Cache) remove(b, idx *bolt.Bucket, expiryKey []byte) error {
+	// the key is copied as it is only valid within the transaction and is deleted below
+	k := bytes.Clone(expiryKey)
+	if err := idx.Delete(k); err != nil {
+		return err
+	}
+	if err := b.Delete(k[stampLen:]); err != nil {
+		return err
+	}
+	c.count--
+	return nil
+}
+
+// Purge removes every entry.
+func (c *Cache) Purge() error {
+	const op = "db.geoCache.boltGeoCache.Purge"
+	err := c.update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entries, expiry} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		c.count = 0
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// update runs fn in a write transaction and restores the entry count if it is rolled back.
+func (c *Cache) update(fn func(tx *bolt.Tx) error) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		count := c.count
+		if err := fn(tx); err != nil {
+			c.count = count
+			return err
+		}
+		return nil
+	})
+}
+
+// Len returns the number of stored entries including expired ones not compacted yet.
+func (c *Cache) Len() int {
+	n := 0
+	c.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(entries).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// Close stops the compaction and closes the file.
+func (c *Cache) Close() error {
+	const op = "db.geoCache.boltGeoCache.Close"
+	err := geoCache.Closed
+	c.once.Do(func() {
+		close(c.stop)
+		c.wg.Wait()
+		err = c.db.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (c *Cache) compactLoop() {
+	const op = "db.geoCache.boltGeoCache.compactLoop"
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.settings.CompactInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			removed, err := c.Compact()
+			if err != nil {
+				c.log.Error("failed to compact geo cache", slog.String("op", op), sl.Err(err))
+				continue
+			}
+			if removed > 0 {
+				c.log.Debug("geo cache compacted", slog.String("op", op), slog.Int("removed", removed))
+			}
+		}
+	}
+}
+
+func expiryKey(expiresAt time.Time, key []byte) []byte {
+	k := make([]byte, stampLen+len(key))
+	binary.BigEndian.PutUint64(k, uint64(expiresAt.UnixNano()))
+	copy(k[stampLen:], key)
+	return k
+}
+
+func decodeStamp(v []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(v[:stampLen])))
+}
@@ -0,0 +1,155 @@
+package boltGeoCache
+
+import (
+	"errors"
+	"geo/db/geoCache"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func open(t *testing.T, path string, settings Settings) *Cache {
+	t.Helper()
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	c, err := Open(log, path, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCache_GetPut(t *testing.T) {
+	c := open(t, filepath.Join(t.TempDir(), "cache.db"), Settings{})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	if err := c.Put("a", []byte("1"), now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("b", []byte("2"), now); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "valid",
+			key:  "a",
+			want: "1",
+		},
+		{
+			name:    "expired",
+			key:     "b",
+			wantErr: geoCache.NotFound,
+		},
+		{
+			name:    "missing",
+			key:     "c",
+			wantErr: geoCache.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Get(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Get() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCache_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cache.db")
+	c := open(t, path, Settings{})
+	if err := c.Put("a", []byte("1"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); !errors.Is(err, geoCache.Closed) {
+		t.Errorf("Close() error = %v, wantErr %v", err, geoCache.Closed)
+	}
+
+	c = open(t, path, Settings{})
+	got, err := c.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "1" {
+		t.Errorf("Get() got = %q, want %q", got, "1")
+	}
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	c := open(t, filepath.Join(t.TempDir(), "cache.db"), Settings{MaxEntries: 2})
+	now := time.Now()
+	puts := []struct {
+		key string
+		ttl time.Duration
+	}{
+		{key: "a", ttl: 3 * time.Minute},
+		{key: "b", ttl: time.Minute},
+		// replacing an entry does not evict anything
+		{key: "a", ttl: 4 * time.Minute},
+		{key: "c", ttl: 2 * time.Minute},
+	}
+	for _, p := range puts {
+		if err := c.Put(p.key, []byte(p.key), now.Add(p.ttl)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len() got = %d, want 2", n)
+	}
+	// b expires first and is evicted
+	if _, err := c.Get("b"); !errors.Is(err, geoCache.NotFound) {
+		t.Errorf("Get(b) error = %v, wantErr %v", err, geoCache.NotFound)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := c.Get(key); err != nil {
+			t.Errorf("Get(%s) error = %v", key, err)
+		}
+	}
+}
+
+func TestCache_Compact(t *testing.T) {
+	c := open(t, filepath.Join(t.TempDir(), "cache.db"), Settings{})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for i, key := range []string{"a", "b", "c"} {
+		if err := c.Put(key, []byte(key), now.Add(time.Duration(i-1)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removed, err := c.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("Compact() removed = %d, want 2", removed)
+	}
+	if n := c.Len(); n != 1 {
+		t.Errorf("Len() got = %d, want 1", n)
+	}
+
+	if err := c.Purge(); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Len(); n != 0 {
+		t.Errorf("Len() after Purge() got = %d, want 0", n)
+	}
+}
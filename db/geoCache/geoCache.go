@@ -0,0 +1,10 @@
+package geoCache
+
+import (
+	"errors"
+)
+
+var (
+	NotFound = errors.New("cache entry not found")
+	Closed   = errors.New("cache storage closed")
+)
@@ -0,0 +1,92 @@
+package inMemoryPlaceStorage
+
+import (
+	"fmt"
+	"geo/db/placeStorage"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Storage struct {
+	// Places maps the owner to the places by id
+	Places map[string]map[string]placeStorage.Place
+	mu     sync.RWMutex
+}
+
+func New() *Storage {
+	return &Storage{
+		Places: make(map[string]map[string]placeStorage.Place, 100),
+	}
+}
+
+func (s *Storage) Add(p placeStorage.Place) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	places, ok := s.Places[p.Owner]
+	if !ok {
+		places = make(map[string]placeStorage.Place)
+		s.Places[p.Owner] = places
+	}
+	if _, exists := places[p.ID]; exists {
+		return fmt.Errorf("place \"%s\": %w", p.ID, placeStorage.ErrAlreadyExists)
+	}
+	places[p.ID] = clone(p)
+	return nil
+}
+
+func (s *Storage) Get(owner, id string) (placeStorage.Place, error) {
+	s.mu.RLock()
+	p, ok := s.Places[owner][id]
+	s.mu.RUnlock()
+	if !ok {
+		return placeStorage.Place{}, fmt.Errorf("place \"%s\": %w", id, placeStorage.ErrNotFound)
+	}
+	return clone(p), nil
+}
+
+// List returns the places of owner, oldest first.
+func (s *Storage) List(owner string) ([]placeStorage.Place, error) {
+	s.mu.RLock()
+	res := make([]placeStorage.Place, 0, len(s.Places[owner]))
+	for _, p := range s.Places[owner] {
+		res = append(res, clone(p))
+	}
+	s.mu.RUnlock()
+	slices.SortFunc(res, func(a, b placeStorage.Place) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res, nil
+}
+
+func (s *Storage) Update(p placeStorage.Place) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Places[p.Owner][p.ID]; !ok {
+		return fmt.Errorf("place \"%s\": %w", p.ID, placeStorage.ErrNotFound)
+	}
+	s.Places[p.Owner][p.ID] = clone(p)
+	return nil
+}
+
+func (s *Storage) Delete(owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Places[owner][id]; !ok {
+		return fmt.Errorf("place \"%s\": %w", id, placeStorage.ErrNotFound)
+	}
+	delete(s.Places[owner], id)
+	if len(s.Places[owner]) == 0 {
+		delete(s.Places, owner)
+	}
+	return nil
+}
+
+// clone copies the tags so that the stored place is not shared with callers.
+func clone(p placeStorage.Place) placeStorage.Place {
+	p.Tags = slices.Clone(p.Tags)
+	return p
+}
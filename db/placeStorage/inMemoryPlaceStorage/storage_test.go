@@ -0,0 +1,104 @@
+package inMemoryPlaceStorage
+
+import (
+	"errors"
+	"geo/db/placeStorage"
+	"geo/internal/service/geo"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStorage_AddGet(t *testing.T) {
+	s := New()
+	p := placeStorage.Place{
+		ID:      "1",
+		Owner:   "alice",
+		Label:   "depot",
+		Tags:    []string{"work"},
+		Address: geo.Address{City: "Москва", Street: "Снежная", House: "4"},
+	}
+	if err := s.Add(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(p); !errors.Is(err, placeStorage.ErrAlreadyExists) {
+		t.Errorf("Add() error = %v, wantErr %v", err, placeStorage.ErrAlreadyExists)
+	}
+
+	got, err := s.Get("alice", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Label != "depot" || got.Address.Street != "Снежная" {
+		t.Errorf("Get() = %+v", got)
+	}
+	got.Tags[0] = "changed"
+	if again, _ := s.Get("alice", "1"); again.Tags[0] != "work" {
+		t.Errorf("stored tags changed through a returned place: %v", again.Tags)
+	}
+
+	if _, err := s.Get("bob", "1"); !errors.Is(err, placeStorage.ErrNotFound) {
+		t.Errorf("Get() of another owner error = %v, wantErr %v", err, placeStorage.ErrNotFound)
+	}
+}
+
+func TestStorage_List(t *testing.T) {
+	s := New()
+	now := time.Now()
+	for _, p := range []placeStorage.Place{
+		{ID: "b", Owner: "alice", CreatedAt: now},
+		{ID: "c", Owner: "alice", CreatedAt: now.Add(-time.Hour)},
+		{ID: "a", Owner: "alice", CreatedAt: now},
+		{ID: "d", Owner: "bob", CreatedAt: now},
+	} {
+		if err := s.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.List("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, p := range got {
+		ids = append(ids, p.ID)
+	}
+	if want := "c a b"; strings.Join(ids, " ") != want {
+		t.Errorf("List() ids = %v, want %v", strings.Join(ids, " "), want)
+	}
+
+	if got, _ := s.List("nobody"); len(got) != 0 {
+		t.Errorf("List() of unknown owner = %v, want empty", got)
+	}
+}
+
+func TestStorage_UpdateDelete(t *testing.T) {
+	s := New()
+	if err := s.Add(placeStorage.Place{ID: "1", Owner: "alice", Label: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Update(placeStorage.Place{ID: "1", Owner: "bob", Label: "new"}); !errors.Is(err, placeStorage.ErrNotFound) {
+		t.Errorf("Update() of another owner error = %v, wantErr %v", err, placeStorage.ErrNotFound)
+	}
+	if err := s.Update(placeStorage.Place{ID: "1", Owner: "alice", Label: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get("alice", "1"); got.Label != "new" {
+		t.Errorf("Get() label = %v, want new", got.Label)
+	}
+
+	if err := s.Delete("bob", "1"); !errors.Is(err, placeStorage.ErrNotFound) {
+		t.Errorf("Delete() of another owner error = %v, wantErr %v", err, placeStorage.ErrNotFound)
+	}
+	if err := s.Delete("alice", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("alice", "1"); !errors.Is(err, placeStorage.ErrNotFound) {
+		t.Errorf("second Delete() error = %v, wantErr %v", err, placeStorage.ErrNotFound)
+	}
+	if _, ok := s.Places["alice"]; ok {
+		t.Errorf("owner without places is kept")
+	}
+}
@@ -0,0 +1,23 @@
+package placeStorage
+
+import (
+	"errors"
+	"geo/internal/service/geo"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("place not found")
+	ErrAlreadyExists = errors.New("place already exists")
+)
+
+// Place is a place saved by its owner, the login of the user.
+type Place struct {
+	ID        string
+	Owner     string
+	Label     string
+	Tags      []string
+	Address   geo.Address
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,200 @@
+package inMemoryRefreshTokenStorage
+
+import (
+	"fmt"
+	"geo/db/refreshTokenStorage"
+	"sync"
+	"time"
+)
+
+type token struct {
+	refreshTokenStorage.Token
+	used bool
+}
+
+type family struct {
+	revoked bool
+	// expiresAt is the time the last token or access token of the family expires
+	expiresAt time.Time
+	access    map[string]time.Time
+}
+
+type Storage struct {
+	tokens   map[string]*token
+	families map[string]*family
+	// familyOf maps the jti of an access token to its family
+	familyOf map[string]string
+	now      func() time.Time
+	mu       sync.Mutex
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// New returns a storage removing the expired tokens every compactInterval,
+// zero disables the removal. Expired tokens are never returned either way.
+func New(compactInterval time.Duration) *Storage {
+	s := &Storage{
+		tokens:   make(map[string]*token, 100),
+		families: make(map[string]*family, 100),
+		familyOf: make(map[string]string, 100),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+	if compactInterval > 0 {
+		s.wg.Add(1)
+		go s.compactLoop(compactInterval)
+	}
+	return s
+}
+
+// Add stores the token, its family is created if needed.
+func (s *Storage) Add(t refreshTokenStorage.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(t)
+}
+
+func (s *Storage) add(t refreshTokenStorage.Token) error {
+	if _, ok := s.tokens[t.Hash]; ok {
+		return fmt.Errorf("family \"%s\": %w", t.Family, refreshTokenStorage.ErrAlreadyExists)
+	}
+	f, ok := s.families[t.Family]
+	if !ok {
+		f = &family{access: make(map[string]time.Time)}
+		s.families[t.Family] = f
+	}
+	if f.revoked {
+		return fmt.Errorf("family \"%s\": %w", t.Family, refreshTokenStorage.ErrRevoked)
+	}
+	if t.ExpiresAt.After(f.expiresAt) {
+		f.expiresAt = t.ExpiresAt
+	}
+	s.tokens[t.Hash] = &token{Token: t}
+	return nil
+}
+
+// Rotate marks the token with hash used and stores its successor in the same family.
+// The rotated token is returned, also along with ErrUsed when it was already rotated.
+func (s *Storage) Rotate(hash, nextHash string, expiresAt time.Time) (refreshTokenStorage.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[hash]
+	if !ok || !s.now().Before(t.ExpiresAt) {
+		return refreshTokenStorage.Token{}, refreshTokenStorage.ErrNotFound
+	}
+	if s.families[t.Family].revoked {
+		return t.Token, fmt.Errorf("family \"%s\": %w", t.Family, refreshTokenStorage.ErrRevoked)
+	}
+	if t.used {
+		return t.Token, fmt.Errorf("family \"%s\": %w", t.Family, refreshTokenStorage.ErrUsed)
+	}
+	next := refreshTokenStorage.Token{Hash: nextHash, Family: t.Family, Login: t.Login, ExpiresAt: expiresAt}
+	if err := s.add(next); err != nil {
+		return t.Token, err
+	}
+	t.used = true
+	return t.Token, nil
+}
+
+// AddAccessToken records an access token issued along with a token of the family.
+func (s *Storage) AddAccessToken(familyID, jti string, exp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.families[familyID]
+	if !ok {
+		return fmt.Errorf("family \"%s\": %w", familyID, refreshTokenStorage.ErrNotFound)
+	}
+	if f.revoked {
+		return fmt.Errorf("family \"%s\": %w", familyID, refreshTokenStorage.ErrRevoked)
+	}
+	f.access[jti] = exp
+	if exp.After(f.expiresAt) {
+		f.expiresAt = exp
+	}
+	s.familyOf[jti] = familyID
+	return nil
+}
+
+// FamilyOf returns the family the access token was issued from.
+func (s *Storage) FamilyOf(jti string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	familyID, ok := s.familyOf[jti]
+	if f := s.families[familyID]; !ok || f == nil || !s.now().Before(f.access[jti]) {
+		return "", fmt.Errorf("jti \"%s\": %w", jti, refreshTokenStorage.ErrNotFound)
+	}
+	return familyID, nil
+}
+
+// RevokeFamily makes every token of the family unusable and returns
+// the access tokens issued from it that have not expired.
+func (s *Storage) RevokeFamily(familyID string) ([]refreshTokenStorage.AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.families[familyID]
+	if !ok {
+		return nil, fmt.Errorf("family \"%s\": %w", familyID, refreshTokenStorage.ErrNotFound)
+	}
+	f.revoked = true
+	now := s.now()
+	res := make([]refreshTokenStorage.AccessToken, 0, len(f.access))
+	for jti, exp := range f.access {
+		if now.Before(exp) {
+			res = append(res, refreshTokenStorage.AccessToken{JTI: jti, ExpiresAt: exp})
+		}
+		delete(s.familyOf, jti)
+	}
+	clear(f.access)
+	return res, nil
+}
+
+// Compact removes the expired tokens and access tokens, and the families whose tokens all expired.
+// It returns the number of removed tokens.
+func (s *Storage) Compact() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	removed := 0
+	for hash, t := range s.tokens {
+		if !now.Before(t.ExpiresAt) {
+			delete(s.tokens, hash)
+			removed++
+		}
+	}
+	for id, f := range s.families {
+		for jti, exp := range f.access {
+			if !now.Before(exp) {
+				delete(f.access, jti)
+				delete(s.familyOf, jti)
+			}
+		}
+		if !now.Before(f.expiresAt) {
+			delete(s.families, id)
+		}
+	}
+	return removed
+}
+
+// Close stops the removal of the expired tokens.
+func (s *Storage) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+		s.wg.Wait()
+	})
+}
+
+func (s *Storage) compactLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.Compact()
+		}
+	}
+}
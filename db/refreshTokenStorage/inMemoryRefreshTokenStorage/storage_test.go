@@ -0,0 +1,138 @@
+package inMemoryRefreshTokenStorage
+
+import (
+	"errors"
+	"geo/db/refreshTokenStorage"
+	"testing"
+	"time"
+)
+
+func TestStorage_Rotate(t *testing.T) {
+	s := New(0)
+	exp := time.Now().Add(time.Hour)
+	if err := s.Add(refreshTokenStorage.Token{Hash: "a", Family: "f", Login: "user", ExpiresAt: exp}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(refreshTokenStorage.Token{Hash: "a", Family: "f", Login: "user", ExpiresAt: exp}); !errors.Is(err, refreshTokenStorage.ErrAlreadyExists) {
+		t.Errorf("Add() error = %v, wantErr %v", err, refreshTokenStorage.ErrAlreadyExists)
+	}
+
+	got, err := s.Rotate("a", "b", exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Family != "f" || got.Login != "user" {
+		t.Errorf("Rotate() = %+v", got)
+	}
+	if _, err := s.Rotate("b", "c", exp); err != nil {
+		t.Errorf("Rotate() of the successor error = %v", err)
+	}
+
+	got, err = s.Rotate("a", "d", exp)
+	if !errors.Is(err, refreshTokenStorage.ErrUsed) {
+		t.Errorf("Rotate() of a used token error = %v, wantErr %v", err, refreshTokenStorage.ErrUsed)
+	}
+	if got.Family != "f" {
+		t.Errorf("Rotate() of a used token family = %v, want f", got.Family)
+	}
+	if _, err := s.Rotate("d", "e", exp); !errors.Is(err, refreshTokenStorage.ErrNotFound) {
+		t.Errorf("the successor of a used token is stored: error = %v", err)
+	}
+	if _, err := s.Rotate("unknown", "e", exp); !errors.Is(err, refreshTokenStorage.ErrNotFound) {
+		t.Errorf("Rotate() of an unknown token error = %v, wantErr %v", err, refreshTokenStorage.ErrNotFound)
+	}
+}
+
+func TestStorage_RevokeFamily(t *testing.T) {
+	s := New(0)
+	exp := time.Now().Add(time.Hour)
+	if err := s.Add(refreshTokenStorage.Token{Hash: "a", Family: "f", Login: "user", ExpiresAt: exp}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAccessToken("f", "jti1", exp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAccessToken("f", "jti2", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAccessToken("unknown", "jti3", exp); !errors.Is(err, refreshTokenStorage.ErrNotFound) {
+		t.Errorf("AddAccessToken() of an unknown family error = %v, wantErr %v", err, refreshTokenStorage.ErrNotFound)
+	}
+	if got, err := s.FamilyOf("jti1"); err != nil || got != "f" {
+		t.Errorf("FamilyOf() = %v, %v, want f", got, err)
+	}
+
+	access, err := s.RevokeFamily("f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(access) != 1 || access[0].JTI != "jti1" {
+		t.Errorf("RevokeFamily() = %v, want only the unexpired jti1", access)
+	}
+	if _, err := s.Rotate("a", "b", exp); !errors.Is(err, refreshTokenStorage.ErrRevoked) {
+		t.Errorf("Rotate() in a revoked family error = %v, wantErr %v", err, refreshTokenStorage.ErrRevoked)
+	}
+	if err := s.AddAccessToken("f", "jti4", exp); !errors.Is(err, refreshTokenStorage.ErrRevoked) {
+		t.Errorf("AddAccessToken() in a revoked family error = %v, wantErr %v", err, refreshTokenStorage.ErrRevoked)
+	}
+	if _, err := s.FamilyOf("jti1"); !errors.Is(err, refreshTokenStorage.ErrNotFound) {
+		t.Errorf("FamilyOf() of a revoked access token error = %v, wantErr %v", err, refreshTokenStorage.ErrNotFound)
+	}
+}
+
+func TestStorage_Compact(t *testing.T) {
+	s := New(0)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	if err := s.Add(refreshTokenStorage.Token{Hash: "a", Family: "f", ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAccessToken("f", "jti", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := s.FamilyOf("jti"); !errors.Is(err, refreshTokenStorage.ErrNotFound) {
+		t.Errorf("expired access token is returned")
+	}
+	if _, err := s.Rotate("a", "b", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := s.Rotate("b", "c", now.Add(time.Hour)); !errors.Is(err, refreshTokenStorage.ErrNotFound) {
+		t.Errorf("Rotate() of an expired token error = %v, wantErr %v", err, refreshTokenStorage.ErrNotFound)
+	}
+	if len(s.tokens) != 2 {
+		t.Errorf("expired tokens are removed before compaction: %d tokens", len(s.tokens))
+	}
+	if removed := s.Compact(); removed != 2 {
+		t.Errorf("Compact() = %d, want 2", removed)
+	}
+	if len(s.tokens) != 0 || len(s.families) != 0 || len(s.familyOf) != 0 {
+		t.Errorf("expired tokens or families are kept: %d tokens, %d families, %d access tokens",
+			len(s.tokens), len(s.families), len(s.familyOf))
+	}
+}
+
+func TestStorage_compactLoop(t *testing.T) {
+	s := New(10 * time.Millisecond)
+	defer s.Close()
+	if err := s.Add(refreshTokenStorage.Token{Hash: "a", Family: "f", ExpiresAt: time.Now().Add(20 * time.Millisecond)}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		n := len(s.tokens) + len(s.families)
+		s.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expired token is not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,28 @@
+package refreshTokenStorage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("refresh token not found")
+	ErrAlreadyExists = errors.New("refresh token already exists")
+	ErrUsed          = errors.New("refresh token already used")
+	ErrRevoked       = errors.New("refresh token family revoked")
+)
+
+// Token is a refresh token kept by the hash of its value. The tokens rotated
+// from the one issued at login form a family.
+type Token struct {
+	Hash      string
+	Family    string
+	Login     string
+	ExpiresAt time.Time
+}
+
+// AccessToken is an access token issued along with a token of a family.
+type AccessToken struct {
+	JTI       string
+	ExpiresAt time.Time
+}
@@ -5,17 +5,21 @@ import (
 	"fmt"
 	"geo/db/userStorage"
 	"golang.org/x/crypto/bcrypt"
+	"slices"
 	"sync"
 )
 
 type Storage struct {
 	Users map[string]string
+	// Roles maps the login to the roles granted to the user
+	Roles map[string][]string
 	mu    sync.RWMutex
 }
 
 func New() *Storage {
 	return &Storage{
 		Users: make(map[string]string, 100),
+		Roles: make(map[string][]string, 100),
 	}
 }
 
@@ -52,6 +56,40 @@ func (r *Storage) Login(login, password string) error {
 	return nil
 }
 
+// UserRoles returns the roles of the user in the order they were granted.
+func (r *Storage) UserRoles(login string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.Users[login]; !ok {
+		return nil, fmt.Errorf("login \"%s\": %w", login, userStorage.ErrNotFound)
+	}
+	return slices.Clone(r.Roles[login]), nil
+}
+
+// Grant adds the role to the user, granting a role the user has is a no-op.
+func (r *Storage) Grant(login, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.Users[login]; !ok {
+		return fmt.Errorf("login \"%s\": %w", login, userStorage.ErrNotFound)
+	}
+	if !slices.Contains(r.Roles[login], role) {
+		r.Roles[login] = append(r.Roles[login], role)
+	}
+	return nil
+}
+
+// Revoke removes the role from the user, revoking a role the user does not have is a no-op.
+func (r *Storage) Revoke(login, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.Users[login]; !ok {
+		return fmt.Errorf("login \"%s\": %w", login, userStorage.ErrNotFound)
+	}
+	r.Roles[login] = slices.DeleteFunc(r.Roles[login], func(granted string) bool { return granted == role })
+	return nil
+}
+
 func checkPassword(password, hashedPassword string) error {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
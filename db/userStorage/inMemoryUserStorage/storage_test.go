@@ -4,6 +4,7 @@ import (
 	"errors"
 	"geo/db/userStorage"
 	"golang.org/x/crypto/bcrypt"
+	"slices"
 	"testing"
 )
 
@@ -194,3 +195,49 @@ func TestUserInMemoryRegistry_LoginUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserInMemoryRegistry_Roles(t *testing.T) {
+	s := New()
+	if err := s.Register("test", "test"); err != nil {
+		t.Fatal(err)
+	}
+	roles, err := s.UserRoles("test")
+	if err != nil || len(roles) != 0 {
+		t.Errorf("UserRoles() of a new user = %v, %v, want none", roles, err)
+	}
+
+	for _, role := range []string{"user", "admin", "user"} {
+		if err := s.Grant("test", role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	roles, _ = s.UserRoles("test")
+	if !slices.Equal(roles, []string{"user", "admin"}) {
+		t.Errorf("UserRoles() = %v, want [user admin]", roles)
+	}
+	roles[0] = "changed"
+	if again, _ := s.UserRoles("test"); again[0] != "user" {
+		t.Errorf("stored roles changed through the returned ones: %v", again)
+	}
+
+	if err := s.Revoke("test", "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Revoke("test", "unknown"); err != nil {
+		t.Fatal(err)
+	}
+	roles, _ = s.UserRoles("test")
+	if !slices.Equal(roles, []string{"admin"}) {
+		t.Errorf("UserRoles() = %v, want [admin]", roles)
+	}
+
+	if _, err := s.UserRoles("unknown"); !errors.Is(err, userStorage.ErrNotFound) {
+		t.Errorf("UserRoles() error = %v, wantErr %v", err, userStorage.ErrNotFound)
+	}
+	if err := s.Grant("unknown", "user"); !errors.Is(err, userStorage.ErrNotFound) {
+		t.Errorf("Grant() error = %v, wantErr %v", err, userStorage.ErrNotFound)
+	}
+	if err := s.Revoke("unknown", "user"); !errors.Is(err, userStorage.ErrNotFound) {
+		t.Errorf("Revoke() error = %v, wantErr %v", err, userStorage.ErrNotFound)
+	}
+}
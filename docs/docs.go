@@ -21,25 +21,69 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/address/geocode": {
+        "/address/autocomplete": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Upgrades to a WebSocket authenticated by the bearer token of the handshake.\nThe client sends {\"id\": 1, \"query\": \"г Москва, ул Сне\"} on every keystroke, the server waits\nfor the input to settle, cancels the search of an outdated query and answers\nthe latest one with an AddressSuggestions message. The socket is closed when the token expires,\na session opened with an API key lasts at most the access token TTL.",
+                "tags": [
+                    "address"
+                ],
+                "summary": "Live address autocomplete",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols, then suggestion messages",
+                        "schema": {
+                            "$ref": "#/definitions/AddressSuggestions"
+                        }
+                    },
+                    "400": {
+                        "description": "not a WebSocket handshake"
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "cross-origin handshake"
+                    }
+                }
+            }
+        },
+        "/address/clean": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
                     }
                 ],
+                "description": "Parses a free-form address into a standardized one with its components, coordinates and quality codes.",
                 "tags": [
                     "address"
                 ],
-                "summary": "Array of addresses located at specified coordinates",
+                "summary": "Standardized address",
                 "parameters": [
                     {
-                        "description": "object coordinates",
-                        "name": "coordinates",
+                        "description": "free-form address",
+                        "name": "address",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/address.GeocodeRequest"
+                            "$ref": "#/definitions/CleanRequest"
                         }
                     }
                 ],
@@ -47,13 +91,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/AddressResponse"
+                            "$ref": "#/definitions/CleanAddress"
                         }
                     },
                     "400": {
-                        "description": "invalid lat or lng format",
+                        "description": "empty or too long address",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -70,43 +126,91 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ErrResponse"
                         }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
                     }
                 }
             }
         },
-        "/address/search": {
+        "/address/geocode": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
                     }
                 ],
+                "description": "The addresses can be exported as GeoJSON, CSV, KML or GPX with the format parameter or the Accept header.",
+                "produces": [
+                    "application/json",
+                    "application/geo+json",
+                    "text/csv",
+                    "application/vnd.google-earth.kml+xml",
+                    "application/gpx+xml"
+                ],
                 "tags": [
                     "address"
                 ],
-                "summary": "Array of addresses located at specified location",
+                "summary": "Array of addresses located at specified coordinates",
                 "parameters": [
                     {
-                        "description": "object location",
-                        "name": "query",
+                        "description": "object coordinates",
+                        "name": "coordinates",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/address.SearchRequest"
+                            "$ref": "#/definitions/address.GeocodeRequest"
                         }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "geojson",
+                            "csv",
+                            "kml",
+                            "gpx"
+                        ],
+                        "type": "string",
+                        "description": "output format, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "addresses, or a geojson.FeatureCollection with Accept: application/geo+json",
                         "schema": {
                             "$ref": "#/definitions/AddressResponse"
+                        },
+                        "headers": {
+                            "Warning": {
+                                "type": "string",
+                                "description": "Response is Stale, when cached addresses are served because the provider is unavailable"
+                            }
                         }
                     },
                     "400": {
-                        "description": "invalid query format",
+                        "description": "invalid lat, lng or options, an option the provider does not support, unknown format",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -123,45 +227,172 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ErrResponse"
                         }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
                     }
                 }
             }
         },
-        "/login": {
+        "/address/geocode/batch": {
             "post": {
-                "description": "Get the Bearer token using your Login and Password. If the token's lifetime has expired, you need to log in again. If you don't have an account, see /register endpoint",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Items are geocoded concurrently, results are returned in the order of the request items.\nA failed item carries an error and does not fail the whole batch.\nThe results can be exported as CSV, KML or GPX with the format parameter or the Accept header.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.google-earth.kml+xml",
+                    "application/gpx+xml"
+                ],
                 "tags": [
-                    "auth"
+                    "address"
                 ],
-                "summary": "Log in to the api",
+                "summary": "Addresses located at every specified coordinates",
                 "parameters": [
                     {
-                        "description": "your credentials",
-                        "name": "credentials",
+                        "description": "array of object coordinates",
+                        "name": "coordinates",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/CredentialsRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/address.GeocodeRequest"
+                            }
                         }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "kml",
+                            "gpx"
+                        ],
+                        "type": "string",
+                        "description": "output format, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/auth.LoginResponse"
+                            "$ref": "#/definitions/AddressBatchResponse"
                         }
                     },
                     "400": {
-                        "description": "invalid login/password format",
+                        "description": "invalid request format, empty or too large batch, unknown format",
                         "schema": {
                             "$ref": "#/definitions/response.ErrResponse"
                         }
                     },
                     "401": {
-                        "description": "Invalid username or password",
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/address/search": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Optional filters restrict results to locations and address levels,\ne.g. only the streets of one city or only cities.\nThe addresses can be exported as GeoJSON, CSV, KML or GPX with the format parameter or the Accept header.",
+                "produces": [
+                    "application/json",
+                    "application/geo+json",
+                    "text/csv",
+                    "application/vnd.google-earth.kml+xml",
+                    "application/gpx+xml"
+                ],
+                "tags": [
+                    "address"
+                ],
+                "summary": "Array of addresses located at specified location",
+                "parameters": [
+                    {
+                        "description": "object location",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/response.ErrResponse"
+                            "$ref": "#/definitions/address.SearchRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "geojson",
+                            "csv",
+                            "kml",
+                            "gpx"
+                        ],
+                        "type": "string",
+                        "description": "output format, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "addresses, or a geojson.FeatureCollection with Accept: application/geo+json",
+                        "schema": {
+                            "$ref": "#/definitions/AddressResponse"
+                        },
+                        "headers": {
+                            "Warning": {
+                                "type": "string",
+                                "description": "Response is Stale, when cached addresses are served because the provider is unavailable"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query or filters, a filter the provider does not support, unknown format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
                         }
                     },
                     "500": {
@@ -169,25 +400,112 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ErrResponse"
                         }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
                     }
                 }
             }
         },
-        "/logout": {
+        "/address/search/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "The CSV is sent as the request body or as the file field of a multipart form, its first row is the header.\nRows are searched concurrently and streamed back in input order while the file is processed,\nevery output row holds the input row number, the top match, the number of matches and the row error.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "address"
+                ],
+                "summary": "Search every address of a CSV file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "header of the column holding the query, address by default",
+                        "name": "column",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/AddressBulkRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid CSV, unknown column or format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache": {
             "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Log out and revoke the Bearer token",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Log out from the server",
+                "summary": "Drop every cached provider response",
                 "responses": {
-                    "204": {
-                        "description": "Logged out successfully"
+                    "200": {
+                        "description": "names of the purged caches",
+                        "schema": {
+                            "$ref": "#/definitions/responder.Response"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized: Token missing or invalid",
@@ -198,42 +516,59 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Forbidden: the token lacks the admin role"
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrResponse"
+                            "$ref": "#/definitions/responder.Response"
                         }
                     }
                 }
             }
         },
-        "/register": {
-            "post": {
-                "description": "Choose a login and set up a password",
+        "/admin/users/{login}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register on the server",
+                "summary": "Roles of a user",
                 "parameters": [
                     {
-                        "description": "your credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/CredentialsRequest"
-                        }
+                        "type": "string",
+                        "description": "user login",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User registered successfully",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "$ref": "#/definitions/UserRoles"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request parameters",
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: the token lacks the admin role"
+                    },
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "$ref": "#/definitions/response.ErrResponse"
                         }
@@ -246,22 +581,1601 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "Address": {
-            "type": "object",
-            "properties": {
-                "city": {
-                    "type": "string"
-                },
-                "house": {
-                    "type": "string"
-                },
-                "lat": {
-                    "type": "string"
-                },
+        },
+        "/admin/users/{login}/roles/{role}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Roles are the built-in user and admin or custom ones. The user gets the role in the tokens issued from now on.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Grant a role to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user login",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserRoles"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid role name",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: the token lacks the admin role"
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The tokens issued before keep the role until they expire. Admins cannot revoke their own admin role.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke a role from a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user login",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserRoles"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid role name, own admin role",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: the token lacks the admin role"
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/distance": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Places are given by coordinates or by addresses resolved to their best match.",
+                "tags": [
+                    "distance"
+                ],
+                "summary": "Distance and initial bearing between two places",
+                "parameters": [
+                    {
+                        "description": "origin, destination and method",
+                        "name": "places",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/distance.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DistanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid place or method, address not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/distance/matrix": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Places are given by coordinates or by addresses resolved to their best match.\nThe number of origins and destinations is limited by the service configuration.",
+                "tags": [
+                    "distance"
+                ],
+                "summary": "Distances and initial bearings from every origin to every destination",
+                "parameters": [
+                    {
+                        "description": "origins, destinations and method",
+                        "name": "places",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/distance.MatrixRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/DistanceMatrixResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid place or method, too many places, address not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Service health",
+                "responses": {
+                    "200": {
+                        "description": "all components are healthy",
+                        "schema": {
+                            "$ref": "#/definitions/HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "some components are unhealthy",
+                        "schema": {
+                            "$ref": "#/definitions/HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Keys are listed oldest first, without their secrets.",
+                "tags": [
+                    "keys"
+                ],
+                "summary": "API keys of the user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/APIKeyList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage keys"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The secret is returned only once, the service keeps its hash.\nMachine clients send it in the X-API-Key header or as Authorization: ApiKey \u003csecret\u003e.",
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Issue an API key",
+                "parameters": [
+                    {
+                        "description": "label, scopes and expiry",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/APIKeyCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/NewAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid label, scopes or expiry, too many keys",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage keys"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The requests made with the key are rejected from now on.",
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage keys"
+                    },
+                    "404": {
+                        "description": "no such key of the user",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "keys"
+                ],
+                "summary": "Relabel an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new label",
+                        "name": "key",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/APIKeyUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/APIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid label",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden: API keys cannot manage keys"
+                    },
+                    "404": {
+                        "description": "no such key of the user",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Get the Bearer token using your Login and Password. When the token's lifetime has expired, get a new one with the refresh token at /token/refresh. If you don't have an account, see /register endpoint",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in to the api",
+                "parameters": [
+                    {
+                        "description": "your credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CredentialsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid login/password format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid username or password",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log out and revoke the Bearer token",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out from the server",
+                "responses": {
+                    "204": {
+                        "description": "Logged out successfully"
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/places": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Places are listed oldest first.",
+                "tags": [
+                    "places"
+                ],
+                "summary": "Saved places of the user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only the places with the tag, case insensitive",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/PlaceList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "The address is given as a search or geocode result, or is resolved from a query or a point to its best match.\nExactly one of address, search and geocode is set.",
+                "tags": [
+                    "places"
+                ],
+                "summary": "Save a place",
+                "parameters": [
+                    {
+                        "description": "label, tags and address source",
+                        "name": "place",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PlaceCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/Place"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid label, tags or source, address not found, too many places",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/places/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "tags": [
+                    "places"
+                ],
+                "summary": "Saved place",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "place id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Place"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no such place of the user",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "tags": [
+                    "places"
+                ],
+                "summary": "Delete a saved place",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "place id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no such place of the user",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "XAPIKey": []
+                    }
+                ],
+                "description": "Only the fields sent are changed. A new address source replaces the address.",
+                "tags": [
+                    "places"
+                ],
+                "summary": "Change a saved place",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "place id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change",
+                        "name": "place",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/PlaceUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Place"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid label, tags or source, address not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/responder.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/validation.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: Token missing or invalid",
+                        "headers": {
+                            "WWW-Authenticate": {
+                                "type": "string",
+                                "description": "Bearer"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no such place of the user",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "upstream timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Choose a login and set up a password",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register on the server",
+                "parameters": [
+                    {
+                        "description": "your credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CredentialsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Exchange the refresh token for a new Bearer token and a new refresh token, the old refresh token cannot be used again.\nReusing a refresh token revokes every token issued since the login it comes from.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Renew the Bearer token",
+                "parameters": [
+                    {
+                        "description": "refresh token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unknown, expired, revoked or reused refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-05-01T09:30:00Z"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt, CreatedAt and LastUsedAt are in UTC, the keys without ExpiresAt never expire",
+                    "type": "string",
+                    "example": "2025-05-01T09:30:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-41d2-883f-0016d3cca427"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "nightly geocoding"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-05-02T09:30:00Z"
+                },
+                "prefix": {
+                    "description": "Prefix is the beginning of the secret that tells the keys apart",
+                    "type": "string",
+                    "example": "geo_Jk3v9QpX"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "address"
+                    ]
+                }
+            }
+        },
+        "APIKeyCreateRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt is when the key stops working, a key without it never expires",
+                    "type": "string",
+                    "example": "2025-05-01T09:30:00Z"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "nightly geocoding"
+                },
+                "scopes": {
+                    "description": "Scopes limit the key to address, distance or places, a key without scopes may use all of them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "address"
+                    ]
+                }
+            }
+        },
+        "APIKeyList": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/APIKey"
+                    }
+                }
+            }
+        },
+        "APIKeyUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "example": "nightly geocoding"
+                }
+            }
+        },
+        "Address": {
+            "type": "object",
+            "properties": {
+                "area": {
+                    "description": "Area is the district of the region, Settlement is the village or town outside a city",
+                    "type": "string",
+                    "example": "Одинцовский"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Россия"
+                },
+                "fias_id": {
+                    "description": "FiasID is the FIAS code of the most detailed address level found",
+                    "type": "string",
+                    "example": "93eb5a93-2e58-4d68-ad78-ae4bcb2ab1f2"
+                },
+                "house": {
+                    "type": "string"
+                },
+                "kladr_id": {
+                    "type": "string",
+                    "example": "7700000000028360004"
+                },
+                "lat": {
+                    "type": "string"
+                },
+                "lon": {
+                    "type": "string"
+                },
+                "okato": {
+                    "type": "string",
+                    "example": "45280583000"
+                },
+                "oktmo": {
+                    "type": "string",
+                    "example": "45362000"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "129323"
+                },
+                "qc_geo": {
+                    "description": "QcGeo is the coordinates precision code: 0 is the exact house, 5 is unknown",
+                    "type": "string",
+                    "example": "0"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "settlement": {
+                    "type": "string",
+                    "example": "Жаворонки"
+                },
+                "street": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "UTC+3"
+                },
+                "value": {
+                    "description": "Value is the full address in one line",
+                    "type": "string",
+                    "example": "г Москва, ул Снежная, д 4"
+                }
+            }
+        },
+        "AddressBatchItem": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Address"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "lat, lng cannot be empty"
+                },
+                "stale": {
+                    "description": "Stale is set when the addresses come from an expired cache entry",
+                    "type": "boolean"
+                }
+            }
+        },
+        "AddressBatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AddressBatchItem"
+                    }
+                }
+            }
+        },
+        "AddressBulkRow": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "house": {
+                    "type": "string"
+                },
+                "lat": {
+                    "type": "string"
+                },
+                "lon": {
+                    "type": "string"
+                },
+                "matches": {
+                    "type": "integer"
+                },
+                "row": {
+                    "description": "Row is the number of the input row, the header is row 1",
+                    "type": "integer"
+                },
+                "street": {
+                    "type": "string"
+                }
+            }
+        },
+        "AddressResponse": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Address"
+                    }
+                },
+                "stale": {
+                    "description": "Stale is set when the addresses come from an expired cache entry\nbecause the provider is unavailable",
+                    "type": "boolean"
+                }
+            }
+        },
+        "AddressSuggestions": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "stale": {
+                    "description": "Stale is set when the addresses come from an expired cache entry",
+                    "type": "boolean"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Address"
+                    }
+                }
+            }
+        },
+        "BoostLocation": {
+            "type": "object",
+            "properties": {
+                "kladr_id": {
+                    "type": "string",
+                    "example": "7700000000000"
+                }
+            }
+        },
+        "CleanAddress": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "$ref": "#/definitions/CleanAddressComponents"
+                },
+                "lat": {
+                    "type": "string",
+                    "example": "55.8782557"
+                },
+                "lon": {
+                    "type": "string",
+                    "example": "37.65372"
+                },
+                "qc": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "qc_complete": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "qc_geo": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "qc_house": {
+                    "type": "string",
+                    "example": "2"
+                },
+                "result": {
+                    "description": "Result is the standardized address in one line",
+                    "type": "string",
+                    "example": "г Москва, ул Сухонская, д 11, кв 89"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "мск сухонская 11 89"
+                },
+                "unparsed_parts": {
+                    "description": "UnparsedParts are the words of the source that were not recognized",
+                    "type": "string",
+                    "example": "ВОЗЛЕ АВТОВОКЗАЛА"
+                }
+            }
+        },
+        "CleanAddressComponents": {
+            "type": "object",
+            "properties": {
+                "area": {
+                    "type": "string"
+                },
+                "block": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "city_district": {
+                    "type": "string",
+                    "example": "Северный"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Россия"
+                },
+                "fias_id": {
+                    "type": "string",
+                    "example": "5ee84ac0-eb9a-4b49-ad0f-e3a8ca6f5de1"
+                },
+                "fias_level": {
+                    "description": "FiasLevel is the most detailed FIAS level found, 8 is a house",
+                    "type": "string",
+                    "example": "8"
+                },
+                "flat": {
+                    "type": "string",
+                    "example": "89"
+                },
+                "house": {
+                    "type": "string",
+                    "example": "11"
+                },
+                "kladr_id": {
+                    "type": "string",
+                    "example": "7700000000028360004"
+                },
+                "okato": {
+                    "type": "string",
+                    "example": "45280583000"
+                },
+                "oktmo": {
+                    "type": "string",
+                    "example": "45362000"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "127642"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "settlement": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Сухонская"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "UTC+3"
+                }
+            }
+        },
+        "CleanRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "мск сухонская 11 89"
+                }
+            }
+        },
+        "CredentialsRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "DistanceLeg": {
+            "type": "object",
+            "properties": {
+                "bearing_degrees": {
+                    "description": "Bearing is the initial bearing in degrees clockwise from north",
+                    "type": "number",
+                    "example": 312.7
+                },
+                "distance_meters": {
+                    "type": "number",
+                    "example": 1523.4
+                },
+                "error": {
+                    "description": "Error is set when the distance cannot be computed, e.g. vincenty for nearly antipodal points",
+                    "type": "string"
+                }
+            }
+        },
+        "DistanceLocation": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is the address the query was resolved to",
+                    "type": "string",
+                    "example": "г Москва, ул Снежная, д 4"
+                },
+                "lat": {
+                    "type": "number",
+                    "example": 55.8481373
+                },
                 "lon": {
+                    "type": "number",
+                    "example": 37.6414907
+                }
+            }
+        },
+        "DistanceMatrixResponse": {
+            "type": "object",
+            "properties": {
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistanceLocation"
+                    }
+                },
+                "method": {
+                    "type": "string",
+                    "example": "haversine"
+                },
+                "origins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistanceLocation"
+                    }
+                },
+                "rows": {
+                    "description": "Rows follow the order of the origins",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistanceMatrixRow"
+                    }
+                }
+            }
+        },
+        "DistanceMatrixRow": {
+            "type": "object",
+            "properties": {
+                "legs": {
+                    "description": "Legs are the legs from the origin of the row to every destination",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistanceLeg"
+                    }
+                }
+            }
+        },
+        "DistancePlace": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "г Москва, ул Снежная, д 4"
+                },
+                "lat": {
+                    "type": "number",
+                    "example": 55.8481373
+                },
+                "lng": {
+                    "type": "number",
+                    "example": 37.6414907
+                }
+            }
+        },
+        "DistanceResponse": {
+            "type": "object",
+            "properties": {
+                "bearing_degrees": {
+                    "description": "Bearing is the initial bearing in degrees clockwise from north",
+                    "type": "number",
+                    "example": 312.7
+                },
+                "distance_meters": {
+                    "type": "number",
+                    "example": 1523.4
+                },
+                "error": {
+                    "type": "string"
+                },
+                "from": {
+                    "$ref": "#/definitions/DistanceLocation"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "haversine"
+                },
+                "to": {
+                    "$ref": "#/definitions/DistanceLocation"
+                }
+            }
+        },
+        "HealthComponent": {
+            "type": "object",
+            "properties": {
+                "details": {},
+                "healthy": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "HealthResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/HealthComponent"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "Location": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Россия"
+                },
+                "fias_id": {
+                    "type": "string"
+                },
+                "kladr_id": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "Москва"
+                },
+                "settlement": {
                     "type": "string"
                 },
                 "street": {
@@ -269,49 +2183,295 @@ const docTemplate = `{
                 }
             }
         },
-        "AddressResponse": {
+        "NewAPIKey": {
             "type": "object",
             "properties": {
-                "addresses": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-05-01T09:30:00Z"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt, CreatedAt and LastUsedAt are in UTC, the keys without ExpiresAt never expire",
+                    "type": "string",
+                    "example": "2025-05-01T09:30:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-41d2-883f-0016d3cca427"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "nightly geocoding"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-05-02T09:30:00Z"
+                },
+                "prefix": {
+                    "description": "Prefix is the beginning of the secret that tells the keys apart",
+                    "type": "string",
+                    "example": "geo_Jk3v9QpX"
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/Address"
+                        "type": "string"
+                    },
+                    "example": [
+                        "address"
+                    ]
+                },
+                "secret": {
+                    "description": "Secret is sent in the X-API-Key header or as Authorization: ApiKey \u003csecret\u003e",
+                    "type": "string",
+                    "example": "geo_Jk3v9QpXy8Wm1cZt4RbN6eLh2sUo0aVf5gDi7kQw3E"
+                }
+            }
+        },
+        "Place": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/Address"
+                },
+                "created_at": {
+                    "description": "CreatedAt and UpdatedAt are in UTC",
+                    "type": "string",
+                    "example": "2024-05-01T09:30:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-41d2-883f-0016d3cca427"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Склад на Снежной"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "depot",
+                        "north"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-05-01T09:30:00Z"
+                }
+            }
+        },
+        "PlaceCreateRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is an address as search or geocode returned it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Address"
+                        }
+                    ]
+                },
+                "geocode": {
+                    "description": "Geocode is a point whose nearest address is saved",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PlaceGeocodeSource"
+                        }
+                    ]
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Склад на Снежной"
+                },
+                "search": {
+                    "description": "Search is a query whose best match is saved",
+                    "type": "string",
+                    "example": "г Москва, ул Снежная, д 4"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "depot",
+                        "north"
+                    ]
+                }
+            }
+        },
+        "PlaceGeocodeSource": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "number",
+                    "example": 55.8481373
+                },
+                "lng": {
+                    "type": "number",
+                    "example": 37.6414907
+                }
+            }
+        },
+        "PlaceList": {
+            "type": "object",
+            "properties": {
+                "places": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Place"
                     }
                 }
             }
         },
-        "CredentialsRequest": {
+        "PlaceUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is an address as search or geocode returned it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Address"
+                        }
+                    ]
+                },
+                "geocode": {
+                    "description": "Geocode is a point whose nearest address is saved",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/PlaceGeocodeSource"
+                        }
+                    ]
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Склад на Снежной"
+                },
+                "search": {
+                    "description": "Search is a query whose best match is saved",
+                    "type": "string",
+                    "example": "г Москва, ул Снежная, д 4"
+                },
+                "tags": {
+                    "description": "Tags replace the tags, an empty array removes them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "depot",
+                        "north"
+                    ]
+                }
+            }
+        },
+        "RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7Ehp3Yv0m2b8yK0Jc4n5fQ1rT9uW6xZaBcDeFgHiJk"
+                }
+            }
+        },
+        "UserRoles": {
             "type": "object",
             "properties": {
                 "login": {
                     "type": "string",
                     "example": "admin"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "123456"
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user",
+                        "admin"
+                    ]
                 }
             }
         },
         "address.GeocodeRequest": {
             "type": "object",
             "properties": {
-                "lat": {
+                "count": {
+                    "description": "Count is the maximum number of addresses returned",
+                    "type": "integer",
+                    "maximum": 20,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "language": {
+                    "description": "Language is the language of the returned addresses",
                     "type": "string",
-                    "example": "55.8481373"
+                    "example": "en"
+                },
+                "lat": {
+                    "type": "number",
+                    "example": 55.8481373
                 },
                 "lng": {
-                    "type": "string",
-                    "example": "37.6414907"
+                    "type": "number",
+                    "example": 37.6414907
+                },
+                "radius_meters": {
+                    "description": "RadiusMeters is the search radius around the point, the provider default is used when omitted",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 100
                 }
             }
         },
         "address.SearchRequest": {
             "type": "object",
             "properties": {
+                "count": {
+                    "description": "Count is the maximum number of addresses returned",
+                    "type": "integer",
+                    "maximum": 20,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "from_bound": {
+                    "description": "FromBound and ToBound limit the address levels returned, e.g. city to city returns only cities",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/geo.Granularity"
+                        }
+                    ],
+                    "example": "street"
+                },
+                "locations": {
+                    "description": "Locations keep only the addresses inside any of them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Location"
+                    }
+                },
+                "locations_boost": {
+                    "description": "LocationsBoost rank the addresses inside them first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/BoostLocation"
+                    }
+                },
                 "query": {
                     "type": "string",
                     "example": "г Москва, ул Снежная"
+                },
+                "to_bound": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/geo.Granularity"
+                        }
+                    ],
+                    "example": "street"
                 }
             }
         },
@@ -329,6 +2489,85 @@ const docTemplate = `{
                 }
             }
         },
+        "distance.MatrixRequest": {
+            "type": "object",
+            "properties": {
+                "destinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistancePlace"
+                    }
+                },
+                "method": {
+                    "description": "Method is haversine for a sphere or vincenty for the WGS 84 ellipsoid",
+                    "type": "string",
+                    "enum": [
+                        "haversine",
+                        "vincenty"
+                    ],
+                    "example": "haversine"
+                },
+                "origins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DistancePlace"
+                    }
+                }
+            }
+        },
+        "distance.Request": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/DistancePlace"
+                },
+                "method": {
+                    "description": "Method is haversine for a sphere or vincenty for the WGS 84 ellipsoid",
+                    "type": "string",
+                    "enum": [
+                        "haversine",
+                        "vincenty"
+                    ],
+                    "example": "haversine"
+                },
+                "to": {
+                    "$ref": "#/definitions/DistancePlace"
+                }
+            }
+        },
+        "geo.Granularity": {
+            "type": "string",
+            "enum": [
+                "country",
+                "region",
+                "area",
+                "city",
+                "settlement",
+                "street",
+                "house"
+            ],
+            "x-enum-varnames": [
+                "GranularityCountry",
+                "GranularityRegion",
+                "GranularityArea",
+                "GranularityCity",
+                "GranularitySettlement",
+                "GranularityStreet",
+                "GranularityHouse"
+            ]
+        },
+        "responder.Response": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
         "response.ErrResponse": {
             "type": "object",
             "properties": {
@@ -347,6 +2586,17 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "validation.Error": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -355,6 +2605,12 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "XAPIKey": {
+            "description": "API key of a machine client, also accepted as ` + "`" + `Authorization: ApiKey \u003ckey\u003e` + "`" + `",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     },
     "tags": [
@@ -365,6 +2621,26 @@ const docTemplate = `{
         {
             "description": "Authorization and authentication",
             "name": "auth"
+        },
+        {
+            "description": "Service and upstream providers state",
+            "name": "health"
+        },
+        {
+            "description": "Service maintenance",
+            "name": "admin"
+        },
+        {
+            "description": "Distances between coordinates and addresses",
+            "name": "distance"
+        },
+        {
+            "description": "Saved places of the user",
+            "name": "places"
+        },
+        {
+            "description": "API keys of the user for machine clients",
+            "name": "keys"
         }
     ]
 }`
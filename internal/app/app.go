@@ -3,6 +3,10 @@ package app
 import (
 	"context"
 	"errors"
+	"geo/db/apiKeyStorage/inMemoryAPIKeyStorage"
+	"geo/db/geoCache/boltGeoCache"
+	"geo/db/placeStorage/inMemoryPlaceStorage"
+	"geo/db/refreshTokenStorage/inMemoryRefreshTokenStorage"
 	"geo/db/tokenBlacklist/inMemoryTokenBlacklist"
 	"geo/db/userStorage/inMemoryUserStorage"
 	"geo/internal/config"
@@ -10,16 +14,35 @@ import (
 	httpController "geo/internal/controller/http"
 	authMW "geo/internal/controller/http/middleware/auth"
 	addressController "geo/internal/controller/http/v1/address"
+	adminController "geo/internal/controller/http/v1/admin"
 	authController "geo/internal/controller/http/v1/auth"
+	autocompleteController "geo/internal/controller/http/v1/autocomplete"
+	cleanController "geo/internal/controller/http/v1/clean"
+	distanceController "geo/internal/controller/http/v1/distance"
+	healthController "geo/internal/controller/http/v1/health"
+	jwksController "geo/internal/controller/http/v1/jwks"
+	keysController "geo/internal/controller/http/v1/keys"
+	placesController "geo/internal/controller/http/v1/places"
+	usersController "geo/internal/controller/http/v1/users"
+	"geo/internal/infrastructure/geoProvider/cache"
 	"geo/internal/infrastructure/geoProvider/dadata"
+	"geo/internal/infrastructure/jwtKeys"
+	"geo/internal/infrastructure/repository/apiKey"
+	placeRepository "geo/internal/infrastructure/repository/place"
+	"geo/internal/infrastructure/repository/refreshToken"
 	"geo/internal/infrastructure/repository/token"
 	"geo/internal/infrastructure/repository/user"
 	"geo/internal/infrastructure/responder"
 	"geo/internal/infrastructure/tokenGenerator/JWTAuthTokenGenerator"
+	"geo/internal/lib/api/address/addressExport"
+	"geo/internal/lib/api/geojson"
 	"geo/internal/lib/logger/sl"
+	"geo/internal/service/apikey"
 	"geo/internal/service/auth"
+	"geo/internal/service/clean"
+	"geo/internal/service/distance"
 	"geo/internal/service/geo"
-	"github.com/go-chi/jwtauth/v5"
+	"geo/internal/service/place"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/ptflp/godecoder"
@@ -38,40 +61,141 @@ func Run(cfg *config.Config) {
 	)
 
 	// infrastructure
-	geoProvider := dadata.NewGeoService(cfg.Dadata.ApiKey, cfg.Dadata.ApiSecret)
-	ja := jwtauth.New("HS256", []byte(cfg.Token.Secret), nil,
-		jwt.WithAcceptableSkew(cfg.Token.Skew))
-	tokenGenerator := JWTAuthTokenGenerator.New(ja, cfg.Token.TTL)
+	geoProvider, err := newGeoProvider(log, cfg)
+	if err != nil {
+		log.Error("failed to create geo provider", sl.Err(err))
+		os.Exit(1)
+	}
+	log.Info("geo provider selected", slog.String("type", cfg.Provider.Type))
+	var reporters []healthController.Reporter
+	if r, ok := geoProvider.(healthController.Reporter); ok {
+		reporters = append(reporters, r)
+	}
+	var purgers []adminController.Purger
+	var geoCacheDB *boltGeoCache.Cache
+	if cfg.Cache.Persistent.Enabled {
+		geoCacheDB, err = boltGeoCache.Open(log, cfg.Cache.Persistent.Path, boltGeoCache.Settings{
+			MaxEntries:      cfg.Cache.Persistent.MaxEntries,
+			CompactInterval: cfg.Cache.Persistent.CompactInterval,
+		})
+		if err != nil {
+			log.Error("failed to open geo cache storage", sl.Err(err))
+			os.Exit(1)
+		}
+		persistentCache := cache.NewPersistent(log, geoProvider, geoCacheDB, cache.PersistentSettings{
+			TTL:        cfg.Cache.Persistent.TTL,
+			Precision:  cfg.Cache.Precision,
+			StaleGrace: cfg.Cache.Persistent.StaleGrace,
+		})
+		reporters = append(reporters, persistentCache)
+		purgers = append(purgers, persistentCache)
+		geoProvider = persistentCache
+	}
+	if cfg.Cache.Enabled {
+		providerCache := cache.New(geoProvider, cache.Settings{
+			Size:      cfg.Cache.Size,
+			TTL:       cfg.Cache.TTL,
+			Precision: cfg.Cache.Precision,
+
+			StaleGrace:           cfg.Cache.StaleGrace,
+			StaleWhileRevalidate: cfg.Cache.StaleWhileRevalidate,
+			RevalidateTimeout:    cfg.Cache.RevalidateTimeout,
+		})
+		reporters = append(reporters, providerCache)
+		purgers = append(purgers, providerCache)
+		geoProvider = providerCache
+	}
+	keys, err := jwtKeys.New(cfg.Token.Algorithm, cfg.Token.Secret, cfg.Token.SigningKey, cfg.Token.VerificationKeys,
+		jwt.WithAcceptableSkew(cfg.Token.Skew),
+		jwt.WithIssuer(cfg.Token.Issuer),
+		jwt.WithAudience(cfg.Token.Audience))
+	if err != nil {
+		log.Error("failed to load token keys", sl.Err(err))
+		os.Exit(1)
+	}
+	tokenGenerator := JWTAuthTokenGenerator.New(keys.JWTAuth(), cfg.Token.TTL,
+		cfg.Token.Issuer, cfg.Token.Audience)
 	decoder := godecoder.NewDecoder(jsoniter.Config{
 		EscapeHTML:             true,
 		SortMapKeys:            true,
 		ValidateJsonRawMessage: true,
 		DisallowUnknownFields:  true,
 	})
-	responseManager := responder.NewResponder(decoder, log)
+	responseManager := responder.NewResponder(decoder, log,
+		geojson.Encoder{}, addressExport.CSV{}, addressExport.KML{}, addressExport.GPX{})
 
 	// db
 	tokenDB := inMemoryTokenBlacklist.NewBlacklist(cfg.Token.Skew)
 	userDB := inMemoryUserStorage.New()
+	placeDB := inMemoryPlaceStorage.New()
+	refreshTokenDB := inMemoryRefreshTokenStorage.New(cfg.Token.RefreshCompactInterval)
+	apiKeyDB := inMemoryAPIKeyStorage.New()
 
 	// repository
 	tokenRepo := token.New(tokenDB)
 	userRepo := user.New(userDB)
+	placeRepo := placeRepository.New(placeDB)
+	refreshTokenRepo := refreshToken.New(refreshTokenDB)
+	apiKeyRepo := apiKey.New(apiKeyDB)
 
 	// service
-	authService := auth.New(log, RequestIdKey, tokenRepo, tokenGenerator, userRepo)
-	geoService := geo.New(log, RequestIdKey, geoProvider)
+	authService := auth.New(log, RequestIdKey, tokenRepo, tokenGenerator, userRepo, refreshTokenRepo, cfg.Token.RefreshTTL)
+	if cfg.Admin.Login != "" {
+		if err := authService.BootstrapAdmin(cfg.Admin.Login, cfg.Admin.Password); err != nil {
+			log.Error("failed to bootstrap admin", sl.Err(err))
+			os.Exit(1)
+		}
+	}
+	geoService := geo.New(log, RequestIdKey, geoProvider,
+		cfg.Geoservice.SearchTimeout, cfg.Geoservice.GeocodeTimeout, geo.SearchRules{
+			RequireCity:     cfg.Geoservice.Search.RequireCity,
+			RequireStreet:   cfg.Geoservice.Search.RequireStreet,
+			Rank:            cfg.Geoservice.Search.Rank,
+			Dedupe:          cfg.Geoservice.Search.Dedupe,
+			DedupePrecision: cfg.Geoservice.Search.DedupePrecision,
+		})
+	cleanService := clean.New(log, RequestIdKey, dadata.NewGeoService(cfg.Dadata.ApiKey, cfg.Dadata.ApiSecret),
+		cfg.Geoservice.CleanTimeout)
+	distanceService := distance.New(log, RequestIdKey, geoService, distance.Settings{
+		MaxOrigins:      cfg.Distance.MatrixMaxOrigins,
+		MaxDestinations: cfg.Distance.MatrixMaxDestinations,
+		Parallelism:     cfg.Distance.Parallelism,
+	})
+	placeService := place.New(log, RequestIdKey, placeRepo, geoService)
+	apiKeyService := apikey.New(log, RequestIdKey, apiKeyRepo, userRepo)
 
 	// controller
 	authCtrl := authController.New(log, RequestIdKey, authService, responseManager)
-	addressCtrl := addressController.New(log, RequestIdKey, geoService, responseManager)
-	ctrl := controller.New(authCtrl, addressCtrl)
+	addressCtrl := addressController.New(log, RequestIdKey, geoService, responseManager, addressController.Limits{
+		BatchParallelism: cfg.Geoservice.BatchParallelism,
+		BatchMaxItems:    cfg.Geoservice.BatchMaxItems,
+		BulkColumn:       cfg.Geoservice.BulkColumn,
+		BulkMaxRows:      cfg.Geoservice.BulkMaxRows,
+		ReadTimeout:      cfg.Geoservice.ReadTimeout,
+		WriteTimeout:     cfg.Geoservice.WriteTimeout,
+	})
+	healthCtrl := healthController.New(log, reporters...)
+	adminCtrl := adminController.New(log, responseManager, purgers...)
+	distanceCtrl := distanceController.New(log, RequestIdKey, distanceService, responseManager)
+	cleanCtrl := cleanController.New(log, RequestIdKey, cleanService, responseManager)
+	autocompleteCtrl := autocompleteController.New(log, RequestIdKey, geoService, autocompleteController.Settings{
+		Debounce:     cfg.Autocomplete.Debounce,
+		MinLength:    cfg.Autocomplete.MinLength,
+		Count:        cfg.Autocomplete.Count,
+		PingInterval: cfg.Autocomplete.PingInterval,
+	})
+	placesCtrl := placesController.New(log, RequestIdKey, placeService, responseManager)
+	usersCtrl := usersController.New(log, RequestIdKey, authService, responseManager)
+	keysCtrl := keysController.New(log, RequestIdKey, apiKeyService, responseManager)
+	ctrl := controller.New(authCtrl, addressCtrl, healthCtrl, adminCtrl, distanceCtrl, cleanCtrl, autocompleteCtrl,
+		placesCtrl, usersCtrl, keysCtrl, jwksController.New(log, keys))
 
 	// router
 	authenticator := authMW.NewAuthenticator(log, authService)
 	router := httpController.NewRouter(log, cfg, ctrl, &httpController.AuthMiddleware{
 		Authenticator: authenticator,
-		Ja:            ja,
+		KeyVerifier:   authMW.NewKeyVerifier(log, RequestIdKey, apiKeyService, cfg.Token.TTL),
+		Verifier:      keys,
 	})
 
 	// server
@@ -103,5 +227,11 @@ func Run(cfg *config.Config) {
 		log.Error("error while shutting down server", sl.Err(err))
 	}
 	// close storage
+	refreshTokenDB.Close()
+	if geoCacheDB != nil {
+		if err := geoCacheDB.Close(); err != nil {
+			log.Error("error while closing geo cache storage", sl.Err(err))
+		}
+	}
 	log.Info("shut down successfully")
 }
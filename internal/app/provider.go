@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"geo/internal/config"
+	"geo/internal/infrastructure/geoProvider/dadata"
+	"geo/internal/infrastructure/geoProvider/failover"
+	"geo/internal/infrastructure/geoProvider/gazetteer"
+	"geo/internal/infrastructure/geoProvider/nominatim"
+	"geo/internal/service/geo"
+	"log/slog"
+)
+
+const (
+	ProviderDadata    = "dadata"
+	ProviderNominatim = "nominatim"
+	ProviderGazetteer = "gazetteer"
+	ProviderFailover  = "failover"
+)
+
+// newGeoProvider creates the geo provider selected by provider.type.
+func newGeoProvider(log *slog.Logger, cfg *config.Config) (geo.Provider, error) {
+	if cfg.Provider.Type != ProviderFailover {
+		return newSingleProvider(cfg, cfg.Provider.Type)
+	}
+
+	if len(cfg.Provider.Chain) == 0 {
+		return nil, fmt.Errorf("provider chain is empty")
+	}
+	members := make([]failover.Member, 0, len(cfg.Provider.Chain))
+	for _, typ := range cfg.Provider.Chain {
+		p, err := newSingleProvider(cfg, typ)
+		if err != nil {
+			return nil, fmt.Errorf("provider chain: %w", err)
+		}
+		members = append(members, failover.Member{Name: typ, Provider: p})
+	}
+	return failover.New(log, members, failover.Settings{
+		FailureThreshold: cfg.Provider.Breaker.FailureThreshold,
+		CoolDown:         cfg.Provider.Breaker.CoolDown,
+		HalfOpenProbes:   cfg.Provider.Breaker.HalfOpenProbes,
+	}), nil
+}
+
+func newSingleProvider(cfg *config.Config, typ string) (geo.Provider, error) {
+	switch typ {
+	case ProviderDadata:
+		return dadata.NewGeoService(cfg.Dadata.ApiKey, cfg.Dadata.ApiSecret), nil
+	case ProviderNominatim:
+		return nominatim.NewGeoService(cfg.Nominatim.BaseURL, cfg.Nominatim.UserAgent, cfg.Nominatim.RateLimit)
+	case ProviderGazetteer:
+		return gazetteer.NewGeoService(cfg.Gazetteer.Path)
+	default:
+		return nil, fmt.Errorf("unknown provider type %q", typ)
+	}
+}
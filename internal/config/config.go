@@ -7,9 +7,14 @@ import (
 )
 
 type Config struct {
-	Dadata     `yaml:"dadata"`
-	Geoservice `yaml:"geoservice"`
-	Token      `yaml:"token"`
+	Dadata       `yaml:"dadata"`
+	Geoservice   `yaml:"geoservice"`
+	Token        `yaml:"token"`
+	Provider     `yaml:"provider"`
+	Cache        `yaml:"cache"`
+	Distance     `yaml:"distance"`
+	Autocomplete `yaml:"autocomplete"`
+	Admin        `yaml:"-"`
 }
 
 type Dadata struct {
@@ -24,12 +29,125 @@ type Geoservice struct {
 	ReadTimeout     time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" env-default:"10s"`
 	WriteTimeout    time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"10s"`
 	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"30s"`
+	SearchTimeout   time.Duration `yaml:"search_timeout" env:"SEARCH_TIMEOUT" env-default:"5s"`
+	GeocodeTimeout  time.Duration `yaml:"geocode_timeout" env:"GEOCODE_TIMEOUT" env-default:"5s"`
+	CleanTimeout    time.Duration `yaml:"clean_timeout" env:"CLEAN_TIMEOUT" env-default:"5s"`
+	// BatchParallelism is the number of batch geocode items and bulk search rows sent to the provider at once
+	BatchParallelism int `yaml:"batch_parallelism" env:"BATCH_PARALLELISM" env-default:"8"`
+	BatchMaxItems    int `yaml:"batch_max_items" env:"BATCH_MAX_ITEMS" env-default:"1000"`
+	// BulkColumn is the default header of the CSV column holding the bulk search query
+	BulkColumn  string `yaml:"bulk_column" env:"BULK_COLUMN" env-default:"address"`
+	BulkMaxRows int    `yaml:"bulk_max_rows" env:"BULK_MAX_ROWS" env-default:"100000"`
+	Search      Search `yaml:"search"`
+}
+
+// Search holds the rules of the search results post-processing.
+type Search struct {
+	RequireCity   bool `yaml:"require_city" env:"SEARCH_REQUIRE_CITY" env-default:"true"`
+	RequireStreet bool `yaml:"require_street" env:"SEARCH_REQUIRE_STREET" env-default:"false"`
+	Rank          bool `yaml:"rank" env:"SEARCH_RANK" env-default:"true"`
+	Dedupe        bool `yaml:"dedupe" env:"SEARCH_DEDUPE" env-default:"true"`
+	// DedupePrecision is the number of decimal places of the coordinates compared by dedupe
+	DedupePrecision int `yaml:"dedupe_precision" env:"SEARCH_DEDUPE_PRECISION" env-default:"5"`
 }
 
 type Token struct {
-	Secret string        `yaml:"secret" env:"TOKEN_SECRET"`
-	TTL    time.Duration `yaml:"ttl" env:"TTL" env-default:"10m"`
-	Skew   time.Duration `yaml:"skew" env:"TOKEN_SKEW" env-default:"30s"`
+	// Algorithm signs the access tokens: HS256 with Secret, or RS256, ES256 and EdDSA with SigningKey
+	Algorithm string        `yaml:"algorithm" env:"TOKEN_ALGORITHM" env-default:"HS256"`
+	Secret    string        `yaml:"secret" env:"TOKEN_SECRET"`
+	TTL       time.Duration `yaml:"ttl" env:"TTL" env-default:"10m"`
+	Skew      time.Duration `yaml:"skew" env:"TOKEN_SKEW" env-default:"30s"`
+	// Issuer and Audience are set in the iss and aud claims and required of every token,
+	// they have no default so that every deployment names its own
+	Issuer   string `yaml:"issuer" env:"TOKEN_ISSUER" env-required:"true"`
+	Audience string `yaml:"audience" env:"TOKEN_AUDIENCE" env-required:"true"`
+	// SigningKey is the PEM file of the private key, its public key is published at /.well-known/jwks.json
+	SigningKey string `yaml:"signing_key" env:"TOKEN_SIGNING_KEY"`
+	// VerificationKeys are PEM files of the previous signing keys, the tokens they signed are
+	// accepted until the keys are removed from the list
+	VerificationKeys []string `yaml:"verification_keys" env:"TOKEN_VERIFICATION_KEYS" env-separator:","`
+	// RefreshTTL is the lifetime of a refresh token, every refresh issues a new one
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env:"REFRESH_TTL" env-default:"720h"`
+	// RefreshCompactInterval is the period expired refresh tokens are removed with
+	RefreshCompactInterval time.Duration `yaml:"refresh_compact_interval" env:"REFRESH_COMPACT_INTERVAL" env-default:"10m"`
+}
+
+// Admin is the first admin, registered on start if missing and granted the admin role.
+// Nothing is bootstrapped when Login is empty. The credentials are read from the environment only
+// so that they never end up in the config shipped with the image.
+type Admin struct {
+	Login    string `yaml:"-" env:"ADMIN_LOGIN"`
+	Password string `yaml:"-" env:"ADMIN_PASSWORD"`
+}
+
+type Provider struct {
+	Type string `yaml:"type" env:"PROVIDER_TYPE" env-default:"dadata"`
+	// Chain is the ordered list of providers used when Type is failover
+	Chain     []string  `yaml:"chain" env:"PROVIDER_CHAIN" env-separator:","`
+	Breaker   Breaker   `yaml:"breaker"`
+	Nominatim Nominatim `yaml:"nominatim"`
+	Gazetteer Gazetteer `yaml:"gazetteer"`
+}
+
+type Breaker struct {
+	FailureThreshold int           `yaml:"failure_threshold" env:"BREAKER_FAILURE_THRESHOLD" env-default:"5"`
+	CoolDown         time.Duration `yaml:"cool_down" env:"BREAKER_COOL_DOWN" env-default:"30s"`
+	HalfOpenProbes   int           `yaml:"half_open_probes" env:"BREAKER_HALF_OPEN_PROBES" env-default:"1"`
+}
+
+type Nominatim struct {
+	BaseURL   string `yaml:"base_url" env:"NOMINATIM_BASE_URL" env-default:"https://nominatim.openstreetmap.org"`
+	UserAgent string `yaml:"user_agent" env:"NOMINATIM_USER_AGENT" env-default:"geoservice/1.0"`
+	// RateLimit is the maximum number of requests per second sent to the API
+	RateLimit float64 `yaml:"rate_limit" env:"NOMINATIM_RATE_LIMIT" env-default:"1"`
+}
+
+type Gazetteer struct {
+	// Path is a CSV or GeoJSON file with city, street, house, lat and lon of every address
+	Path string `yaml:"path" env:"GAZETTEER_PATH"`
+}
+
+type Cache struct {
+	Enabled bool          `yaml:"enabled" env:"CACHE_ENABLED" env-default:"true"`
+	Size    int           `yaml:"size" env:"CACHE_SIZE" env-default:"10000"`
+	TTL     time.Duration `yaml:"ttl" env:"CACHE_TTL" env-default:"1h"`
+	// Precision is the number of decimal places geocode coordinates are rounded to,
+	// 4 places are roughly 11 meters
+	Precision int `yaml:"precision" env:"CACHE_PRECISION" env-default:"4"`
+	// StaleGrace is the time after expiration an entry is served when the provider is unavailable
+	StaleGrace           time.Duration   `yaml:"stale_grace" env:"CACHE_STALE_GRACE" env-default:"24h"`
+	StaleWhileRevalidate bool            `yaml:"stale_while_revalidate" env:"CACHE_STALE_WHILE_REVALIDATE" env-default:"false"`
+	RevalidateTimeout    time.Duration   `yaml:"revalidate_timeout" env:"CACHE_REVALIDATE_TIMEOUT" env-default:"10s"`
+	Persistent           PersistentCache `yaml:"persistent"`
+}
+
+// PersistentCache is an on-disk cache kept between restarts, it sits behind the in-memory one.
+type PersistentCache struct {
+	Enabled    bool          `yaml:"enabled" env:"PERSISTENT_CACHE_ENABLED" env-default:"false"`
+	Path       string        `yaml:"path" env:"PERSISTENT_CACHE_PATH" env-default:"data/geocache.db"`
+	MaxEntries int           `yaml:"max_entries" env:"PERSISTENT_CACHE_MAX_ENTRIES" env-default:"100000"`
+	TTL        time.Duration `yaml:"ttl" env:"PERSISTENT_CACHE_TTL" env-default:"168h"`
+	// StaleGrace is the time after expiration an entry is kept on disk
+	// and served when the provider is unavailable
+	StaleGrace      time.Duration `yaml:"stale_grace" env:"PERSISTENT_CACHE_STALE_GRACE" env-default:"720h"`
+	CompactInterval time.Duration `yaml:"compact_interval" env:"PERSISTENT_CACHE_COMPACT_INTERVAL" env-default:"10m"`
+}
+
+type Distance struct {
+	// MatrixMaxOrigins and MatrixMaxDestinations bound the size of a distance matrix
+	MatrixMaxOrigins      int `yaml:"matrix_max_origins" env:"DISTANCE_MATRIX_MAX_ORIGINS" env-default:"25"`
+	MatrixMaxDestinations int `yaml:"matrix_max_destinations" env:"DISTANCE_MATRIX_MAX_DESTINATIONS" env-default:"25"`
+	// Parallelism is the number of addresses of a request searched at once
+	Parallelism int `yaml:"parallelism" env:"DISTANCE_PARALLELISM" env-default:"4"`
+}
+
+type Autocomplete struct {
+	// Debounce is the quiet period after the last keystroke before the query is searched
+	Debounce  time.Duration `yaml:"debounce" env:"AUTOCOMPLETE_DEBOUNCE" env-default:"150ms"`
+	MinLength int           `yaml:"min_length" env:"AUTOCOMPLETE_MIN_LENGTH" env-default:"3"`
+	Count     int           `yaml:"count" env:"AUTOCOMPLETE_COUNT" env-default:"5"`
+	// PingInterval is the period of the keepalive pings of the WebSocket
+	PingInterval time.Duration `yaml:"ping_interval" env:"AUTOCOMPLETE_PING_INTERVAL" env-default:"30s"`
 }
 
 func MustLoadConfig(path string) *Config {
@@ -2,17 +2,47 @@ package controller
 
 import (
 	addressController "geo/internal/controller/http/v1/address"
+	adminController "geo/internal/controller/http/v1/admin"
 	authController "geo/internal/controller/http/v1/auth"
+	autocompleteController "geo/internal/controller/http/v1/autocomplete"
+	cleanController "geo/internal/controller/http/v1/clean"
+	distanceController "geo/internal/controller/http/v1/distance"
+	healthController "geo/internal/controller/http/v1/health"
+	jwksController "geo/internal/controller/http/v1/jwks"
+	keysController "geo/internal/controller/http/v1/keys"
+	placesController "geo/internal/controller/http/v1/places"
+	usersController "geo/internal/controller/http/v1/users"
 )
 
 type Controllers struct {
-	Auth    authController.Auther
-	Address addressController.Addresser
+	Auth         authController.Auther
+	Address      addressController.Addresser
+	Health       healthController.Checker
+	Admin        adminController.Administrator
+	Distance     distanceController.Distancer
+	Clean        cleanController.Cleaner
+	Autocomplete autocompleteController.Completer
+	Places       placesController.Placer
+	Users        usersController.RoleManager
+	Keys         keysController.KeyManager
+	JWKS         jwksController.Publisher
 }
 
-func New(auth authController.Auther, address addressController.Addresser) *Controllers {
+func New(auth authController.Auther, address addressController.Addresser, health healthController.Checker,
+	admin adminController.Administrator, distance distanceController.Distancer, clean cleanController.Cleaner,
+	autocomplete autocompleteController.Completer, places placesController.Placer, users usersController.RoleManager,
+	keys keysController.KeyManager, jwks jwksController.Publisher) *Controllers {
 	return &Controllers{
-		Auth:    auth,
-		Address: address,
+		Auth:         auth,
+		Address:      address,
+		Health:       health,
+		Admin:        admin,
+		Distance:     distance,
+		Clean:        clean,
+		Autocomplete: autocomplete,
+		Places:       places,
+		Users:        users,
+		Keys:         keys,
+		JWKS:         jwks,
 	}
 }
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	resp "geo/internal/lib/api/auth/response"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/apikey"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type principalCtxKey struct{}
+
+// PrincipalFromContext returns who the request authenticated with an API key acts for,
+// nil if the request was not authenticated with a key.
+func PrincipalFromContext(ctx context.Context) *apikey.Principal {
+	p, _ := ctx.Value(principalCtxKey{}).(*apikey.Principal)
+	return p
+}
+
+// KeyVerifier authenticates the requests carrying an API key in the X-API-Key header
+// or as Authorization: ApiKey <key>. It goes between jwtauth.Verifier and Authenticator.
+type KeyVerifier struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.APIKeys
+	// ttl bounds the token built for a key like the lifetime of an access token,
+	// so that a long-lived connection does not outlive a revoked key
+	ttl time.Duration
+}
+
+func NewKeyVerifier(log *slog.Logger, requestIdKey string, uc service.APIKeys, ttl time.Duration) *KeyVerifier {
+	return &KeyVerifier{
+		log:          log,
+		requestIdKey: requestIdKey,
+		uc:           uc,
+		ttl:          ttl,
+	}
+}
+
+// Middleware replaces the token found by jwtauth.Verifier with one for the owner of the key,
+// so that the handlers and RequireRoles treat both credentials alike. The requests without
+// a key are passed as they are.
+func (v *KeyVerifier) Middleware() func(http.Handler) http.Handler {
+	const op = "controller.middleware.KeyVerifier.Middleware"
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			secret := keyFromRequest(r)
+			if secret == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			log := v.log.With(
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+
+			ctx := context.WithValue(r.Context(), v.requestIdKey, middleware.GetReqID(r.Context()))
+			p, err := v.uc.Authenticate(ctx, secret)
+			if errors.Is(err, apikey.ErrExpired) {
+				log.Error("api key expired", sl.Err(err))
+				render.Render(w, r, resp.ErrAPIKeyExpired())
+				return
+			}
+			if errors.Is(err, apikey.ErrInvalidKey) {
+				log.Error("api key rejected", sl.Err(err))
+				render.Render(w, r, resp.ErrAPIKeyInvalid())
+				return
+			}
+			if err != nil {
+				log.Error("error authenticating api key", sl.Err(err))
+				render.Render(w, r, resp.ErrInternal())
+				return
+			}
+
+			token, err := v.token(p)
+			if err != nil {
+				log.Error("error building api key token", sl.Err(err))
+				render.Render(w, r, resp.ErrInternal())
+				return
+			}
+			log.Info("api key accepted", slog.String("key_id", p.KeyID))
+			ctx = jwtauth.NewContext(r.Context(), token, nil)
+			ctx = context.WithValue(ctx, principalCtxKey{}, p)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// token builds the token the requests of the key are handled with, it expires after ttl
+// or with the key, whichever comes first.
+func (v *KeyVerifier) token(p *apikey.Principal) (jwt.Token, error) {
+	exp := time.Now().Add(v.ttl)
+	if !p.ExpiresAt.IsZero() && p.ExpiresAt.Before(exp) {
+		exp = p.ExpiresAt
+	}
+	token := jwt.New()
+	if err := token.Set(jwt.SubjectKey, p.Owner); err != nil {
+		return nil, err
+	}
+	if err := token.Set(jwt.ExpirationKey, exp); err != nil {
+		return nil, err
+	}
+	if err := token.Set("roles", p.Roles); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RequireScope lets through the requests authenticated with a token
+// and the ones with a key allowed the scope.
+func (v *KeyVerifier) RequireScope(scope string) func(http.Handler) http.Handler {
+	const op = "controller.middleware.KeyVerifier.RequireScope"
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			if p := PrincipalFromContext(r.Context()); p != nil && !p.Allows(scope) {
+				v.log.Warn("api key lacks the required scope",
+					slog.String("op", op),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("key_id", p.KeyID),
+					slog.String("required", scope),
+				)
+				render.Render(w, r, resp.ErrAPIKeyScope())
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// RequireToken rejects the requests authenticated with a key, e.g. so that a key cannot issue keys.
+func (v *KeyVerifier) RequireToken() func(http.Handler) http.Handler {
+	const op = "controller.middleware.KeyVerifier.RequireToken"
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			if p := PrincipalFromContext(r.Context()); p != nil {
+				v.log.Warn("api key used where a token is required",
+					slog.String("op", op),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("key_id", p.KeyID),
+				)
+				render.Render(w, r, resp.ErrAPIKeyNotAllowed())
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+func keyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"geo/internal/service/apikey"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestKeyVerifier(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	_, bearer, err := ja.Encode(map[string]interface{}{
+		"sub": "alice", "jti": "1", "exp": time.Now().Add(time.Hour), "roles": []string{"user"},
+	})
+	require.NoError(t, err)
+	principal := &apikey.Principal{KeyID: "k1", Owner: "bob", Scopes: []string{apikey.ScopeAddress}, Roles: []string{"user"}}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		path       string
+		mockResult *apikey.Principal
+		mockError  error
+		wantSub    string
+		respStatus int
+	}{
+		{
+			name:       "bearer token",
+			header:     "Authorization",
+			value:      "Bearer " + bearer,
+			path:       "/address",
+			wantSub:    "alice",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "x-api-key header",
+			header:     "X-API-Key",
+			value:      "geo_key",
+			path:       "/address",
+			mockResult: principal,
+			wantSub:    "bob",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "authorization header",
+			header:     "Authorization",
+			value:      "ApiKey geo_key",
+			path:       "/address",
+			mockResult: principal,
+			wantSub:    "bob",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "scope not allowed",
+			header:     "X-API-Key",
+			value:      "geo_key",
+			path:       "/places",
+			mockResult: principal,
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "token only endpoint",
+			header:     "X-API-Key",
+			value:      "geo_key",
+			path:       "/keys",
+			mockResult: principal,
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid key",
+			header:     "X-API-Key",
+			value:      "geo_key",
+			path:       "/address",
+			mockError:  apikey.ErrInvalidKey,
+			respStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "expired key",
+			header:     "X-API-Key",
+			value:      "geo_key",
+			path:       "/address",
+			mockError:  apikey.ErrExpired,
+			respStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no credentials",
+			path:       "/address",
+			respStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keysMock := mocks.NewAPIKeys(t)
+			if tt.mockResult != nil || tt.mockError != nil {
+				keysMock.On("Authenticate", mock.Anything, "geo_key").Return(tt.mockResult, tt.mockError).Once()
+			}
+			authMock := mocks.NewAuth(t)
+			if tt.wantSub == "alice" {
+				authMock.On("IsTokenRevoked", mock.Anything, "1").Return(false).Once()
+			}
+			verifier := NewKeyVerifier(log, "request_id", keysMock, time.Hour)
+			authenticator := NewAuthenticator(log, authMock)
+
+			var sub string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				_, claims, _ := jwtauth.FromContext(r.Context())
+				sub, _ = claims["sub"].(string)
+				w.WriteHeader(http.StatusOK)
+			}
+			router := chi.NewRouter()
+			router.Use(jwtauth.Verifier(ja))
+			router.Use(verifier.Middleware())
+			router.Use(authenticator.Middleware())
+			router.Use(authenticator.RequireRoles("user"))
+			router.With(verifier.RequireScope(apikey.ScopeAddress)).Get("/address", handler)
+			router.With(verifier.RequireScope(apikey.ScopePlaces)).Get("/places", handler)
+			router.With(verifier.RequireToken()).Get("/keys", handler)
+
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			require.Equal(t, tt.wantSub, sub)
+		})
+	}
+}
+
+func TestKeyVerifier_Expiry(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		wantExp   time.Time
+	}{
+		{
+			name:    "key without expiry lasts the ttl",
+			wantExp: now.Add(time.Hour),
+		},
+		{
+			name:      "key expiring later lasts the ttl",
+			expiresAt: now.Add(24 * time.Hour),
+			wantExp:   now.Add(time.Hour),
+		},
+		{
+			name:      "key expiring sooner",
+			expiresAt: now.Add(time.Minute),
+			wantExp:   now.Add(time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keysMock := mocks.NewAPIKeys(t)
+			keysMock.On("Authenticate", mock.Anything, "geo_key").Return(&apikey.Principal{
+				KeyID: "k1", Owner: "bob", Roles: []string{"user"}, ExpiresAt: tt.expiresAt,
+			}, nil).Once()
+			verifier := NewKeyVerifier(log, "request_id", keysMock, time.Hour)
+
+			var exp time.Time
+			router := chi.NewRouter()
+			router.Use(verifier.Middleware())
+			router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				_, claims, _ := jwtauth.FromContext(r.Context())
+				exp, _ = claims["exp"].(time.Time)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-API-Key", "geo_key")
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			router.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+			require.WithinDuration(t, tt.wantExp, exp, time.Second)
+		})
+	}
+}
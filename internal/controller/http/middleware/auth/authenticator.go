@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"geo/internal/infrastructure/jwtKeys"
 	resp "geo/internal/lib/api/auth/response"
 	"geo/internal/lib/logger/sl"
 	"geo/internal/service"
@@ -10,6 +11,7 @@ import (
 	"github.com/go-chi/render"
 	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 )
 
@@ -34,6 +36,11 @@ func (a *Authenticator) Middleware() func(http.Handler) http.Handler {
 				slog.String("op", op),
 				slog.String("request_id", requestID),
 			)
+			if PrincipalFromContext(r.Context()) != nil {
+				// authenticated by KeyVerifier, the token of a key has no jti to revoke
+				next.ServeHTTP(w, r)
+				return
+			}
 			token, claims, err := jwtauth.FromContext(r.Context())
 			if errors.Is(err, jwtauth.ErrExpired) {
 				log.Error("token expired", sl.Err(err))
@@ -45,9 +52,20 @@ func (a *Authenticator) Middleware() func(http.Handler) http.Handler {
 				render.Render(w, r, resp.ErrNoTokenProvided())
 				return
 			}
+			if errors.Is(err, jwtKeys.ErrInvalidIssuer) {
+				log.Error("token of another issuer", sl.Err(err))
+				render.Render(w, r, resp.ErrTokenIssuer())
+				return
+			}
+			if errors.Is(err, jwtKeys.ErrInvalidAudience) {
+				log.Error("token of another audience", sl.Err(err))
+				render.Render(w, r, resp.ErrTokenAudience())
+				return
+			}
 			if err != nil {
 				log.Error("error getting token", sl.Err(err))
 				render.Render(w, r, resp.ErrInternal())
+				return
 			}
 			if token == nil {
 				log.Error("token is nil")
@@ -77,3 +95,44 @@ func (a *Authenticator) Middleware() func(http.Handler) http.Handler {
 		return http.HandlerFunc(hfn)
 	}
 }
+
+// RequireRoles lets through the requests whose token carries any of the roles.
+// It must follow Middleware.
+func (a *Authenticator) RequireRoles(roles ...string) func(http.Handler) http.Handler {
+	const op = "controller.middleware.Authenticator.RequireRoles"
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			log := a.log.With(
+				slog.String("op", op),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			granted := Roles(claims)
+			for _, role := range roles {
+				if slices.Contains(granted, role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			log.Warn("token lacks the required role", slog.Any("required", roles), slog.Any("granted", granted))
+			render.Render(w, r, resp.ErrInsufficientRole())
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+// Roles returns the roles carried by the token claims.
+func Roles(claims map[string]interface{}) []string {
+	var roles []string
+	switch v := claims["roles"].(type) {
+	case []string:
+		roles = v
+	case []interface{}:
+		for _, role := range v {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	}
+	return roles
+}
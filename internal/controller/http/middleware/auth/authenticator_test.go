@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"geo/internal/infrastructure/jwtKeys"
 	resp "geo/internal/lib/api/auth/response"
 	"geo/internal/service/mocks"
 	"github.com/brianvoe/gofakeit/v6"
@@ -135,3 +136,143 @@ func TestAuthenticator(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticator_RequireRoles(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+
+	tests := []struct {
+		name       string
+		roles      interface{}
+		required   []string
+		respStatus int
+	}{
+		{
+			name:       "has the role",
+			roles:      []string{"user"},
+			required:   []string{"user"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "has any of the roles",
+			roles:      []string{"admin"},
+			required:   []string{"user", "admin"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "lacks the role",
+			roles:      []string{"user", "auditor"},
+			required:   []string{"admin"},
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no roles claim",
+			required:   []string{"user"},
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "malformed roles claim",
+			roles:      "admin",
+			required:   []string{"admin"},
+			respStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]interface{}{"sub": "alice", "jti": "1", "exp": time.Now().Add(time.Hour)}
+			if tt.roles != nil {
+				claims["roles"] = tt.roles
+			}
+			_, token, err := ja.Encode(claims)
+			require.NoError(t, err)
+
+			router := chi.NewRouter()
+			router.Use(jwtauth.Verifier(ja))
+			router.Use(NewAuthenticator(log, nil).RequireRoles(tt.required...))
+			router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.respStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuthenticator_IssuerAudience(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	keys, err := jwtKeys.New("HS256", "secret", "", nil,
+		jwt.WithIssuer("geo.prod"), jwt.WithAudience("geo-api"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		iss        string
+		aud        string
+		wantHeader string
+		respStatus int
+	}{
+		{
+			name:       "success",
+			iss:        "geo.prod",
+			aud:        "geo-api",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "another issuer",
+			iss:        "geo.staging",
+			aud:        "geo-api",
+			wantHeader: `Bearer, error="invalid_token", error_description="Token was issued by another issuer"`,
+			respStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "another audience",
+			iss:        "geo.prod",
+			aud:        "billing",
+			wantHeader: `Bearer, error="invalid_token", error_description="Token was issued for another audience"`,
+			respStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, token, err := keys.JWTAuth().Encode(map[string]interface{}{
+				"iss": tt.iss,
+				"aud": tt.aud,
+				"sub": "alice",
+				"jti": "1",
+				"exp": time.Now().Add(time.Hour),
+			})
+			require.NoError(t, err)
+			useCaseMock := mocks.NewAuth(t)
+			if tt.respStatus == http.StatusOK {
+				useCaseMock.On("IsTokenRevoked", mock.Anything, "1").Return(false).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Use(Verifier(keys))
+			router.Use(NewAuthenticator(log, useCaseMock).Middleware())
+			router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "1")))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.wantHeader != "" {
+				require.Equal(t, tt.wantHeader, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
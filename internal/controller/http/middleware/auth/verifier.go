@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+)
+
+// TokenVerifier checks the signature and the claims of a token, the errors are the ones of jwtauth.
+type TokenVerifier interface {
+	Verify(token string) (jwt.Token, error)
+}
+
+// Verifier is jwtauth.Verifier for tokens signed by any of several keys: it finds the token
+// in the Authorization header or the jwt cookie, verifies it with v and puts it in the context
+// along with the error for Authenticator.
+func Verifier(v TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			tokenString := jwtauth.TokenFromHeader(r)
+			if tokenString == "" {
+				tokenString = jwtauth.TokenFromCookie(r)
+			}
+			var (
+				token jwt.Token
+				err   = jwtauth.ErrNoTokenFound
+			)
+			if tokenString != "" {
+				token, err = v.Verify(tokenString)
+			}
+			next.ServeHTTP(w, r.WithContext(jwtauth.NewContext(r.Context(), token, err)))
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type tokenVerifier struct {
+	ja *jwtauth.JWTAuth
+}
+
+func (v tokenVerifier) Verify(token string) (jwt.Token, error) {
+	return jwtauth.VerifyToken(v.ja, token)
+}
+
+func TestVerifier(t *testing.T) {
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	_, valid, err := ja.Encode(map[string]interface{}{"sub": "alice", "exp": time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, expired, err := ja.Encode(map[string]interface{}{"sub": "alice", "exp": time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		header  string
+		cookie  string
+		wantSub string
+		wantErr error
+	}{
+		{name: "header", header: "Bearer " + valid, wantSub: "alice"},
+		{name: "cookie", cookie: valid, wantSub: "alice"},
+		{name: "no token", wantErr: jwtauth.ErrNoTokenFound},
+		{name: "expired", header: "Bearer " + expired, wantErr: jwtauth.ErrExpired},
+		{name: "forged", header: "Bearer " + valid + "x", wantErr: jwtauth.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				sub    string
+				ctxErr error
+			)
+			handler := Verifier(tokenVerifier{ja})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var claims map[string]interface{}
+				_, claims, ctxErr = jwtauth.FromContext(r.Context())
+				sub, _ = claims["sub"].(string)
+			}))
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "jwt", Value: tt.cookie})
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.True(t, errors.Is(ctxErr, tt.wantErr), "error = %v, want %v", ctxErr, tt.wantErr)
+			if tt.wantErr == nil {
+				require.Equal(t, tt.wantSub, sub)
+			}
+		})
+	}
+}
@@ -6,9 +6,10 @@ import (
 	"geo/internal/controller"
 	"geo/internal/controller/http/middleware/auth"
 	"geo/internal/controller/http/middleware/logger"
+	"geo/internal/service/apikey"
+	authService "geo/internal/service/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/jwtauth/v5"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"log/slog"
 	"net/http"
@@ -16,7 +17,8 @@ import (
 
 type AuthMiddleware struct {
 	Authenticator *auth.Authenticator
-	Ja            *jwtauth.JWTAuth
+	KeyVerifier   *auth.KeyVerifier
+	Verifier      auth.TokenVerifier
 }
 
 //	@Title			Geoservice API
@@ -35,11 +37,31 @@ type AuthMiddleware struct {
 //	@name						Authorization
 //	@description				Specify the Bearer token in the format `Bearer <your_token>`
 
+//	@securitydefinitions.apikey	XAPIKey
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key of a machine client, also accepted as `Authorization: ApiKey <key>`
+
 // @Tag.name			address
 // @Tag.description	Get array of addresses
 
 // @Tag.name			auth
 // @Tag.description	Authorization and authentication
+
+// @Tag.name			health
+// @Tag.description	Service and upstream providers state
+
+// @Tag.name			admin
+// @Tag.description	Service maintenance
+
+// @Tag.name			distance
+// @Tag.description	Distances between coordinates and addresses
+
+// @Tag.name			places
+// @Tag.description	Saved places of the user
+
+// @Tag.name			keys
+// @Tag.description	API keys of the user for machine clients
 func NewRouter(log *slog.Logger, cfg *config.Config, controllers *controller.Controllers, am *AuthMiddleware) http.Handler {
 	router := chi.NewRouter()
 	router.Use(middleware.RequestID)
@@ -48,17 +70,57 @@ func NewRouter(log *slog.Logger, cfg *config.Config, controllers *controller.Con
 
 	router.Route("/api", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
-			r.Use(jwtauth.Verifier(am.Ja))
+			r.Use(auth.Verifier(am.Verifier))
+			r.Use(am.KeyVerifier.Middleware())
 			r.Use(am.Authenticator.Middleware())
+			r.Use(am.Authenticator.RequireRoles(authService.RoleUser, authService.RoleAdmin))
 			r.Route("/address", func(r chi.Router) {
+				r.Use(am.KeyVerifier.RequireScope(apikey.ScopeAddress))
 				r.Post("/search", controllers.Address.Search)
+				r.Post("/search/bulk", controllers.Address.SearchBulk)
 				r.Post("/geocode", controllers.Address.Geocode)
+				r.Post("/geocode/batch", controllers.Address.GeocodeBatch)
+				r.Post("/clean", controllers.Clean.Clean)
+				r.Get("/autocomplete", controllers.Autocomplete.Autocomplete)
+			})
+			r.Route("/distance", func(r chi.Router) {
+				r.Use(am.KeyVerifier.RequireScope(apikey.ScopeDistance))
+				r.Post("/", controllers.Distance.Distance)
+				r.Post("/matrix", controllers.Distance.Matrix)
+			})
+			r.Route("/places", func(r chi.Router) {
+				r.Use(am.KeyVerifier.RequireScope(apikey.ScopePlaces))
+				r.Post("/", controllers.Places.Create)
+				r.Get("/", controllers.Places.List)
+				r.Get("/{id}", controllers.Places.Get)
+				r.Patch("/{id}", controllers.Places.Update)
+				r.Delete("/{id}", controllers.Places.Delete)
+			})
+			r.Route("/keys", func(r chi.Router) {
+				r.Use(am.KeyVerifier.RequireToken())
+				r.Post("/", controllers.Keys.Create)
+				r.Get("/", controllers.Keys.List)
+				r.Patch("/{id}", controllers.Keys.Update)
+				r.Delete("/{id}", controllers.Keys.Revoke)
+			})
+			r.With(am.KeyVerifier.RequireToken()).Delete("/logout", controllers.Auth.Logout)
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(am.KeyVerifier.RequireToken())
+				r.Use(am.Authenticator.RequireRoles(authService.RoleAdmin))
+				r.Delete("/cache", controllers.Admin.PurgeCache)
+				r.Route("/users/{login}/roles", func(r chi.Router) {
+					r.Get("/", controllers.Users.Roles)
+					r.Put("/{role}", controllers.Users.GrantRole)
+					r.Delete("/{role}", controllers.Users.RevokeRole)
+				})
 			})
-			r.Delete("/logout", controllers.Auth.Logout)
 		})
 		r.Post("/login", controllers.Auth.Login)
+		r.Post("/token/refresh", controllers.Auth.Refresh)
 		r.Post("/register", controllers.Auth.Register)
+		r.Get("/health", controllers.Health.Health)
 	})
+	router.Get("/.well-known/jwks.json", controllers.JWKS.JWKS)
 	router.Get("/swagger/my.yaml", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "docs/my.yaml")
 	})
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"geo/internal/config"
+	"geo/internal/controller"
+	"geo/internal/controller/http/middleware/auth"
+	"geo/internal/infrastructure/jwtKeys"
+	"geo/internal/service/apikey"
+	"geo/internal/service/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// handlers answers every route with 200 and counts the calls
+type handlers struct {
+	calls int
+}
+
+func (h *handlers) serve(w http.ResponseWriter, _ *http.Request) {
+	h.calls++
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *handlers) Login(w http.ResponseWriter, r *http.Request)        { h.serve(w, r) }
+func (h *handlers) Logout(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) Refresh(w http.ResponseWriter, r *http.Request)      { h.serve(w, r) }
+func (h *handlers) Register(w http.ResponseWriter, r *http.Request)     { h.serve(w, r) }
+func (h *handlers) Search(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) SearchBulk(w http.ResponseWriter, r *http.Request)   { h.serve(w, r) }
+func (h *handlers) Geocode(w http.ResponseWriter, r *http.Request)      { h.serve(w, r) }
+func (h *handlers) GeocodeBatch(w http.ResponseWriter, r *http.Request) { h.serve(w, r) }
+func (h *handlers) Health(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) PurgeCache(w http.ResponseWriter, r *http.Request)   { h.serve(w, r) }
+func (h *handlers) Distance(w http.ResponseWriter, r *http.Request)     { h.serve(w, r) }
+func (h *handlers) Matrix(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) Clean(w http.ResponseWriter, r *http.Request)        { h.serve(w, r) }
+func (h *handlers) Autocomplete(w http.ResponseWriter, r *http.Request) { h.serve(w, r) }
+func (h *handlers) Create(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) List(w http.ResponseWriter, r *http.Request)         { h.serve(w, r) }
+func (h *handlers) Get(w http.ResponseWriter, r *http.Request)          { h.serve(w, r) }
+func (h *handlers) Update(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) Delete(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) Revoke(w http.ResponseWriter, r *http.Request)       { h.serve(w, r) }
+func (h *handlers) Roles(w http.ResponseWriter, r *http.Request)        { h.serve(w, r) }
+func (h *handlers) GrantRole(w http.ResponseWriter, r *http.Request)    { h.serve(w, r) }
+func (h *handlers) RevokeRole(w http.ResponseWriter, r *http.Request)   { h.serve(w, r) }
+func (h *handlers) JWKS(w http.ResponseWriter, r *http.Request)         { h.serve(w, r) }
+
+func TestRouter_PurgeCacheRequiresAdmin(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	keys, err := jwtKeys.New("HS256", "secret", "", nil)
+	require.NoError(t, err)
+	token := func(roles ...string) string {
+		_, token, err := keys.JWTAuth().Encode(map[string]interface{}{
+			"sub": "alice", "jti": "1", "exp": time.Now().Add(time.Hour), "roles": roles,
+		})
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+	principal := &apikey.Principal{KeyID: "k1", Owner: "bob", Scopes: apikey.Scopes, Roles: []string{"admin"}}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantCalls  int
+		respStatus int
+	}{
+		{
+			name:       "admin token",
+			header:     "Authorization",
+			value:      token("user", "admin"),
+			wantCalls:  1,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "user token",
+			header:     "Authorization",
+			value:      token("user"),
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "api key of an admin",
+			header:     "X-API-Key",
+			value:      "geo_key",
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no credentials",
+			respStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMock := mocks.NewAuth(t)
+			authMock.On("IsTokenRevoked", mock.Anything, "1").Return(false).Maybe()
+			keysMock := mocks.NewAPIKeys(t)
+			keysMock.On("Authenticate", mock.Anything, "geo_key").Return(principal, nil).Maybe()
+
+			h := &handlers{}
+			router := NewRouter(log, &config.Config{},
+				controller.New(h, h, h, h, h, h, h, h, h, h, h),
+				&AuthMiddleware{
+					Authenticator: auth.NewAuthenticator(log, authMock),
+					KeyVerifier:   auth.NewKeyVerifier(log, "request_id", keysMock, time.Hour),
+					Verifier:      keys,
+				})
+
+			req, err := http.NewRequest(http.MethodDelete, "/api/admin/cache", nil)
+			require.NoError(t, err)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			require.Equal(t, tt.wantCalls, h.calls)
+		})
+	}
+}
@@ -2,20 +2,43 @@ package address
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"geo/internal/infrastructure/responder"
 	"geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/api/coordinate"
+	"geo/internal/lib/api/validation"
 	"geo/internal/lib/logger/sl"
 	"geo/internal/service"
+	"geo/internal/service/geo"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 type Addresser interface {
 	Geocode(http.ResponseWriter, *http.Request)
+	GeocodeBatch(http.ResponseWriter, *http.Request)
 	Search(http.ResponseWriter, *http.Request)
+	SearchBulk(http.ResponseWriter, *http.Request)
+}
+
+// Limits bound the work a single request may cause.
+type Limits struct {
+	// BatchParallelism is the number of batch items or bulk rows processed at once
+	BatchParallelism int
+	// BatchMaxItems is the maximum number of items in a batch
+	BatchMaxItems int
+	// BulkColumn is the CSV column holding the query when the request does not name one
+	BulkColumn string
+	// BulkMaxRows is the maximum number of rows of a bulk search file
+	BulkMaxRows int
+	// ReadTimeout and WriteTimeout are the server timeouts, a bulk search
+	// extends them for every row it reads or writes; zero means no timeout
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
 }
 
 type Address struct {
@@ -23,36 +46,78 @@ type Address struct {
 	requestIdKey string
 	uc           service.Geo
 	responder    responder.Responder
+	limits       Limits
 }
 
-func New(log *slog.Logger, requestIdKey string, uc service.Geo, responder responder.Responder) *Address {
-	return &Address{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder}
+func New(log *slog.Logger, requestIdKey string, uc service.Geo, responder responder.Responder, limits Limits) *Address {
+	if limits.BatchParallelism <= 0 {
+		limits.BatchParallelism = 1
+	}
+	return &Address{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder, limits: limits}
 }
 
 type GeocodeRequest struct {
-	Lat string `json:"lat" example:"55.8481373"`
-	Lng string `json:"lng" example:"37.6414907"`
+	Lat coordinate.Coordinate `json:"lat" swaggertype:"number" example:"55.8481373"`
+	Lng coordinate.Coordinate `json:"lng" swaggertype:"number" example:"37.6414907"`
+	// RadiusMeters is the search radius around the point, the provider default is used when omitted
+	RadiusMeters int `json:"radius_meters,omitempty" minimum:"1" maximum:"1000" example:"100"`
+	// Count is the maximum number of addresses returned
+	Count int `json:"count,omitempty" minimum:"1" maximum:"20" example:"5"`
+	// Language is the language of the returned addresses
+	Language string `json:"language,omitempty" example:"en"`
+
+	point geo.Point
 }
 
 func (gr *GeocodeRequest) Bind(r *http.Request) error {
-	if gr.Lat == "" || gr.Lng == "" {
-		return fmt.Errorf("lat, lng cannot be empty")
+	var vErr validation.Error
+	lat, err := geo.ParseLatitude(string(gr.Lat))
+	if err != nil {
+		vErr.Add("lat", err)
+	}
+	lon, err := geo.ParseLongitude(string(gr.Lng))
+	if err != nil {
+		vErr.Add("lng", err)
+	}
+	if err := geo.ValidateRadius(gr.RadiusMeters); err != nil {
+		vErr.Add("radius_meters", err)
+	}
+	if err := geo.ValidateCount(gr.Count); err != nil {
+		vErr.Add("count", err)
+	}
+	if err := geo.ValidateLanguage(gr.Language); err != nil {
+		vErr.Add("language", err)
 	}
-	return nil
+	gr.point = geo.Point{Lat: lat, Lon: lon}
+	return vErr.Err()
 }
 
-// @Summary	Array of addresses located at specified coordinates
-// @Tags		address
-// @Param		coordinates	body		GeocodeRequest	true	"object coordinates"
-// @Success	200			{object}	addressResponse.Response
-// @Failure	400			{object}	response.ErrResponse	"invalid lat or lng format"
+func (gr *GeocodeRequest) options() geo.GeocodeOptions {
+	return geo.GeocodeOptions{RadiusMeters: gr.RadiusMeters, Count: gr.Count, Language: gr.Language}
+}
+
+// @Summary		Array of addresses located at specified coordinates
+// @Description	The addresses can be exported as GeoJSON, CSV, KML or GPX with the format parameter or the Accept header.
+// @Tags			address
+// @Produce		json
+// @Produce		application/geo+json
+// @Produce		text/csv
+// @Produce		application/vnd.google-earth.kml+xml
+// @Produce		application/gpx+xml
+// @Param			coordinates	body		GeocodeRequest	true	"object coordinates"
+// @Param			format		query		string			false	"output format, overrides the Accept header"	Enums(json, geojson, csv, kml, gpx)
+// @Success		200			{object}	addressResponse.Response	"addresses, or a geojson.FeatureCollection with Accept: application/geo+json"
+// @Header			200			{string}	Warning	"Response is Stale, when cached addresses are served because the provider is unavailable"
+// @Failure		400			{object}	responder.Response{data=validation.Error}	"invalid lat, lng or options, an option the provider does not support, unknown format"
 //
-// @Failure	401			"Unauthorized: Token missing or invalid"
-// @Header		401			{string}	WWW-Authenticate	"Bearer"
+// @Failure		401			"Unauthorized: Token missing or invalid"
+// @Header			401			{string}	WWW-Authenticate	"Bearer"
 //
-// @Failure	500			{object}	response.ErrResponse
-// @Security	ApiKeyAuth
-// @Router		/address/geocode [post]
+// @Failure		500			{object}	response.ErrResponse
+// @Failure		504			{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/address/geocode [post]
 func (a *Address) Geocode(w http.ResponseWriter, r *http.Request) {
 	const op = "controller.address.Geocode"
 	log := a.log.With(
@@ -60,6 +125,11 @@ func (a *Address) Geocode(w http.ResponseWriter, r *http.Request) {
 		slog.String("request_id", middleware.GetReqID(r.Context())),
 	)
 
+	if err := a.responder.CheckFormat(r); err != nil {
+		a.responder.ErrorBadRequest(w, err)
+		return
+	}
+
 	data := &GeocodeRequest{}
 	if err := render.Bind(r, data); err != nil {
 		log.Error("error decoding request", sl.Err(err))
@@ -69,40 +139,104 @@ func (a *Address) Geocode(w http.ResponseWriter, r *http.Request) {
 	log.Info("request received", slog.Any("data", data))
 
 	ctx := context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context()))
-	addresses, err := a.uc.Geocode(ctx, data.Lat, data.Lng)
-	if err != nil {
+	ctx, freshness := geo.WithFreshness(ctx)
+	addresses, err := a.uc.Geocode(ctx, data.point, data.options())
+	if errors.Is(err, geo.ErrInvalidPoint) || errors.Is(err, geo.ErrInvalidOptions) ||
+		errors.Is(err, geo.ErrUnsupportedOption) {
+		log.Error("invalid geocode request", sl.Err(err))
+		a.responder.ErrorBadRequest(w, err)
+		return
+	} else if errors.Is(err, geo.ErrUpstreamTimeout) {
+		log.Error("geo provider timed out", sl.Err(err))
+		a.responder.ErrorGatewayTimeout(w, err)
+		return
+	} else if err != nil {
 		log.Error("failed to get addresses using lat and lng", sl.Err(err))
 		a.responder.ErrorInternal(w, err)
 		return
 	}
 
-	log.Info("request executed", slog.Any("response", addressResponse.NewResponse(addresses)))
-	a.responder.OutputJSON(w, addressResponse.NewResponse(addresses))
+	res := addressResponse.NewResponse(addresses)
+	if freshness.Stale() {
+		log.Warn("serving stale addresses")
+		res.MarkStale(w)
+	}
+	log.Info("request executed", slog.Any("response", res))
+	a.responder.Output(w, r, res)
 }
 
 type SearchRequest struct {
 	Query string `json:"query" example:"г Москва, ул Снежная"`
+	// Locations keep only the addresses inside any of them
+	Locations []geo.Location `json:"locations,omitempty"`
+	// LocationsBoost rank the addresses inside them first
+	LocationsBoost []geo.BoostLocation `json:"locations_boost,omitempty"`
+	// FromBound and ToBound limit the address levels returned, e.g. city to city returns only cities
+	FromBound geo.Granularity `json:"from_bound,omitempty" example:"street"`
+	ToBound   geo.Granularity `json:"to_bound,omitempty" example:"street"`
+	// Count is the maximum number of addresses returned
+	Count int `json:"count,omitempty" minimum:"1" maximum:"20" example:"10"`
 }
 
 func (sr *SearchRequest) Bind(r *http.Request) error {
 	if sr.Query == "" {
 		return fmt.Errorf("query cannot be empty")
 	}
-	return nil
+	var vErr validation.Error
+	if err := geo.ValidateSearchCount(sr.Count); err != nil {
+		vErr.Add("count", err)
+	}
+	if err := geo.ValidateLocations(sr.Locations); err != nil {
+		vErr.Add("locations", err)
+	}
+	if err := geo.ValidateBoostLocations(sr.LocationsBoost); err != nil {
+		vErr.Add("locations_boost", err)
+	}
+	if err := geo.ValidateGranularity(sr.FromBound); err != nil {
+		vErr.Add("from_bound", err)
+	}
+	if err := geo.ValidateGranularity(sr.ToBound); err != nil {
+		vErr.Add("to_bound", err)
+	} else if err := geo.ValidateBoundOrder(sr.FromBound, sr.ToBound); err != nil {
+		vErr.Add("to_bound", err)
+	}
+	return vErr.Err()
 }
 
-// @Summary	Array of addresses located at specified location
-// @Tags		address
-// @Param		query	body		SearchRequest	true	"object location"
-// @Success	200		{object}	addressResponse.Response
-// @Failure	400		{object}	response.ErrResponse	"invalid query format"
+func (sr *SearchRequest) options() geo.SearchOptions {
+	return geo.SearchOptions{
+		Locations:      sr.Locations,
+		LocationsBoost: sr.LocationsBoost,
+		FromBound:      sr.FromBound,
+		ToBound:        sr.ToBound,
+		Count:          sr.Count,
+	}
+}
+
+// @Summary		Array of addresses located at specified location
+// @Description	Optional filters restrict results to locations and address levels,
+// @Description	e.g. only the streets of one city or only cities.
+// @Description	The addresses can be exported as GeoJSON, CSV, KML or GPX with the format parameter or the Accept header.
+// @Tags			address
+// @Produce		json
+// @Produce		application/geo+json
+// @Produce		text/csv
+// @Produce		application/vnd.google-earth.kml+xml
+// @Produce		application/gpx+xml
+// @Param			query	body		SearchRequest	true	"object location"
+// @Param			format	query		string			false	"output format, overrides the Accept header"	Enums(json, geojson, csv, kml, gpx)
+// @Success		200		{object}	addressResponse.Response					"addresses, or a geojson.FeatureCollection with Accept: application/geo+json"
+// @Header			200		{string}	Warning										"Response is Stale, when cached addresses are served because the provider is unavailable"
+// @Failure		400		{object}	responder.Response{data=validation.Error}	"invalid query or filters, a filter the provider does not support, unknown format"
 //
-// @Failure	401		"Unauthorized: Token missing or invalid"
-// @Header		401		{string}	WWW-Authenticate	"Bearer"
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
 //
-// @Failure	500		{object}	response.ErrResponse
-// @Security	ApiKeyAuth
-// @Router		/address/search [post]
+// @Failure		500		{object}	response.ErrResponse
+// @Failure		504		{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/address/search [post]
 func (a *Address) Search(w http.ResponseWriter, r *http.Request) {
 	const op = "controller.address.Search"
 	log := a.log.With(
@@ -110,6 +244,11 @@ func (a *Address) Search(w http.ResponseWriter, r *http.Request) {
 		slog.String("request_id", middleware.GetReqID(r.Context())),
 	)
 
+	if err := a.responder.CheckFormat(r); err != nil {
+		a.responder.ErrorBadRequest(w, err)
+		return
+	}
+
 	data := &SearchRequest{}
 	if err := render.Bind(r, data); err != nil {
 		log.Error("error decoding request: ", sl.Err(err))
@@ -119,13 +258,26 @@ func (a *Address) Search(w http.ResponseWriter, r *http.Request) {
 	log.Info("request received", slog.Any("data", data))
 
 	ctx := context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context()))
-	addresses, err := a.uc.Search(ctx, data.Query)
-	if err != nil {
+	ctx, freshness := geo.WithFreshness(ctx)
+	addresses, err := a.uc.Search(ctx, data.Query, data.options())
+	if errors.Is(err, geo.ErrInvalidOptions) || errors.Is(err, geo.ErrUnsupportedOption) {
+		log.Error("invalid search request", sl.Err(err))
+		a.responder.ErrorBadRequest(w, err)
+		return
+	} else if errors.Is(err, geo.ErrUpstreamTimeout) {
+		log.Error("geo provider timed out", sl.Err(err))
+		a.responder.ErrorGatewayTimeout(w, err)
+		return
+	} else if err != nil {
 		log.Error("failed to get addresses using query \"%s\": ", data.Query, sl.Err(err))
 		a.responder.ErrorInternal(w, err)
 		return
 	}
-	log.Info("request executed", slog.Any("response", addressResponse.NewResponse(addresses)))
-
-	a.responder.OutputJSON(w, addressResponse.NewResponse(addresses))
+	res := addressResponse.NewResponse(addresses)
+	if freshness.Stale() {
+		log.Warn("serving stale addresses")
+		res.MarkStale(w)
+	}
+	log.Info("request executed", slog.Any("response", res))
+	a.responder.Output(w, r, res)
 }
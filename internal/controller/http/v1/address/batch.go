@@ -0,0 +1,115 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service/geo"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// maxBatchItemBytes is the room left for a single batch item in the request body,
+// a pretty-printed item with every option set takes about 200 bytes
+const maxBatchItemBytes = 1 << 10
+
+// @Summary		Addresses located at every specified coordinates
+// @Description	Items are geocoded concurrently, results are returned in the order of the request items.
+// @Description	A failed item carries an error and does not fail the whole batch.
+// @Description	The results can be exported as CSV, KML or GPX with the format parameter or the Accept header.
+// @Tags			address
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.google-earth.kml+xml
+// @Produce		application/gpx+xml
+// @Param			coordinates	body		[]GeocodeRequest	true	"array of object coordinates"
+// @Param			format		query		string				false	"output format, overrides the Accept header"	Enums(json, csv, kml, gpx)
+// @Success		200			{object}	addressResponse.BatchResponse
+// @Failure		400			{object}	response.ErrResponse	"invalid request format, empty or too large batch, unknown format"
+//
+// @Failure		401			"Unauthorized: Token missing or invalid"
+// @Header			401			{string}	WWW-Authenticate	"Bearer"
+//
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/address/geocode/batch [post]
+func (a *Address) GeocodeBatch(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.address.GeocodeBatch"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	if err := a.responder.CheckFormat(r); err != nil {
+		a.responder.ErrorBadRequest(w, err)
+		return
+	}
+	if a.limits.BatchMaxItems > 0 {
+		// the body is decoded at once, so its size is bounded before the items are counted
+		r.Body = http.MaxBytesReader(w, r.Body, int64(a.limits.BatchMaxItems)*maxBatchItemBytes)
+	}
+	var data []*GeocodeRequest
+	if err := render.DecodeJSON(r.Body, &data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = fmt.Errorf("batch cannot be larger than %d bytes", tooLarge.Limit)
+		}
+		a.responder.ErrorBadRequest(w, err)
+		return
+	}
+	if len(data) == 0 {
+		a.responder.ErrorBadRequest(w, fmt.Errorf("batch cannot be empty"))
+		return
+	}
+	if a.limits.BatchMaxItems > 0 && len(data) > a.limits.BatchMaxItems {
+		a.responder.ErrorBadRequest(w, fmt.Errorf("batch cannot contain more than %d items", a.limits.BatchMaxItems))
+		return
+	}
+	log.Info("request received", slog.Int("items", len(data)))
+
+	ctx := context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context()))
+	res := addressResponse.NewBatchResponse(len(data))
+	items := make(chan int)
+	var wg sync.WaitGroup
+	for range min(a.limits.BatchParallelism, len(data)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range items {
+				res.Results[i] = a.geocodeItem(ctx, log, r, data[i])
+			}
+		}()
+	}
+	for i := range data {
+		items <- i
+	}
+	close(items)
+	wg.Wait()
+
+	log.Info("request executed")
+	a.responder.Output(w, r, res)
+}
+
+// geocodeItem geocodes a single batch item, its failure is reported in the result.
+func (a *Address) geocodeItem(ctx context.Context, log *slog.Logger, r *http.Request, data *GeocodeRequest) *addressResponse.BatchItem {
+	if data == nil {
+		data = &GeocodeRequest{}
+	}
+	if err := data.Bind(r); err != nil {
+		return &addressResponse.BatchItem{Error: err.Error()}
+	}
+
+	ctx, freshness := geo.WithFreshness(ctx)
+	addresses, err := a.uc.Geocode(ctx, data.point, data.options())
+	if err != nil {
+		log.Error("failed to get addresses using lat and lng", slog.Any("data", data), sl.Err(err))
+		return &addressResponse.BatchItem{Error: err.Error()}
+	}
+	return &addressResponse.BatchItem{Addresses: addresses, Stale: freshness.Stale()}
+}
@@ -0,0 +1,232 @@
+package address
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service/geo"
+	"github.com/go-chi/chi/v5/middleware"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// bulkFile is the multipart form field holding the uploaded CSV
+const bulkFile = "file"
+
+type bulkRow struct {
+	n     int
+	query string
+	res   *addressResponse.BulkRow
+	done  chan struct{}
+}
+
+// @Summary		Search every address of a CSV file
+// @Description	The CSV is sent as the request body or as the file field of a multipart form, its first row is the header.
+// @Description	Rows are searched concurrently and streamed back in input order while the file is processed,
+// @Description	every output row holds the input row number, the top match, the number of matches and the row error.
+// @Tags			address
+// @Accept			text/csv
+// @Accept			mpfd
+// @Produce		text/csv
+// @Produce		application/x-ndjson
+// @Param			file	formData	file	false	"CSV file"
+// @Param			column	query		string	false	"header of the column holding the query, address by default"
+// @Param			format	query		string	false	"output format"	Enums(csv, ndjson)	default(csv)
+// @Success		200		{array}		addressResponse.BulkRow
+// @Failure		400		{object}	response.ErrResponse	"invalid CSV, unknown column or format"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/address/search/bulk [post]
+func (a *Address) SearchBulk(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.address.SearchBulk"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = addressResponse.FormatCSV
+		if accept, _, _ := mime.ParseMediaType(r.Header.Get("Accept")); accept == "application/x-ndjson" {
+			format = addressResponse.FormatNDJSON
+		}
+	}
+	out, ok := addressResponse.NewBulkWriter(w, format)
+	if !ok {
+		a.responder.ErrorBadRequest(w, fmt.Errorf("unknown format %q", format))
+		return
+	}
+	column := r.URL.Query().Get("column")
+	if column == "" {
+		column = a.limits.BulkColumn
+	}
+
+	// the upload is read and the results are written while rows are searched,
+	// so the server timeouts are extended row by row instead of covering the whole file
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(deadline(a.limits.ReadTimeout))
+	// HTTP/1 otherwise drains the rest of the upload on the first flush of the results
+	_ = rc.EnableFullDuplex()
+
+	body, err := bulkBody(r)
+	if err != nil {
+		log.Error("error reading upload", sl.Err(err))
+		a.responder.ErrorBadRequest(w, err)
+		return
+	}
+	defer body.Close()
+	in := csv.NewReader(body)
+	in.FieldsPerRecord = -1
+	header, err := in.Read()
+	if err != nil {
+		log.Error("error reading CSV header", sl.Err(err))
+		a.responder.ErrorBadRequest(w, fmt.Errorf("cannot read CSV header: %w", err))
+		return
+	}
+	idx := columnIndex(header, column)
+	if idx < 0 {
+		a.responder.ErrorBadRequest(w, fmt.Errorf("column %q not found", column))
+		return
+	}
+	log.Info("request received", slog.String("column", column), slog.String("format", format))
+
+	_ = rc.SetWriteDeadline(deadline(a.limits.WriteTimeout))
+	w.Header().Set("Content-Type", out.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if err := out.WriteHeader(); err != nil {
+		log.Error("failed to write response", sl.Err(err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context())))
+	defer cancel()
+	pending := make(chan *bulkRow, a.limits.BatchParallelism)
+	go a.searchRows(ctx, log, rc, in, idx, pending)
+
+	rows := 0
+	for row := range pending {
+		<-row.done
+		_ = rc.SetWriteDeadline(deadline(a.limits.WriteTimeout))
+		if err := out.Write(row.res); err != nil {
+			log.Error("failed to write response", sl.Err(err))
+			cancel()
+			// drain the rows in flight
+			for row := range pending {
+				<-row.done
+			}
+			return
+		}
+		_ = rc.Flush()
+		rows++
+	}
+	log.Info("request executed", slog.Int("rows", rows))
+}
+
+// searchRows reads the rows and searches up to BatchParallelism of them at once,
+// rows are sent to pending in input order.
+func (a *Address) searchRows(ctx context.Context, log *slog.Logger, rc *http.ResponseController,
+	in *csv.Reader, idx int, pending chan<- *bulkRow) {
+	defer close(pending)
+	sem := make(chan struct{}, a.limits.BatchParallelism)
+	// the header is row 1
+	for n := 2; ctx.Err() == nil; n++ {
+		row := &bulkRow{n: n, done: make(chan struct{})}
+		_ = rc.SetReadDeadline(deadline(a.limits.ReadTimeout))
+		record, err := in.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			log.Error("error reading CSV row", slog.Int("row", n), sl.Err(err))
+			row.res = &addressResponse.BulkRow{Row: n, Error: err.Error()}
+			close(row.done)
+			pending <- row
+			return
+		}
+		if a.limits.BulkMaxRows > 0 && n-1 > a.limits.BulkMaxRows {
+			row.res = &addressResponse.BulkRow{Row: n, Error: fmt.Sprintf("file cannot contain more than %d rows", a.limits.BulkMaxRows)}
+			close(row.done)
+			pending <- row
+			return
+		}
+		if idx < len(record) {
+			row.query = strings.TrimSpace(record[idx])
+		}
+
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem }()
+			a.searchRow(ctx, log, row)
+		}()
+		pending <- row
+	}
+}
+
+func (a *Address) searchRow(ctx context.Context, log *slog.Logger, row *bulkRow) {
+	defer close(row.done)
+	if row.query == "" {
+		row.res = &addressResponse.BulkRow{Row: row.n, Error: "query cannot be empty"}
+		return
+	}
+	addresses, err := a.uc.Search(ctx, row.query, geo.SearchOptions{})
+	if err != nil {
+		log.Error("failed to get addresses using query", slog.Int("row", row.n), sl.Err(err))
+		row.res = &addressResponse.BulkRow{Row: row.n, Error: err.Error()}
+		return
+	}
+	row.res = addressResponse.NewBulkRow(row.n, addresses)
+}
+
+// deadline is the deadline of a step allowed timeout, zero timeout means none as with http.Server.
+func deadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
+
+// bulkBody returns the uploaded file of a multipart form or the request body.
+func bulkBody(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("form field %q not found", bulkFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == bulkFile {
+			return part, nil
+		}
+	}
+}
+
+func columnIndex(header []string, column string) int {
+	for i, h := range header {
+		// spreadsheets often save CSV with a byte order mark
+		h = strings.TrimPrefix(h, "\ufeff")
+		if strings.EqualFold(strings.TrimSpace(h), column) {
+			return i
+		}
+	}
+	return -1
+}
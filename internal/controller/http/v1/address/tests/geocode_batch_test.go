@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	"geo/internal/controller/http/v1/address"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/address/addressExport"
+	resp "geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/api/geojson"
+	"geo/internal/service/geo"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAddressGeocodeBatchHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+	limits := address.Limits{BatchParallelism: 2, BatchMaxItems: 3}
+
+	snezhnaya := []*geo.Address{
+		{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+	}
+	nevsky := []*geo.Address{
+		{City: "Санкт-Петербург", Street: "Невский", House: "28", Lat: "59.935634", Lon: "30.325935"},
+	}
+
+	type call struct {
+		point     geo.Point
+		addresses []*geo.Address
+		err       error
+		stale     bool
+	}
+	tests := []struct {
+		name       string
+		body       string
+		calls      []call
+		want       resp.BatchResponse
+		respStatus int
+	}{
+		{
+			name: "results in input order",
+			body: `[{"lat":"55.8481373","lng":"37.6414907"},{"lat":"","lng":"37.6"},` +
+				`{"lat":"59.935634","lng":"30.325935"}]`,
+			calls: []call{
+				{point: geo.Point{Lat: 55.8481373, Lon: 37.6414907}, addresses: snezhnaya},
+				{point: geo.Point{Lat: 59.935634, Lon: 30.325935}, addresses: nevsky, stale: true},
+			},
+			want: resp.BatchResponse{Results: []*resp.BatchItem{
+				{Addresses: snezhnaya},
+				{Error: "lat: cannot be empty"},
+				{Addresses: nevsky, Stale: true},
+			}},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "item errors do not fail the batch",
+			body: `[{"lat":"55.8481373","lng":"37.6414907"},{"lat":59.935634,"lng":30.325935},null]`,
+			calls: []call{
+				{point: geo.Point{Lat: 55.8481373, Lon: 37.6414907}, err: geo.ErrUpstreamTimeout},
+				{point: geo.Point{Lat: 59.935634, Lon: 30.325935}, err: geo.ErrInternal},
+			},
+			want: resp.BatchResponse{Results: []*resp.BatchItem{
+				{Error: geo.ErrUpstreamTimeout.Error()},
+				{Error: geo.ErrInternal.Error()},
+				{Error: "lat: cannot be empty; lng: cannot be empty"},
+			}},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "empty batch",
+			body:       `[]`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too many items",
+			body:       `[{"lat":"1","lng":"1"},{"lat":"2","lng":"2"},{"lat":"3","lng":"3"},{"lat":"4","lng":"4"}]`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "body larger than the items allowed",
+			body:       `[{"lat":"1","lng":"1","language":"` + strings.Repeat("x", 4096) + `"}]`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not an array",
+			body:       `{"lat":"55.8481373","lng":"37.6414907"}`,
+			respStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewGeo(t)
+			controller := address.New(log, requestIdKey, useCaseMock, responseManager, limits)
+			handler := http.HandlerFunc(controller.GeocodeBatch)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/address/geocode/batch", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			ctxMock := mock.MatchedBy(func(c context.Context) bool {
+				return c.Value(requestIdKey) == "1"
+			})
+			for _, c := range tt.calls {
+				useCaseMock.On("Geocode", ctxMock, c.point, geo.GeocodeOptions{}).
+					Run(func(args mock.Arguments) {
+						if c.stale {
+							geo.MarkStale(args.Get(0).(context.Context))
+						}
+					}).
+					Return(c.addresses, c.err).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.respStatus == http.StatusOK {
+				var res resp.BatchResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.want, res)
+			}
+		})
+	}
+}
+
+func TestAddressGeocodeBatchHandler_Formats(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log,
+		geojson.Encoder{}, addressExport.CSV{}, addressExport.KML{}, addressExport.GPX{})
+	limits := address.Limits{BatchParallelism: 1, BatchMaxItems: 3}
+
+	snezhnaya := []*geo.Address{
+		{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		accept          string
+		geocode         bool
+		wantContentType string
+		wantBody        string
+		respStatus      int
+	}{
+		{
+			name:            "csv",
+			query:           "?format=csv",
+			geocode:         true,
+			wantContentType: "text/csv;charset=utf-8",
+			wantBody: "item,value,postal_code,country,region,city,street,house,fias_id,kladr_id,okato,oktmo,qc_geo,timezone,lat,lon,error\n" +
+				"0,,,,,Москва,Снежная,4,,,,,,,55.8481373,37.6414907,\n" +
+				"1,,,,,,,,,,,,,,,,lat: cannot be empty\n",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:            "gpx by Accept",
+			accept:          "application/gpx+xml",
+			geocode:         true,
+			wantContentType: "application/gpx+xml;charset=utf-8",
+			respStatus:      http.StatusOK,
+		},
+		{
+			name:            "format overrides Accept",
+			query:           "?format=json",
+			accept:          "application/vnd.google-earth.kml+xml",
+			geocode:         true,
+			wantContentType: "application/json;charset=utf-8",
+			respStatus:      http.StatusOK,
+		},
+		{
+			name:       "unknown format",
+			query:      "?format=xlsx",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "format not available for batches",
+			query:      "?format=geojson",
+			geocode:    true,
+			respStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewGeo(t)
+			controller := address.New(log, requestIdKey, useCaseMock, responseManager, limits)
+			handler := http.HandlerFunc(controller.GeocodeBatch)
+
+			body := `[{"lat":"55.8481373","lng":"37.6414907"},{"lat":"","lng":"37.6"}]`
+			req, err := http.NewRequest(http.MethodPost, "/api/address/geocode/batch"+tt.query, strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			if tt.geocode {
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				useCaseMock.On("Geocode", ctxMock, geo.Point{Lat: 55.8481373, Lon: 37.6414907}, geo.GeocodeOptions{}).
+					Return(snezhnaya, nil).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.wantContentType != "" {
+				require.Equal(t, tt.wantContentType, rr.Header().Get("Content-Type"))
+			}
+			if tt.wantBody != "" {
+				require.Equal(t, tt.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
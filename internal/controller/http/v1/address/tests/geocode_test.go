@@ -8,16 +8,19 @@ import (
 	"geo/internal/controller/http/v1/address"
 	"geo/internal/infrastructure/responder"
 	resp "geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/api/validation"
 	"geo/internal/service/geo"
 	"geo/internal/service/mocks"
 	"github.com/go-chi/chi/v5/middleware"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -40,7 +43,7 @@ func TestAddressGeocodeHandler(t *testing.T) {
 		want        resp.Response
 		respStatus  int
 		useCaseMock *mocks.Geo
-		mockError   bool
+		mockError   error
 	}{
 		{
 			name: "success",
@@ -61,7 +64,29 @@ func TestAddressGeocodeHandler(t *testing.T) {
 			},
 			respStatus:  http.StatusOK,
 			useCaseMock: mocks.NewGeo(t),
-			mockError:   false,
+			mockError:   nil,
+		},
+		{
+			name: "stale",
+			req: address.GeocodeRequest{
+				Lat: "55.8481373",
+				Lng: "37.6414907",
+			},
+			want: resp.Response{
+				Addresses: []*geo.Address{
+					{
+						City:   "Москва",
+						Street: "Снежная",
+						House:  "4",
+						Lat:    "55.8481373",
+						Lon:    "37.6414907",
+					},
+				},
+				Stale: true,
+			},
+			respStatus:  http.StatusOK,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   nil,
 		},
 		{
 			name: "incorrect request",
@@ -74,7 +99,45 @@ func TestAddressGeocodeHandler(t *testing.T) {
 			},
 			respStatus:  http.StatusBadRequest,
 			useCaseMock: nil,
-			mockError:   false,
+			mockError:   nil,
+		},
+		{
+			name: "options",
+			req: address.GeocodeRequest{
+				Lat:          "55.8481373",
+				Lng:          "37.6414907",
+				RadiusMeters: 50,
+				Count:        1,
+				Language:     "en",
+			},
+			want: resp.Response{
+				Addresses: []*geo.Address{
+					{
+						City:   "Moscow",
+						Street: "Snezhnaya",
+						House:  "4",
+						Lat:    "55.8481373",
+						Lon:    "37.6414907",
+					},
+				},
+			},
+			respStatus:  http.StatusOK,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   nil,
+		},
+		{
+			name: "option not supported by provider",
+			req: address.GeocodeRequest{
+				Lat:      "55.8481373",
+				Lng:      "37.6414907",
+				Language: "de",
+			},
+			want: resp.Response{
+				Addresses: nil,
+			},
+			respStatus:  http.StatusBadRequest,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   geo.ErrUnsupportedOption,
 		},
 		{
 			name: "use case error",
@@ -87,12 +150,25 @@ func TestAddressGeocodeHandler(t *testing.T) {
 			},
 			respStatus:  http.StatusInternalServerError,
 			useCaseMock: mocks.NewGeo(t),
-			mockError:   true,
+			mockError:   geo.ErrInternal,
+		},
+		{
+			name: "upstream timeout",
+			req: address.GeocodeRequest{
+				Lat: "55.8481373",
+				Lng: "37.6414907",
+			},
+			want: resp.Response{
+				Addresses: nil,
+			},
+			respStatus:  http.StatusGatewayTimeout,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   geo.ErrUpstreamTimeout,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			controller := address.New(log, requestIdKey, tt.useCaseMock, responseManager)
+			controller := address.New(log, requestIdKey, tt.useCaseMock, responseManager, address.Limits{})
 			handler := http.HandlerFunc(controller.Geocode)
 
 			body, err := json.Marshal(tt.req)
@@ -104,12 +180,23 @@ func TestAddressGeocodeHandler(t *testing.T) {
 
 			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
 			if tt.useCaseMock != nil {
-				ctxMock := context.WithValue(ctx, requestIdKey, "1")
-				if tt.mockError {
-					tt.useCaseMock.On("Geocode", ctxMock, tt.req.Lat, tt.req.Lng).
-						Return(nil, geo.ErrInternal).Once()
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				var point geo.Point
+				point.Lat, _ = geo.ParseLatitude(string(tt.req.Lat))
+				point.Lon, _ = geo.ParseLongitude(string(tt.req.Lng))
+				opts := geo.GeocodeOptions{RadiusMeters: tt.req.RadiusMeters, Count: tt.req.Count, Language: tt.req.Language}
+				if tt.mockError != nil {
+					tt.useCaseMock.On("Geocode", ctxMock, point, opts).
+						Return(nil, tt.mockError).Once()
 				} else {
-					tt.useCaseMock.On("Geocode", ctxMock, tt.req.Lat, tt.req.Lng).
+					tt.useCaseMock.On("Geocode", ctxMock, point, opts).
+						Run(func(args mock.Arguments) {
+							if tt.want.Stale {
+								geo.MarkStale(args.Get(0).(context.Context))
+							}
+						}).
 						Return(tt.want.Addresses, nil).Once()
 				}
 			}
@@ -122,6 +209,9 @@ func TestAddressGeocodeHandler(t *testing.T) {
 				var res resp.Response
 				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
 				require.Equal(t, tt.want, res)
+				if tt.want.Stale {
+					require.Equal(t, resp.StaleWarning, rr.Header().Get("Warning"))
+				}
 			}
 			if tt.useCaseMock != nil {
 				tt.useCaseMock.AssertExpectations(t)
@@ -129,3 +219,114 @@ func TestAddressGeocodeHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAddressGeocodeHandler_Coordinates(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+
+	tests := []struct {
+		name       string
+		body       string
+		point      *geo.Point
+		opts       geo.GeocodeOptions
+		wantFields map[string]string
+		respStatus int
+	}{
+		{
+			name:       "numbers",
+			body:       `{"lat": 55.8481373, "lng": 37.6414907}`,
+			point:      &geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "negative strings",
+			body:       `{"lat": "-33.8688", "lng": "-151.2093"}`,
+			point:      &geo.Point{Lat: -33.8688, Lon: -151.2093},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "out of range",
+			body: `{"lat": 91, "lng": "-180.5"}`,
+			wantFields: map[string]string{
+				"lat": geo.ErrLatitudeRange.Error(),
+				"lng": geo.ErrLongitudeRange.Error(),
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not a number",
+			body: `{"lat": "NaN", "lng": "37.6\", \"x\": 1"}`,
+			wantFields: map[string]string{
+				"lat": geo.ErrNotANumber.Error(),
+				"lng": geo.ErrNotANumber.Error(),
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "options",
+			body:       `{"lat": 55.8481373, "lng": 37.6414907, "radius_meters": 1000, "count": 20, "language": "ru"}`,
+			point:      &geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:       geo.GeocodeOptions{RadiusMeters: 1000, Count: 20, Language: "ru"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "options out of range",
+			body: `{"lat": 55.8481373, "lng": 37.6414907, "radius_meters": 1001, "count": -1, "language": "русский"}`,
+			wantFields: map[string]string{
+				"radius_meters": geo.ErrRadiusRange.Error(),
+				"count":         geo.ErrCountRange.Error(),
+				"language":      geo.ErrLanguage.Error(),
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name: "missing and wrong type",
+			body: `{"lng": true}`,
+			wantFields: map[string]string{
+				"lat": geo.ErrEmptyCoordinate.Error(),
+				"lng": geo.ErrNotANumber.Error(),
+			},
+			respStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewGeo(t)
+			controller := address.New(log, requestIdKey, useCaseMock, responseManager, address.Limits{})
+			handler := http.HandlerFunc(controller.Geocode)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/address/geocode", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			if tt.point != nil {
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				useCaseMock.On("Geocode", ctxMock, *tt.point, tt.opts).Return(nil, nil).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.wantFields != nil {
+				var res struct {
+					Data validation.Error `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.wantFields, res.Data.Fields)
+			}
+		})
+	}
+}
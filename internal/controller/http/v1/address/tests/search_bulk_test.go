@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"geo/internal/app"
+	"geo/internal/controller/http/v1/address"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/service/geo"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddressSearchBulkHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+	limits := address.Limits{BatchParallelism: 2, BulkColumn: "address", BulkMaxRows: 4}
+
+	snezhnaya := []*geo.Address{
+		{City: "Москва", Street: "Снежная", House: "", Lat: "55.852405", Lon: "37.646947"},
+		{City: "Москва", Street: "Снежная", House: "1", Lat: "55.849384", Lon: "37.64015"},
+	}
+	nevsky := []*geo.Address{
+		{City: "Санкт-Петербург", Street: "Невский", House: "28", Lat: "59.935634", Lon: "30.325935"},
+	}
+	input := "id,address\n" +
+		"1,\"г Москва, ул Снежная\"\n" +
+		"2,\n" +
+		"3,\"Санкт-Петербург, Невский 28\"\n" +
+		"4,Владивосток\n" +
+		"5,Тверь\n"
+
+	type call struct {
+		query     string
+		addresses []*geo.Address
+		err       error
+	}
+	calls := []call{
+		{query: "г Москва, ул Снежная", addresses: snezhnaya},
+		{query: "Санкт-Петербург, Невский 28", addresses: nevsky},
+		{query: "Владивосток", err: geo.ErrUpstreamTimeout},
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		accept      string
+		multipart   bool
+		body        string
+		calls       []call
+		want        string
+		contentType string
+		respStatus  int
+	}{
+		{
+			name:  "csv",
+			url:   "/api/address/search/bulk",
+			body:  input,
+			calls: calls,
+			want: "row,city,street,house,lat,lon,matches,error\n" +
+				"2,Москва,Снежная,,55.852405,37.646947,2,\n" +
+				"3,,,,,,0,query cannot be empty\n" +
+				"4,Санкт-Петербург,Невский,28,59.935634,30.325935,1,\n" +
+				"5,,,,,,0,upstream timeout\n" +
+				"6,,,,,,0,file cannot contain more than 4 rows\n",
+			contentType: "text/csv;charset=utf-8",
+			respStatus:  http.StatusOK,
+		},
+		{
+			name:   "ndjson from multipart upload",
+			url:    "/api/address/search/bulk?column=ADDRESS",
+			accept: "application/x-ndjson",
+			body:   "address\n\"г Москва, ул Снежная\"\n",
+			calls:  calls[:1],
+			want: `{"row":2,"city":"Москва","street":"Снежная","house":"","lat":"55.852405",` +
+				`"lon":"37.646947","matches":2}` + "\n",
+			multipart:   true,
+			contentType: "application/x-ndjson",
+			respStatus:  http.StatusOK,
+		},
+		{
+			name:       "unknown column",
+			url:        "/api/address/search/bulk?column=query",
+			body:       input,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown format",
+			url:        "/api/address/search/bulk?format=xlsx",
+			body:       input,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty file",
+			url:        "/api/address/search/bulk",
+			body:       "",
+			respStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewGeo(t)
+			controller := address.New(log, requestIdKey, useCaseMock, responseManager, limits)
+			handler := http.HandlerFunc(controller.SearchBulk)
+
+			var body io.Reader = strings.NewReader(tt.body)
+			contentType := "text/csv"
+			if tt.multipart {
+				buf := &bytes.Buffer{}
+				mw := multipart.NewWriter(buf)
+				fw, err := mw.CreateFormFile("file", "addresses.csv")
+				require.NoError(t, err)
+				_, err = fw.Write([]byte(tt.body))
+				require.NoError(t, err)
+				require.NoError(t, mw.Close())
+				body, contentType = buf, mw.FormDataContentType()
+			}
+			req, err := http.NewRequest(http.MethodPost, tt.url, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			ctxMock := mock.MatchedBy(func(c context.Context) bool {
+				return c.Value(requestIdKey) == "1"
+			})
+			for _, c := range tt.calls {
+				useCaseMock.On("Search", ctxMock, c.query, geo.SearchOptions{}).Return(c.addresses, c.err).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.respStatus == http.StatusOK {
+				require.Equal(t, tt.contentType, rr.Header().Get("Content-Type"))
+				require.Equal(t, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAddressSearchBulkHandler_Deadlines(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	responseManager := responder.NewResponder(godecoder.NewDecoder(), log)
+	const timeout = 300 * time.Millisecond
+	limits := address.Limits{BatchParallelism: 2, BulkColumn: "address", ReadTimeout: timeout, WriteTimeout: timeout}
+
+	tests := []struct {
+		name  string
+		rows  int
+		stall bool
+		want  string
+	}{
+		{
+			name: "upload longer than the server timeouts",
+			rows: 5,
+			want: "row,city,street,house,lat,lon,matches,error\n" +
+				"2,Тверь,,,,,1,\n3,Тверь,,,,,1,\n4,Тверь,,,,,1,\n5,Тверь,,,,,1,\n6,Тверь,,,,,1,\n",
+		},
+		{
+			name:  "stalled upload",
+			rows:  1,
+			stall: true,
+			want:  "row,city,street,house,lat,lon,matches,error\n2,Тверь,,,,,1,\n3,,,,,,0,",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewGeo(t)
+			useCaseMock.On("Search", mock.Anything, "Тверь", geo.SearchOptions{}).
+				Return([]*geo.Address{{City: "Тверь"}}, nil).Times(tt.rows)
+			controller := address.New(log, app.RequestIdKey, useCaseMock, responseManager, limits)
+			srv := httptest.NewUnstartedServer(middleware.RequestID(http.HandlerFunc(controller.SearchBulk)))
+			srv.Config.ReadTimeout, srv.Config.WriteTimeout = timeout, timeout
+			srv.Start()
+			t.Cleanup(srv.Close)
+
+			// every row arrives after half the timeout, the whole upload takes longer than it
+			pr, pw := io.Pipe()
+			go func() {
+				_, _ = io.WriteString(pw, "address\n")
+				for i := 0; i < tt.rows; i++ {
+					time.Sleep(timeout / 2)
+					_, _ = io.WriteString(pw, "Тверь\n")
+				}
+				if tt.stall {
+					time.Sleep(3 * timeout)
+				}
+				_ = pw.Close()
+			}()
+			req, err := http.NewRequest(http.MethodPost, srv.URL, pr)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "text/csv")
+
+			res, err := srv.Client().Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			got, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.True(t, strings.HasPrefix(string(got), tt.want), "got %q", got)
+			if tt.stall {
+				require.Contains(t, string(got), "timeout")
+			}
+		})
+	}
+}
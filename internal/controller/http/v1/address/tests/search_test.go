@@ -8,11 +8,13 @@ import (
 	"geo/internal/controller/http/v1/address"
 	"geo/internal/infrastructure/responder"
 	resp "geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/api/geojson"
 	"geo/internal/service/geo"
 	"geo/internal/service/mocks"
 	"github.com/go-chi/chi/v5/middleware"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"log/slog"
 	"net/http"
@@ -40,7 +42,7 @@ func TestAddressSearchHandler(t *testing.T) {
 		want        resp.Response
 		respStatus  int
 		useCaseMock *mocks.Geo
-		mockError   bool
+		mockError   error
 	}{
 		{
 			name: "success",
@@ -121,7 +123,26 @@ func TestAddressSearchHandler(t *testing.T) {
 			},
 			respStatus:  http.StatusOK,
 			useCaseMock: mocks.NewGeo(t),
-			mockError:   false,
+			mockError:   nil,
+		},
+		{
+			name: "stale",
+			req:  address.SearchRequest{Query: "г Москва, ул Снежная"},
+			want: resp.Response{
+				Addresses: []*geo.Address{
+					{
+						City:   "Москва",
+						Street: "Снежная",
+						House:  "",
+						Lat:    "55.852405",
+						Lon:    "37.646947",
+					},
+				},
+				Stale: true,
+			},
+			respStatus:  http.StatusOK,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   nil,
 		},
 		{
 			name: "incorrect request",
@@ -131,7 +152,61 @@ func TestAddressSearchHandler(t *testing.T) {
 			},
 			respStatus:  http.StatusBadRequest,
 			useCaseMock: nil,
-			mockError:   false,
+			mockError:   nil,
+		},
+		{
+			name: "filters",
+			req: address.SearchRequest{
+				Query:          "Снежная",
+				Locations:      []geo.Location{{City: "Москва"}},
+				LocationsBoost: []geo.BoostLocation{{KladrID: "7700000000000"}},
+				FromBound:      geo.GranularityStreet,
+				ToBound:        geo.GranularityStreet,
+				Count:          1,
+			},
+			want: resp.Response{
+				Addresses: []*geo.Address{
+					{
+						City:   "Москва",
+						Street: "Снежная",
+						Lat:    "55.852405",
+						Lon:    "37.646947",
+					},
+				},
+			},
+			respStatus:  http.StatusOK,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   nil,
+		},
+		{
+			name: "invalid filters",
+			req: address.SearchRequest{
+				Query:     "Снежная",
+				Locations: []geo.Location{{}},
+				FromBound: geo.GranularityStreet,
+				ToBound:   geo.GranularityCity,
+				Count:     21,
+			},
+			want: resp.Response{
+				Addresses: nil,
+			},
+			respStatus:  http.StatusBadRequest,
+			useCaseMock: nil,
+			mockError:   nil,
+		},
+		{
+			name: "filter not supported by provider",
+			req: address.SearchRequest{
+				Query:     "Москва",
+				FromBound: geo.GranularityCity,
+				ToBound:   geo.GranularityCity,
+			},
+			want: resp.Response{
+				Addresses: nil,
+			},
+			respStatus:  http.StatusBadRequest,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   geo.ErrUnsupportedOption,
 		},
 		{
 			name: "use case error",
@@ -141,12 +216,22 @@ func TestAddressSearchHandler(t *testing.T) {
 			},
 			respStatus:  http.StatusInternalServerError,
 			useCaseMock: mocks.NewGeo(t),
-			mockError:   true,
+			mockError:   geo.ErrInternal,
+		},
+		{
+			name: "upstream timeout",
+			req:  address.SearchRequest{Query: "г Москва, ул Снежная"},
+			want: resp.Response{
+				Addresses: nil,
+			},
+			respStatus:  http.StatusGatewayTimeout,
+			useCaseMock: mocks.NewGeo(t),
+			mockError:   geo.ErrUpstreamTimeout,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			controller := address.New(log, requestIdKey, tt.useCaseMock, responseManager)
+			controller := address.New(log, requestIdKey, tt.useCaseMock, responseManager, address.Limits{})
 			handler := http.HandlerFunc(controller.Search)
 
 			body, err := json.Marshal(tt.req)
@@ -158,12 +243,26 @@ func TestAddressSearchHandler(t *testing.T) {
 
 			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
 			if tt.useCaseMock != nil {
-				ctxMock := context.WithValue(ctx, requestIdKey, "1")
-				if tt.mockError {
-					tt.useCaseMock.On("Search", ctxMock, tt.req.Query).
-						Return(nil, geo.ErrInternal).Once()
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				opts := geo.SearchOptions{
+					Locations:      tt.req.Locations,
+					LocationsBoost: tt.req.LocationsBoost,
+					FromBound:      tt.req.FromBound,
+					ToBound:        tt.req.ToBound,
+					Count:          tt.req.Count,
+				}
+				if tt.mockError != nil {
+					tt.useCaseMock.On("Search", ctxMock, tt.req.Query, opts).
+						Return(nil, tt.mockError).Once()
 				} else {
-					tt.useCaseMock.On("Search", ctxMock, tt.req.Query).
+					tt.useCaseMock.On("Search", ctxMock, tt.req.Query, opts).
+						Run(func(args mock.Arguments) {
+							if tt.want.Stale {
+								geo.MarkStale(args.Get(0).(context.Context))
+							}
+						}).
 						Return(tt.want.Addresses, nil).Once()
 				}
 			}
@@ -176,6 +275,10 @@ func TestAddressSearchHandler(t *testing.T) {
 				var res resp.Response
 				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
 				require.Equal(t, tt.want.Addresses, res.Addresses)
+				require.Equal(t, tt.want.Stale, res.Stale)
+				if tt.want.Stale {
+					require.Equal(t, resp.StaleWarning, rr.Header().Get("Warning"))
+				}
 			}
 
 			if tt.useCaseMock != nil {
@@ -184,3 +287,92 @@ func TestAddressSearchHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAddressSearchHandler_GeoJSON(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log, geojson.Encoder{})
+
+	addresses := []*geo.Address{
+		{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+		{City: "Москва", Street: "Снежная"},
+	}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		wantGeoJSON     bool
+	}{
+		{
+			name:            "geojson",
+			accept:          "application/geo+json",
+			wantContentType: "application/geo+json;charset=utf-8",
+			wantGeoJSON:     true,
+		},
+		{
+			name:            "json preferred",
+			accept:          "application/json, application/geo+json;q=0.9",
+			wantContentType: "application/json;charset=utf-8",
+		},
+		{
+			name:            "any",
+			accept:          "*/*",
+			wantContentType: "application/json;charset=utf-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewGeo(t)
+			controller := address.New(log, requestIdKey, useCaseMock, responseManager, address.Limits{})
+			handler := http.HandlerFunc(controller.Search)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/address/search", bytes.NewReader([]byte(`{"query": "Снежная"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", tt.accept)
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			ctxMock := mock.MatchedBy(func(c context.Context) bool {
+				return c.Value(requestIdKey) == "1"
+			})
+			useCaseMock.On("Search", ctxMock, "Снежная", geo.SearchOptions{}).Return(addresses, nil).Once()
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, tt.wantContentType, rr.Header().Get("Content-Type"))
+			require.Equal(t, "Accept", rr.Header().Get("Vary"))
+			if !tt.wantGeoJSON {
+				var res resp.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, addresses, res.Addresses)
+				return
+			}
+			require.JSONEq(t, `{
+				"type": "FeatureCollection",
+				"features": [
+					{
+						"type": "Feature",
+						"geometry": {"type": "Point", "coordinates": [37.6414907, 55.8481373]},
+						"properties": {"city": "Москва", "street": "Снежная", "house": "4", "lat": "55.8481373", "lon": "37.6414907"}
+					},
+					{
+						"type": "Feature",
+						"geometry": null,
+						"properties": {"city": "Москва", "street": "Снежная", "house": "", "lat": "", "lon": ""}
+					}
+				]
+			}`, rr.Body.String())
+		})
+	}
+}
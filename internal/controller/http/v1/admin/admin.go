@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"fmt"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/logger/sl"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+	"net/http"
+)
+
+type Administrator interface {
+	PurgeCache(http.ResponseWriter, *http.Request)
+}
+
+// Purger is a cache whose entries can be dropped on demand.
+type Purger interface {
+	Name() string
+	Purge() error
+}
+
+type Admin struct {
+	log       *slog.Logger
+	responder responder.Responder
+	purgers   []Purger
+}
+
+func New(log *slog.Logger, responder responder.Responder, purgers ...Purger) *Admin {
+	return &Admin{log: log, responder: responder, purgers: purgers}
+}
+
+// @Summary	Drop every cached provider response
+// @Tags		admin
+// @Produce	json
+// @Success	200	{object}	responder.Response	"names of the purged caches"
+//
+// @Failure	401	"Unauthorized: Token missing or invalid"
+// @Header		401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure	403	"Forbidden: the token lacks the admin role"
+// @Failure	500	{object}	responder.Response
+// @Security	ApiKeyAuth
+// @Router		/admin/cache [delete]
+func (a *Admin) PurgeCache(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.admin.PurgeCache"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	purged := make([]string, 0, len(a.purgers))
+	for _, p := range a.purgers {
+		if err := p.Purge(); err != nil {
+			log.Error("failed to purge cache", slog.String("cache", p.Name()), sl.Err(err))
+			a.responder.ErrorInternal(w, fmt.Errorf("failed to purge %s", p.Name()))
+			return
+		}
+		purged = append(purged, p.Name())
+	}
+
+	log.Info("caches purged", slog.Any("caches", purged))
+	a.responder.OutputJSON(w, responder.Response{
+		Success: true,
+		Message: "cache purged",
+		Data:    purged,
+	})
+}
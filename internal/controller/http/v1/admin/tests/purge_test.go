@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"geo/internal/controller/http/v1/admin"
+	"geo/internal/infrastructure/responder"
+	"github.com/go-chi/chi/v5/middleware"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type purger struct {
+	name   string
+	err    error
+	purged bool
+}
+
+func (p *purger) Name() string {
+	return p.name
+}
+
+func (p *purger) Purge() error {
+	if p.err != nil {
+		return p.err
+	}
+	p.purged = true
+	return nil
+}
+
+func TestPurgeCacheHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+
+	tests := []struct {
+		name       string
+		purgers    []*purger
+		want       []string
+		respStatus int
+	}{
+		{
+			name:       "no caches",
+			want:       []string{},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "success",
+			purgers: []*purger{
+				{name: "cache"},
+				{name: "persistent_cache"},
+			},
+			want:       []string{"cache", "persistent_cache"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "storage failure",
+			purgers: []*purger{
+				{name: "persistent_cache", err: errors.New("disk full")},
+			},
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purgers := make([]admin.Purger, len(tt.purgers))
+			for i, p := range tt.purgers {
+				purgers[i] = p
+			}
+			controller := admin.New(log, responseManager, purgers...)
+			handler := http.HandlerFunc(controller.PurgeCache)
+
+			req, err := http.NewRequest(http.MethodDelete, "/admin/cache", nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.respStatus != http.StatusOK {
+				return
+			}
+			var res struct {
+				Success bool     `json:"success"`
+				Data    []string `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.True(t, res.Success)
+			require.ElementsMatch(t, tt.want, res.Data)
+			for _, p := range tt.purgers {
+				require.True(t, p.purged)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ type Auther interface {
 	Login(http.ResponseWriter, *http.Request)
 	Logout(http.ResponseWriter, *http.Request)
 	Register(http.ResponseWriter, *http.Request)
+	Refresh(http.ResponseWriter, *http.Request)
 }
 
 type Auth struct {
@@ -49,10 +50,11 @@ func (e *LoginResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-func SendToken(token string) render.Renderer {
+func SendToken(tokens *auth.Tokens) render.Renderer {
 	return &LoginResponse{
-		AccessToken: token,
-		TokenType:   "Bearer",
+		AccessToken:  tokens.Access,
+		RefreshToken: tokens.Refresh,
+		TokenType:    "Bearer",
 	}
 }
 
@@ -60,7 +62,7 @@ func SendToken(token string) render.Renderer {
 //
 // @Tags			auth
 //
-// @Description	Get the Bearer token using your Login and Password. If the token's lifetime has expired, you need to log in again. If you don't have an account, see /register endpoint
+// @Description	Get the Bearer token using your Login and Password. When the token's lifetime has expired, get a new one with the refresh token at /token/refresh. If you don't have an account, see /register endpoint
 // @Param			credentials	body		request.CredentialsRequest	true	"your credentials"
 // @Success		200			{object}	LoginResponse
 // @Failure		400			{object}	response.ErrResponse	"invalid login/password format"
@@ -82,7 +84,7 @@ func (a *Auth) Login(w http.ResponseWriter, r *http.Request) {
 	log.Info("request received", slog.Any("data", data))
 
 	ctx := context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context()))
-	token, err := a.uc.Login(ctx, data.Login, data.Password)
+	tokens, err := a.uc.Login(ctx, data.Login, data.Password)
 	if errors.Is(err, auth.ErrInvalidCredentials) {
 		log.Error("error when logging in", sl.Err(err))
 		a.responder.ErrorUnauthorized(w, err)
@@ -94,9 +96,50 @@ func (a *Auth) Login(w http.ResponseWriter, r *http.Request) {
 		//render.Render(w, r, response.ErrInternal())
 		return
 	}
-	log.Info("user logged in successfully", slog.Any("response", SendToken(token)))
+	log.Info("user logged in successfully")
 	//render.Render(w, r, SendToken(token))
-	a.responder.OutputJSON(w, SendToken(token))
+	a.responder.OutputJSON(w, SendToken(tokens))
+}
+
+// @Summary		Renew the Bearer token
+//
+// @Tags			auth
+//
+// @Description	Exchange the refresh token for a new Bearer token and a new refresh token, the old refresh token cannot be used again.
+// @Description	Reusing a refresh token revokes every token issued since the login it comes from.
+// @Param			token	body		request.RefreshRequest	true	"refresh token"
+// @Success		200		{object}	LoginResponse
+// @Failure		400		{object}	response.ErrResponse	"invalid request format"
+// @Failure		401		{object}	response.ErrResponse	"Unknown, expired, revoked or reused refresh token"
+// @Failure		500		{object}	response.ErrResponse
+// @Router			/token/refresh [post]
+func (a *Auth) Refresh(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.auth.Refresh"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	data := &request.RefreshRequest{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		a.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received")
+
+	ctx := context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context()))
+	tokens, err := a.uc.Refresh(ctx, data.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+		log.Error("error when refreshing token", sl.Err(err))
+		a.responder.ErrorUnauthorized(w, err)
+		return
+	} else if err != nil {
+		log.Error("error when refreshing token", sl.Err(err))
+		a.responder.ErrorInternal(w, err)
+		return
+	}
+	log.Info("token refreshed successfully")
+	a.responder.OutputJSON(w, SendToken(tokens))
 }
 
 // @Summary		Log out from the server
@@ -25,7 +25,7 @@ func TestLogin(t *testing.T) {
 	log := slog.New(
 		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
 	)
-	token := "123"
+	tokens := &service.Tokens{Access: "123", Refresh: "456"}
 	requestIdKey := app.RequestIdKey
 	decoder := godecoder.NewDecoder(jsoniter.Config{
 		EscapeHTML:             true,
@@ -50,8 +50,9 @@ func TestLogin(t *testing.T) {
 				Password: "password",
 			},
 			wantResp: auth.LoginResponse{
-				AccessToken: token,
-				TokenType:   "Bearer",
+				AccessToken:  tokens.Access,
+				RefreshToken: tokens.Refresh,
+				TokenType:    "Bearer",
 			},
 			respStatus:       http.StatusOK,
 			useCaseMock:      mocks.NewAuth(t),
@@ -104,7 +105,7 @@ func TestLogin(t *testing.T) {
 			ctxMock := context.WithValue(ctx, requestIdKey, "1")
 			if tt.useCaseMock != nil {
 				tt.useCaseMock.On("Login", ctxMock, tt.req.Login, tt.req.Password).
-					Return(token, tt.useCaseMockError).Once()
+					Return(tokens, tt.useCaseMockError).Once()
 			}
 
 			handler.ServeHTTP(rr, req.WithContext(ctx))
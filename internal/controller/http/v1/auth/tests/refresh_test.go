@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	"geo/internal/controller/http/v1/auth"
+	"geo/internal/infrastructure/responder"
+	service "geo/internal/service/auth"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRefresh(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+	tokens := &service.Tokens{Access: "access", Refresh: "next"}
+
+	tests := []struct {
+		name             string
+		body             string
+		refresh          string
+		useCaseMockError error
+		wantResp         auth.LoginResponse
+		respStatus       int
+	}{
+		{
+			name:       "success",
+			body:       `{"refresh_token": "old"}`,
+			refresh:    "old",
+			wantResp:   auth.LoginResponse{AccessToken: "access", RefreshToken: "next", TokenType: "Bearer"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "empty token",
+			body:       `{"refresh_token": ""}`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:             "invalid token",
+			body:             `{"refresh_token": "old"}`,
+			refresh:          "old",
+			useCaseMockError: service.ErrInvalidRefreshToken,
+			respStatus:       http.StatusUnauthorized,
+		},
+		{
+			name:             "reused token",
+			body:             `{"refresh_token": "old"}`,
+			refresh:          "old",
+			useCaseMockError: service.ErrRefreshTokenReused,
+			respStatus:       http.StatusUnauthorized,
+		},
+		{
+			name:             "internal server error",
+			body:             `{"refresh_token": "old"}`,
+			refresh:          "old",
+			useCaseMockError: service.ErrInternal,
+			respStatus:       http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewAuth(t)
+			controller := auth.New(log, requestIdKey, useCaseMock, responseManager)
+			handler := http.HandlerFunc(controller.Refresh)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/token/refresh", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			ctxMock := context.WithValue(ctx, requestIdKey, "1")
+			if tt.refresh != "" {
+				var res *service.Tokens
+				if tt.useCaseMockError == nil {
+					res = tokens
+				}
+				useCaseMock.On("Refresh", ctxMock, tt.refresh).Return(res, tt.useCaseMockError).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+			require.Equal(t, tt.respStatus, rr.Code)
+
+			var res auth.LoginResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, tt.wantResp, res)
+		})
+	}
+}
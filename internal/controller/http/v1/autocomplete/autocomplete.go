@@ -0,0 +1,267 @@
+package autocomplete
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"geo/internal/lib/api/address/addressResponse"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/geo"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/gorilla/websocket"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxMessageSize is the largest client message accepted, in bytes
+	maxMessageSize = 4096
+	writeWait      = 10 * time.Second
+	// defaultPingInterval is used when the settings leave PingInterval unset
+	defaultPingInterval = 30 * time.Second
+)
+
+type Completer interface {
+	Autocomplete(http.ResponseWriter, *http.Request)
+}
+
+type Settings struct {
+	// Debounce is the quiet period after the last keystroke before the query is searched
+	Debounce time.Duration
+	// MinLength is the shortest query searched, in characters
+	MinLength int
+	// Count is the maximum number of suggestions
+	Count int
+	// PingInterval is the period of the keepalive pings, the connection is closed
+	// when no pong arrives within two intervals; defaultPingInterval is used when unset
+	PingInterval time.Duration
+}
+
+type Autocomplete struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.Geo
+	settings     Settings
+	upgrader     websocket.Upgrader
+}
+
+func New(log *slog.Logger, requestIdKey string, uc service.Geo, settings Settings) *Autocomplete {
+	if settings.PingInterval <= 0 {
+		settings.PingInterval = defaultPingInterval
+	}
+	return &Autocomplete{log: log, requestIdKey: requestIdKey, uc: uc, settings: settings}
+}
+
+// Request is a message of the client, every keystroke sends the whole input.
+type Request struct {
+	// ID is echoed in the suggestions answering the request
+	ID    int64  `json:"id"`
+	Query string `json:"query"`
+} //@name AutocompleteRequest
+
+// @Summary		Live address autocomplete
+// @Description	Upgrades to a WebSocket authenticated by the bearer token of the handshake.
+// @Description	The client sends {"id": 1, "query": "г Москва, ул Сне"} on every keystroke, the server waits
+// @Description	for the input to settle, cancels the search of an outdated query and answers
+// @Description	the latest one with an AddressSuggestions message. The socket is closed when the token expires,
+// @Description	a session opened with an API key lasts at most the access token TTL.
+// @Tags			address
+// @Success		101	{object}	addressResponse.Suggestions	"Switching Protocols, then suggestion messages"
+// @Failure		400	"not a WebSocket handshake"
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403	"cross-origin handshake"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/address/autocomplete [get]
+func (a *Autocomplete) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.autocomplete.Autocomplete"
+	log := a.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	// the authenticator guarantees the token has an expiry, the one KeyVerifier builds
+	// for an API key lasts at most the access token TTL
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	exp, _ := claims["exp"].(time.Time)
+
+	conn, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already answered the handshake
+		log.Error("failed to upgrade connection", sl.Err(err))
+		return
+	}
+	defer conn.Close()
+	log.Info("autocomplete session started")
+
+	ctx, cancel := context.WithCancel(context.WithValue(r.Context(), a.requestIdKey, middleware.GetReqID(r.Context())))
+	defer cancel()
+	s := &session{a: a, conn: conn, log: log}
+	s.run(ctx, exp)
+	log.Info("autocomplete session ended")
+}
+
+type session struct {
+	a    *Autocomplete
+	conn *websocket.Conn
+	log  *slog.Logger
+	// writeMu serializes the writes, the connection supports a single writer
+	writeMu sync.Mutex
+}
+
+func (s *session) run(ctx context.Context, exp time.Time) {
+	pongWait := 2 * s.a.settings.PingInterval
+	s.conn.SetReadLimit(maxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	requests := make(chan Request)
+	go s.read(ctx, requests)
+
+	debounce := time.NewTimer(s.a.settings.Debounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	ping := time.NewTicker(s.a.settings.PingInterval)
+	defer ping.Stop()
+	var expired <-chan time.Time
+	if !exp.IsZero() {
+		expiry := time.NewTimer(time.Until(exp))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	var (
+		latest   Request
+		inFlight context.CancelFunc = func() {}
+		searches sync.WaitGroup
+	)
+	defer searches.Wait()
+	defer func() { inFlight() }()
+	for {
+		select {
+		case req, ok := <-requests:
+			if !ok {
+				return
+			}
+			// a newer prefix makes the search in flight useless
+			inFlight()
+			latest = req
+			debounce.Reset(s.a.settings.Debounce)
+		case <-debounce.C:
+			inFlight = s.start(ctx, &searches, latest)
+		case <-ping.C:
+			if err := s.write(websocket.PingMessage, nil); err != nil {
+				s.log.Warn("failed to ping client", sl.Err(err))
+				return
+			}
+		case <-expired:
+			s.log.Info("token expired, closing session")
+			msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token expired")
+			_ = s.write(websocket.CloseMessage, msg)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// start searches req in the background, the returned function cancels the search.
+func (s *session) start(ctx context.Context, searches *sync.WaitGroup, req Request) context.CancelFunc {
+	ctx, cancel := context.WithCancel(ctx)
+	searches.Add(1)
+	go func() {
+		defer searches.Done()
+		s.search(ctx, req)
+	}()
+	return cancel
+}
+
+// read sends the client requests to requests until the connection fails or is closed.
+func (s *session) read(ctx context.Context, requests chan<- Request) {
+	defer close(requests)
+	for {
+		_, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.log.Warn("failed to read message", sl.Err(err))
+			}
+			return
+		}
+		var req Request
+		if err := json.Unmarshal(msg, &req); err != nil {
+			s.log.Error("error decoding message", sl.Err(err))
+			if err := s.send(ctx, &addressResponse.Suggestions{Error: "invalid message: " + err.Error()}); err != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *session) search(ctx context.Context, req Request) {
+	res := &addressResponse.Suggestions{ID: req.ID, Query: req.Query, Suggestions: []*geo.Address{}}
+	if utf8.RuneCountInString(strings.TrimSpace(req.Query)) < s.a.settings.MinLength {
+		_ = s.send(ctx, res)
+		return
+	}
+
+	ctx, freshness := geo.WithFreshness(ctx)
+	addresses, err := s.a.uc.Search(ctx, req.Query, geo.SearchOptions{Count: s.a.settings.Count})
+	switch {
+	case ctx.Err() != nil || errors.Is(err, context.Canceled):
+		return
+	case err != nil:
+		s.log.Error("failed to get suggestions", slog.Int64("id", req.ID), sl.Err(err))
+		res.Error = err.Error()
+	default:
+		if addresses != nil {
+			res.Suggestions = addresses
+		}
+		res.Stale = freshness.Stale()
+	}
+	_ = s.send(ctx, res)
+}
+
+// send writes res unless ctx is done, the check is made under the write lock
+// so the answer of a cancelled search never follows the answer of a newer one.
+func (s *session) send(ctx context.Context, res *addressResponse.Suggestions) error {
+	msg, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return s.writeLocked(websocket.TextMessage, msg)
+}
+
+func (s *session) write(messageType int, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.writeLocked(messageType, data)
+}
+
+func (s *session) writeLocked(messageType int, data []byte) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(messageType, data)
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"geo/internal/app"
+	"geo/internal/controller/http/middleware/auth"
+	"geo/internal/controller/http/v1/autocomplete"
+	resp "geo/internal/lib/api/address/addressResponse"
+	"geo/internal/service/apikey"
+	"geo/internal/service/geo"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var settings = autocomplete.Settings{
+	Debounce:     50 * time.Millisecond,
+	MinLength:    3,
+	Count:        5,
+	PingInterval: time.Minute,
+}
+
+// newServer serves the autocomplete channel to a client authenticated by a token expiring at exp.
+func newServer(t *testing.T, useCase *mocks.Geo, exp time.Time) *websocket.Conn {
+	return newServerWithSettings(t, useCase, exp, settings)
+}
+
+func newServerWithSettings(t *testing.T, useCase *mocks.Geo, exp time.Time, settings autocomplete.Settings) *websocket.Conn {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	controller := autocomplete.New(log, app.RequestIdKey, useCase, settings)
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	token, _, err := ja.Encode(map[string]interface{}{"exp": exp})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.RequestIDKey, "1")
+		ctx = jwtauth.NewContext(ctx, token, nil)
+		controller.Autocomplete(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	return conn
+}
+
+var ctxMock = mock.MatchedBy(func(c context.Context) bool {
+	return c.Value(app.RequestIdKey) == "1"
+})
+
+func TestAutocomplete_Debounce(t *testing.T) {
+	useCase := mocks.NewGeo(t)
+	conn := newServer(t, useCase, time.Now().Add(time.Hour))
+
+	snezhnaya := []*geo.Address{{City: "Москва", Street: "Снежная", Lat: "55.85", Lon: "37.64"}}
+	useCase.On("Search", ctxMock, "Снежн", geo.SearchOptions{Count: 5}).Return(snezhnaya, nil).Once()
+
+	// keystrokes faster than the debounce are searched once, for the last prefix
+	for i, q := range []string{"Сн", "Сне", "Снеж", "Снежн"} {
+		require.NoError(t, conn.WriteJSON(autocomplete.Request{ID: int64(i + 1), Query: q}))
+	}
+
+	var got resp.Suggestions
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Equal(t, resp.Suggestions{ID: 4, Query: "Снежн", Suggestions: snezhnaya}, got)
+}
+
+func TestAutocomplete_CancelStaleSearch(t *testing.T) {
+	useCase := mocks.NewGeo(t)
+	conn := newServer(t, useCase, time.Now().Add(time.Hour))
+
+	cancelled := make(chan struct{})
+	useCase.On("Search", ctxMock, "Снеж", geo.SearchOptions{Count: 5}).
+		Return(func(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		}).Once()
+	useCase.On("Search", ctxMock, "Снежная", geo.SearchOptions{Count: 5}).Return(nil, geo.ErrUpstreamTimeout).Once()
+
+	require.NoError(t, conn.WriteJSON(autocomplete.Request{ID: 1, Query: "Снеж"}))
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, conn.WriteJSON(autocomplete.Request{ID: 2, Query: "Снежная"}))
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the search of the outdated prefix is not cancelled")
+	}
+	var got resp.Suggestions
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Equal(t, resp.Suggestions{ID: 2, Query: "Снежная", Suggestions: []*geo.Address{}, Error: geo.ErrUpstreamTimeout.Error()}, got)
+}
+
+func TestAutocomplete_ShortQueryAndInvalidMessage(t *testing.T) {
+	useCase := mocks.NewGeo(t)
+	conn := newServer(t, useCase, time.Now().Add(time.Hour))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id": "x"`)))
+	var got resp.Suggestions
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Contains(t, got.Error, "invalid message")
+
+	// short queries are answered without the provider
+	require.NoError(t, conn.WriteJSON(autocomplete.Request{ID: 7, Query: " Сн "}))
+	got = resp.Suggestions{}
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Equal(t, resp.Suggestions{ID: 7, Query: " Сн ", Suggestions: []*geo.Address{}}, got)
+}
+
+func TestAutocomplete_DefaultPingInterval(t *testing.T) {
+	s := settings
+	s.PingInterval = 0
+	conn := newServerWithSettings(t, mocks.NewGeo(t), time.Now().Add(time.Hour), s)
+
+	require.NoError(t, conn.WriteJSON(autocomplete.Request{ID: 1, Query: "Сн"}))
+	var got resp.Suggestions
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Equal(t, resp.Suggestions{ID: 1, Query: "Сн", Suggestions: []*geo.Address{}}, got)
+}
+
+func TestAutocomplete_TokenExpiry(t *testing.T) {
+	useCase := mocks.NewGeo(t)
+	conn := newServer(t, useCase, time.Now().Add(1500*time.Millisecond))
+
+	_, _, err := conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+}
+
+func TestAutocomplete_APIKeySessionExpiry(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	controller := autocomplete.New(log, app.RequestIdKey, mocks.NewGeo(t), settings)
+	keysMock := mocks.NewAPIKeys(t)
+	keysMock.On("Authenticate", mock.Anything, "geo_key").
+		Return(&apikey.Principal{KeyID: "k1", Owner: "bob", Roles: []string{"user"}}, nil).Once()
+	// a key that never expires still gets a session bounded by the ttl
+	verifier := auth.NewKeyVerifier(log, app.RequestIdKey, keysMock, 1500*time.Millisecond)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), middleware.RequestIDKey, "1")
+		verifier.Middleware()(http.HandlerFunc(controller.Autocomplete)).ServeHTTP(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"),
+		http.Header{"X-API-Key": []string{"geo_key"}})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+}
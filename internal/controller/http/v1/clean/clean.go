@@ -0,0 +1,93 @@
+package clean
+
+import (
+	"context"
+	"errors"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/validation"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/clean"
+	"geo/internal/service/geo"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+)
+
+type Cleaner interface {
+	Clean(http.ResponseWriter, *http.Request)
+}
+
+type Clean struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.Clean
+	responder    responder.Responder
+}
+
+func New(log *slog.Logger, requestIdKey string, uc service.Clean, responder responder.Responder) *Clean {
+	return &Clean{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder}
+}
+
+type Request struct {
+	Address string `json:"address" example:"мск сухонская 11 89"`
+} //@name CleanRequest
+
+func (cr *Request) Bind(r *http.Request) error {
+	var vErr validation.Error
+	if err := clean.ValidateSource(cr.Address); err != nil {
+		vErr.Add("address", err)
+	}
+	return vErr.Err()
+}
+
+// @Summary		Standardized address
+// @Description	Parses a free-form address into a standardized one with its components, coordinates and quality codes.
+// @Tags			address
+// @Param			address	body		Request	true	"free-form address"
+// @Success		200		{object}	clean.Address
+// @Failure		400		{object}	responder.Response{data=validation.Error}	"empty or too long address"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		500		{object}	response.ErrResponse
+// @Failure		504		{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/address/clean [post]
+func (c *Clean) Clean(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.clean.Clean"
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	data := &Request{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		c.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Any("data", data))
+
+	ctx := context.WithValue(r.Context(), c.requestIdKey, middleware.GetReqID(r.Context()))
+	address, err := c.uc.Clean(ctx, data.Address)
+	if errors.Is(err, clean.ErrEmptySource) || errors.Is(err, clean.ErrSourceTooLong) {
+		log.Error("invalid clean request", sl.Err(err))
+		c.responder.ErrorBadRequest(w, err)
+		return
+	} else if errors.Is(err, geo.ErrUpstreamTimeout) {
+		log.Error("clean provider timed out", sl.Err(err))
+		c.responder.ErrorGatewayTimeout(w, err)
+		return
+	} else if err != nil {
+		log.Error("failed to clean address", sl.Err(err))
+		c.responder.ErrorInternal(w, err)
+		return
+	}
+
+	log.Info("request executed", slog.Any("response", address))
+	c.responder.OutputJSON(w, address)
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	cleanController "geo/internal/controller/http/v1/clean"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/validation"
+	"geo/internal/service/clean"
+	"geo/internal/service/geo"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCleanHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+
+	cleaned := &clean.Address{
+		Source: "мск сухонская 11 89",
+		Result: "г Москва, ул Сухонская, д 11, кв 89",
+		Components: clean.Components{
+			PostalCode: "127642", City: "Москва", Street: "Сухонская", House: "11", Flat: "89",
+		},
+		Lat: "55.8782557", Lon: "37.65372",
+		Qc: "0", QcGeo: "0", QcComplete: "0", QcHouse: "2",
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		source     string
+		mockResult *clean.Address
+		mockError  error
+		wantFields map[string]string
+		respStatus int
+	}{
+		{
+			name:       "success",
+			body:       `{"address": "мск сухонская 11 89"}`,
+			source:     "мск сухонская 11 89",
+			mockResult: cleaned,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "empty address",
+			body:       `{"address": ""}`,
+			wantFields: map[string]string{"address": clean.ErrEmptySource.Error()},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too long address",
+			body:       `{"address": "` + strings.Repeat("д", clean.MaxSourceLength+1) + `"}`,
+			wantFields: map[string]string{"address": clean.ErrSourceTooLong.Error()},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "upstream timeout",
+			body:       `{"address": "мск сухонская 11 89"}`,
+			source:     "мск сухонская 11 89",
+			mockError:  geo.ErrUpstreamTimeout,
+			respStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "use case error",
+			body:       `{"address": "мск сухонская 11 89"}`,
+			source:     "мск сухонская 11 89",
+			mockError:  geo.ErrInternal,
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewClean(t)
+			controller := cleanController.New(log, requestIdKey, useCaseMock, responseManager)
+			handler := http.HandlerFunc(controller.Clean)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/address/clean", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			if tt.source != "" {
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				useCaseMock.On("Clean", ctxMock, tt.source).Return(tt.mockResult, tt.mockError).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.mockResult != nil {
+				var res clean.Address
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, *tt.mockResult, res)
+			}
+			if tt.wantFields != nil {
+				var res struct {
+					Data validation.Error `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.wantFields, res.Data.Fields)
+			}
+		})
+	}
+}
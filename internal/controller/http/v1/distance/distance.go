@@ -0,0 +1,225 @@
+package distance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/coordinate"
+	"geo/internal/lib/api/distance/distanceResponse"
+	"geo/internal/lib/api/validation"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/distance"
+	"geo/internal/service/geo"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type Distancer interface {
+	Distance(http.ResponseWriter, *http.Request)
+	Matrix(http.ResponseWriter, *http.Request)
+}
+
+type Distance struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.Distance
+	responder    responder.Responder
+}
+
+func New(log *slog.Logger, requestIdKey string, uc service.Distance, responder responder.Responder) *Distance {
+	return &Distance{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder}
+}
+
+// PlaceRequest is a place given either by coordinates or by a free-text address.
+type PlaceRequest struct {
+	Lat     coordinate.Coordinate `json:"lat,omitempty" swaggertype:"number" example:"55.8481373"`
+	Lng     coordinate.Coordinate `json:"lng,omitempty" swaggertype:"number" example:"37.6414907"`
+	Address string                `json:"address,omitempty" example:"г Москва, ул Снежная, д 4"`
+} //@name DistancePlace
+
+// place checks the request, problems are added to vErr under the field prefix.
+func (pr *PlaceRequest) place(field string, vErr *validation.Error) distance.Place {
+	hasPoint := pr.Lat != "" || pr.Lng != ""
+	hasAddress := strings.TrimSpace(pr.Address) != ""
+	switch {
+	case hasPoint && hasAddress:
+		vErr.Add(field, errors.New("must have either coordinates or an address, not both"))
+	case hasAddress:
+		return distance.Place{Address: pr.Address}
+	case !hasPoint:
+		vErr.Add(field, errors.New("must have coordinates or an address"))
+	default:
+		lat, err := geo.ParseLatitude(string(pr.Lat))
+		if err != nil {
+			vErr.Add(field+".lat", err)
+		}
+		lon, err := geo.ParseLongitude(string(pr.Lng))
+		if err != nil {
+			vErr.Add(field+".lng", err)
+		}
+		return distance.Place{Point: &geo.Point{Lat: lat, Lon: lon}}
+	}
+	return distance.Place{}
+}
+
+// bindMethod checks the method and defaults it to haversine.
+func bindMethod(method *distance.Method, vErr *validation.Error) {
+	switch *method {
+	case "":
+		*method = distance.MethodHaversine
+	case distance.MethodHaversine, distance.MethodVincenty:
+	default:
+		vErr.Add("method", distance.ErrInvalidMethod)
+	}
+}
+
+type Request struct {
+	From PlaceRequest `json:"from"`
+	To   PlaceRequest `json:"to"`
+	// Method is haversine for a sphere or vincenty for the WGS 84 ellipsoid
+	Method distance.Method `json:"method,omitempty" swaggertype:"string" enums:"haversine,vincenty" example:"haversine"`
+
+	from, to distance.Place
+}
+
+func (dr *Request) Bind(r *http.Request) error {
+	var vErr validation.Error
+	dr.from = dr.From.place("from", &vErr)
+	dr.to = dr.To.place("to", &vErr)
+	bindMethod(&dr.Method, &vErr)
+	return vErr.Err()
+}
+
+type MatrixRequest struct {
+	Origins      []PlaceRequest `json:"origins"`
+	Destinations []PlaceRequest `json:"destinations"`
+	// Method is haversine for a sphere or vincenty for the WGS 84 ellipsoid
+	Method distance.Method `json:"method,omitempty" swaggertype:"string" enums:"haversine,vincenty" example:"haversine"`
+
+	origins, destinations []distance.Place
+}
+
+func (mr *MatrixRequest) Bind(r *http.Request) error {
+	var vErr validation.Error
+	if len(mr.Origins) == 0 {
+		vErr.Add("origins", errors.New("cannot be empty"))
+	}
+	if len(mr.Destinations) == 0 {
+		vErr.Add("destinations", errors.New("cannot be empty"))
+	}
+	mr.origins = make([]distance.Place, len(mr.Origins))
+	for i := range mr.Origins {
+		mr.origins[i] = mr.Origins[i].place(fmt.Sprintf("origins[%d]", i), &vErr)
+	}
+	mr.destinations = make([]distance.Place, len(mr.Destinations))
+	for i := range mr.Destinations {
+		mr.destinations[i] = mr.Destinations[i].place(fmt.Sprintf("destinations[%d]", i), &vErr)
+	}
+	bindMethod(&mr.Method, &vErr)
+	return vErr.Err()
+}
+
+// @Summary		Distance and initial bearing between two places
+// @Description	Places are given by coordinates or by addresses resolved to their best match.
+// @Tags			distance
+// @Param			places	body		Request	true	"origin, destination and method"
+// @Success		200		{object}	distanceResponse.Response
+// @Failure		400		{object}	responder.Response{data=validation.Error}	"invalid place or method, address not found"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		500		{object}	response.ErrResponse
+// @Failure		504		{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/distance [post]
+func (d *Distance) Distance(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.distance.Distance"
+	log := d.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	data := &Request{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		d.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Any("data", data))
+
+	ctx := context.WithValue(r.Context(), d.requestIdKey, middleware.GetReqID(r.Context()))
+	m, err := d.uc.Matrix(ctx, []distance.Place{data.from}, []distance.Place{data.to}, data.Method)
+	if err != nil {
+		d.error(w, log, err)
+		return
+	}
+
+	res := distanceResponse.NewResponse(m, data.Method)
+	log.Info("request executed", slog.Any("response", res))
+	d.responder.OutputJSON(w, res)
+}
+
+// @Summary		Distances and initial bearings from every origin to every destination
+// @Description	Places are given by coordinates or by addresses resolved to their best match.
+// @Description	The number of origins and destinations is limited by the service configuration.
+// @Tags			distance
+// @Param			places	body		MatrixRequest	true	"origins, destinations and method"
+// @Success		200		{object}	distanceResponse.MatrixResponse
+// @Failure		400		{object}	responder.Response{data=validation.Error}	"invalid place or method, too many places, address not found"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		500		{object}	response.ErrResponse
+// @Failure		504		{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/distance/matrix [post]
+func (d *Distance) Matrix(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.distance.Matrix"
+	log := d.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	data := &MatrixRequest{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		d.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Int("origins", len(data.origins)), slog.Int("destinations", len(data.destinations)))
+
+	ctx := context.WithValue(r.Context(), d.requestIdKey, middleware.GetReqID(r.Context()))
+	m, err := d.uc.Matrix(ctx, data.origins, data.destinations, data.Method)
+	if err != nil {
+		d.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed")
+	d.responder.OutputJSON(w, distanceResponse.NewMatrixResponse(m, data.Method))
+}
+
+func (d *Distance) error(w http.ResponseWriter, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, distance.ErrInvalidMethod), errors.Is(err, distance.ErrNoPlaces),
+		errors.Is(err, distance.ErrTooManyPlaces), errors.Is(err, distance.ErrInvalidPlace),
+		errors.Is(err, distance.ErrAddressNotFound), errors.Is(err, geo.ErrInvalidPoint):
+		log.Error("invalid distance request", sl.Err(err))
+		d.responder.ErrorBadRequest(w, err)
+	case errors.Is(err, geo.ErrUpstreamTimeout):
+		log.Error("geo provider timed out", sl.Err(err))
+		d.responder.ErrorGatewayTimeout(w, err)
+	default:
+		log.Error("failed to compute distances", sl.Err(err))
+		d.responder.ErrorInternal(w, err)
+	}
+}
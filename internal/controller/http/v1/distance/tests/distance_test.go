@@ -0,0 +1,310 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	distanceController "geo/internal/controller/http/v1/distance"
+	"geo/internal/infrastructure/responder"
+	resp "geo/internal/lib/api/distance/distanceResponse"
+	"geo/internal/lib/api/validation"
+	"geo/internal/service/distance"
+	"geo/internal/service/geo"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5/middleware"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDistanceHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+
+	kremlin := distance.Location{Lat: 55.751999, Lon: 37.617734}
+	snezhnaya := distance.Location{Lat: 55.8481373, Lon: 37.6414907, Address: "г Москва, ул Снежная, д 4"}
+	leg := distance.Leg{Meters: 10792.7, Bearing: 8.1}
+
+	type call struct {
+		origins      []distance.Place
+		destinations []distance.Place
+		method       distance.Method
+		matrix       *distance.Matrix
+		err          error
+	}
+	tests := []struct {
+		name       string
+		body       string
+		call       *call
+		want       *resp.Response
+		wantFields map[string]string
+		respStatus int
+	}{
+		{
+			name: "coordinates and address",
+			body: `{"from": {"lat": 55.751999, "lng": "37.617734"}, "to": {"address": "Москва, Снежная 4"}}`,
+			call: &call{
+				origins:      []distance.Place{{Point: &geo.Point{Lat: 55.751999, Lon: 37.617734}}},
+				destinations: []distance.Place{{Address: "Москва, Снежная 4"}},
+				method:       distance.MethodHaversine,
+				matrix: &distance.Matrix{
+					Origins:      []distance.Location{kremlin},
+					Destinations: []distance.Location{snezhnaya},
+					Legs:         [][]distance.Leg{{leg}},
+				},
+			},
+			want: &resp.Response{
+				Method:  "haversine",
+				From:    kremlin,
+				To:      snezhnaya,
+				Meters:  leg.Meters,
+				Bearing: leg.Bearing,
+			},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "vincenty",
+			body: `{"from": {"lat": 0, "lng": 0}, "to": {"lat": 0.5, "lng": 179.7}, "method": "vincenty"}`,
+			call: &call{
+				origins:      []distance.Place{{Point: &geo.Point{Lat: 0, Lon: 0}}},
+				destinations: []distance.Place{{Point: &geo.Point{Lat: 0.5, Lon: 179.7}}},
+				method:       distance.MethodVincenty,
+				matrix: &distance.Matrix{
+					Origins:      []distance.Location{{}},
+					Destinations: []distance.Location{{Lat: 0.5, Lon: 179.7}},
+					Legs:         [][]distance.Leg{{{Error: "vincenty formula failed to converge"}}},
+				},
+			},
+			want: &resp.Response{
+				Method: "vincenty",
+				To:     distance.Location{Lat: 0.5, Lon: 179.7},
+				Error:  "vincenty formula failed to converge",
+			},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "invalid places and method",
+			body: `{"from": {"lat": 91}, "to": {"lat": 1, "lng": 1, "address": "Москва"}, "method": "manhattan"}`,
+			wantFields: map[string]string{
+				"from.lat": geo.ErrLatitudeRange.Error(),
+				"from.lng": geo.ErrEmptyCoordinate.Error(),
+				"to":       "must have either coordinates or an address, not both",
+				"method":   distance.ErrInvalidMethod.Error(),
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty place",
+			body:       `{"from": {}, "to": {"address": "Москва"}}`,
+			wantFields: map[string]string{"from": "must have coordinates or an address"},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name: "address not found",
+			body: `{"from": {"address": "нигде"}, "to": {"address": "Москва"}}`,
+			call: &call{
+				origins:      []distance.Place{{Address: "нигде"}},
+				destinations: []distance.Place{{Address: "Москва"}},
+				method:       distance.MethodHaversine,
+				err:          distance.ErrAddressNotFound,
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name: "upstream timeout",
+			body: `{"from": {"address": "Тверь"}, "to": {"address": "Москва"}}`,
+			call: &call{
+				origins:      []distance.Place{{Address: "Тверь"}},
+				destinations: []distance.Place{{Address: "Москва"}},
+				method:       distance.MethodHaversine,
+				err:          geo.ErrUpstreamTimeout,
+			},
+			respStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name: "use case error",
+			body: `{"from": {"address": "Тверь"}, "to": {"address": "Москва"}}`,
+			call: &call{
+				origins:      []distance.Place{{Address: "Тверь"}},
+				destinations: []distance.Place{{Address: "Москва"}},
+				method:       distance.MethodHaversine,
+				err:          geo.ErrInternal,
+			},
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewDistance(t)
+			controller := distanceController.New(log, requestIdKey, useCaseMock, responseManager)
+			handler := http.HandlerFunc(controller.Distance)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/distance", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			if tt.call != nil {
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				useCaseMock.On("Matrix", ctxMock, tt.call.origins, tt.call.destinations, tt.call.method).
+					Return(tt.call.matrix, tt.call.err).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.want != nil {
+				var res resp.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, *tt.want, res)
+			}
+			if tt.wantFields != nil {
+				var res struct {
+					Data validation.Error `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.wantFields, res.Data.Fields)
+			}
+		})
+	}
+}
+
+func TestDistanceMatrixHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	requestIdKey := app.RequestIdKey
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	responseManager := responder.NewResponder(decoder, log)
+
+	kremlin := distance.Location{Lat: 55.751999, Lon: 37.617734}
+	snezhnaya := distance.Location{Lat: 55.8481373, Lon: 37.6414907, Address: "г Москва, ул Снежная, д 4"}
+	there := distance.Leg{Meters: 10792.7, Bearing: 8.1}
+	back := distance.Leg{Meters: 10792.7, Bearing: 188.1}
+
+	type call struct {
+		origins      []distance.Place
+		destinations []distance.Place
+		matrix       *distance.Matrix
+		err          error
+	}
+	tests := []struct {
+		name       string
+		body       string
+		call       *call
+		want       *resp.MatrixResponse
+		wantFields map[string]string
+		respStatus int
+	}{
+		{
+			name: "rows in origin order",
+			body: `{"origins": [{"lat": 55.751999, "lng": 37.617734}, {"address": "Москва, Снежная 4"}],` +
+				`"destinations": [{"address": "Москва, Снежная 4"}, {"lat": 55.751999, "lng": 37.617734}]}`,
+			call: &call{
+				origins: []distance.Place{
+					{Point: &geo.Point{Lat: 55.751999, Lon: 37.617734}},
+					{Address: "Москва, Снежная 4"},
+				},
+				destinations: []distance.Place{
+					{Address: "Москва, Снежная 4"},
+					{Point: &geo.Point{Lat: 55.751999, Lon: 37.617734}},
+				},
+				matrix: &distance.Matrix{
+					Origins:      []distance.Location{kremlin, snezhnaya},
+					Destinations: []distance.Location{snezhnaya, kremlin},
+					Legs:         [][]distance.Leg{{there, {}}, {{}, back}},
+				},
+			},
+			want: &resp.MatrixResponse{
+				Method:       "haversine",
+				Origins:      []distance.Location{kremlin, snezhnaya},
+				Destinations: []distance.Location{snezhnaya, kremlin},
+				Rows: []resp.MatrixRow{
+					{Legs: []distance.Leg{there, {}}},
+					{Legs: []distance.Leg{{}, back}},
+				},
+			},
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "item errors are keyed by index",
+			body: `{"origins": [{"address": "Москва"}, {"lat": "x", "lng": 1}], "destinations": []}`,
+			wantFields: map[string]string{
+				"origins[1].lat": geo.ErrNotANumber.Error(),
+				"destinations":   "cannot be empty",
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name: "too many places",
+			body: `{"origins": [{"address": "Москва"}, {"address": "Тверь"}], "destinations": [{"address": "Казань"}]}`,
+			call: &call{
+				origins:      []distance.Place{{Address: "Москва"}, {Address: "Тверь"}},
+				destinations: []distance.Place{{Address: "Казань"}},
+				err:          distance.ErrTooManyPlaces,
+			},
+			respStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCaseMock := mocks.NewDistance(t)
+			controller := distanceController.New(log, requestIdKey, useCaseMock, responseManager)
+			handler := http.HandlerFunc(controller.Matrix)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/distance/matrix", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			if tt.call != nil {
+				ctxMock := mock.MatchedBy(func(c context.Context) bool {
+					return c.Value(requestIdKey) == "1"
+				})
+				useCaseMock.On("Matrix", ctxMock, tt.call.origins, tt.call.destinations, distance.MethodHaversine).
+					Return(tt.call.matrix, tt.call.err).Once()
+			}
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.want != nil {
+				var res resp.MatrixResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, *tt.want, res)
+			}
+			if tt.wantFields != nil {
+				var res struct {
+					Data validation.Error `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.wantFields, res.Data.Fields)
+			}
+		})
+	}
+}
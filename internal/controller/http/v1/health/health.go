@@ -0,0 +1,76 @@
+package health
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+)
+
+type Checker interface {
+	Health(http.ResponseWriter, *http.Request)
+}
+
+// Reporter is a component whose state is exposed by the health check.
+type Reporter interface {
+	Name() string
+	Status() (healthy bool, details interface{})
+}
+
+type Health struct {
+	log       *slog.Logger
+	reporters []Reporter
+}
+
+func New(log *slog.Logger, reporters ...Reporter) *Health {
+	return &Health{log: log, reporters: reporters}
+}
+
+type Component struct {
+	Healthy bool        `json:"healthy"`
+	Details interface{} `json:"details,omitempty"`
+} //@name HealthComponent
+
+type Response struct {
+	HTTPStatusCode int                  `json:"-"`
+	Status         string               `json:"status" example:"ok"`
+	Components     map[string]Component `json:"components,omitempty"`
+} //@name HealthResponse
+
+func (resp *Response) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, resp.HTTPStatusCode)
+	return nil
+}
+
+// @Summary	Service health
+// @Tags		health
+// @Produce	json
+// @Success	200	{object}	Response	"all components are healthy"
+// @Failure	503	{object}	Response	"some components are unhealthy"
+// @Router		/health [get]
+func (h *Health) Health(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.health.Health"
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	resp := &Response{
+		HTTPStatusCode: http.StatusOK,
+		Status:         "ok",
+		Components:     make(map[string]Component, len(h.reporters)),
+	}
+	for _, rep := range h.reporters {
+		healthy, details := rep.Status()
+		resp.Components[rep.Name()] = Component{Healthy: healthy, Details: details}
+		if !healthy {
+			resp.HTTPStatusCode = http.StatusServiceUnavailable
+			resp.Status = "degraded"
+		}
+	}
+
+	if resp.HTTPStatusCode != http.StatusOK {
+		log.Warn("service is degraded", slog.Any("components", resp.Components))
+	}
+	render.Render(w, r, resp)
+}
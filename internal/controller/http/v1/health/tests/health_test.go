@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/controller/http/v1/health"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type reporter struct {
+	name    string
+	healthy bool
+}
+
+func (r reporter) Name() string {
+	return r.name
+}
+
+func (r reporter) Status() (bool, interface{}) {
+	return r.healthy, nil
+}
+
+func TestHealthHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+
+	tests := []struct {
+		name       string
+		reporters  []health.Reporter
+		wantStatus string
+		respStatus int
+	}{
+		{
+			name:       "no components",
+			reporters:  nil,
+			wantStatus: "ok",
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "healthy",
+			reporters: []health.Reporter{
+				reporter{name: "geo_provider", healthy: true},
+			},
+			wantStatus: "ok",
+			respStatus: http.StatusOK,
+		},
+		{
+			name: "degraded",
+			reporters: []health.Reporter{
+				reporter{name: "geo_provider", healthy: false},
+				reporter{name: "cache", healthy: true},
+			},
+			wantStatus: "degraded",
+			respStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := health.New(log, tt.reporters...)
+			handler := http.HandlerFunc(controller.Health)
+
+			req, err := http.NewRequest(http.MethodGet, "/health", nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			var res health.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, tt.wantStatus, res.Status)
+			require.Len(t, res.Components, len(tt.reporters))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package jwks
+
+import (
+	"geo/internal/lib/logger/sl"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"log/slog"
+	"net/http"
+)
+
+type Publisher interface {
+	JWKS(http.ResponseWriter, *http.Request)
+}
+
+// KeySet gives the public keys the access tokens are verified with.
+type KeySet interface {
+	JWKS() (jwk.Set, error)
+}
+
+type JWKS struct {
+	log  *slog.Logger
+	keys KeySet
+}
+
+func New(log *slog.Logger, keys KeySet) *JWKS {
+	return &JWKS{log: log, keys: keys}
+}
+
+// JWKS serves the JSON Web Key Set at /.well-known/jwks.json, outside of the api,
+// so that other services verify the access tokens on their own. A token is verified
+// with the key whose kid is in its header, the set is empty when the tokens are
+// signed with a shared secret.
+func (j *JWKS) JWKS(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.jwks.JWKS"
+	log := j.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+
+	set, err := j.keys.JWKS()
+	if err != nil {
+		log.Error("failed to get public keys", sl.Err(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	// keys are rotated by restarting with a new configuration
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	render.JSON(w, r, set)
+}
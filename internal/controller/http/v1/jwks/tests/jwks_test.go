@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"geo/internal/controller/http/v1/jwks"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type keySet struct {
+	set jwk.Set
+	err error
+}
+
+func (k keySet) JWKS() (jwk.Set, error) {
+	return k.set, k.err
+}
+
+func TestJWKSHandler(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := jwk.FromRaw(public)
+	require.NoError(t, err)
+	require.NoError(t, key.Set(jwk.KeyIDKey, "kid-1"))
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(key))
+
+	tests := []struct {
+		name       string
+		keys       keySet
+		wantKids   []string
+		respStatus int
+	}{
+		{
+			name:       "public keys",
+			keys:       keySet{set: set},
+			wantKids:   []string{"kid-1"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "no keys",
+			keys:       keySet{set: jwk.NewSet()},
+			wantKids:   []string{},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "error",
+			keys:       keySet{err: errors.New("broken key")},
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+			ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+			rr := httptest.NewRecorder()
+
+			jwks.New(log, tt.keys).JWKS(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.wantKids != nil {
+				var res struct {
+					Keys []struct {
+						Kid string `json:"kid"`
+						Kty string `json:"kty"`
+					} `json:"keys"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				kids := make([]string, 0, len(res.Keys))
+				for _, k := range res.Keys {
+					kids = append(kids, k.Kid)
+				}
+				require.Equal(t, tt.wantKids, kids)
+			}
+		})
+	}
+}
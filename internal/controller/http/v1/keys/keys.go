@@ -0,0 +1,268 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/validation"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/apikey"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNoSubject = errors.New("token has no subject")
+
+type KeyManager interface {
+	Create(http.ResponseWriter, *http.Request)
+	List(http.ResponseWriter, *http.Request)
+	Update(http.ResponseWriter, *http.Request)
+	Revoke(http.ResponseWriter, *http.Request)
+}
+
+type Keys struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.APIKeys
+	responder    responder.Responder
+}
+
+func New(log *slog.Logger, requestIdKey string, uc service.APIKeys, responder responder.Responder) *Keys {
+	return &Keys{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder}
+}
+
+type CreateRequest struct {
+	Label string `json:"label" example:"nightly geocoding"`
+	// Scopes limit the key to address, distance or places, a key without scopes may use all of them
+	Scopes []string `json:"scopes,omitempty" example:"address"`
+	// ExpiresAt is when the key stops working, a key without it never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2025-05-01T09:30:00Z"`
+} //@name APIKeyCreateRequest
+
+func (cr *CreateRequest) Bind(r *http.Request) error {
+	var vErr validation.Error
+	if err := apikey.ValidateLabel(cr.Label); err != nil {
+		vErr.Add("label", err)
+	}
+	if err := apikey.ValidateScopes(cr.Scopes); err != nil {
+		vErr.Add("scopes", err)
+	}
+	if cr.ExpiresAt != nil && !cr.ExpiresAt.After(time.Now()) {
+		vErr.Add("expires_at", apikey.ErrPastExpiry)
+	}
+	return vErr.Err()
+}
+
+type UpdateRequest struct {
+	Label string `json:"label" example:"nightly geocoding"`
+} //@name APIKeyUpdateRequest
+
+func (ur *UpdateRequest) Bind(r *http.Request) error {
+	var vErr validation.Error
+	if err := apikey.ValidateLabel(ur.Label); err != nil {
+		vErr.Add("label", err)
+	}
+	return vErr.Err()
+}
+
+type ListResponse struct {
+	Keys []*apikey.Key `json:"keys"`
+} //@name APIKeyList
+
+// @Summary		Issue an API key
+// @Description	The secret is returned only once, the service keeps its hash.
+// @Description	Machine clients send it in the X-API-Key header or as Authorization: ApiKey <secret>.
+// @Tags			keys
+// @Param			key	body		CreateRequest	true	"label, scopes and expiry"
+// @Success		201	{object}	apikey.NewKey
+// @Failure		400	{object}	responder.Response{data=validation.Error}	"invalid label, scopes or expiry, too many keys"
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403	"Forbidden: API keys cannot manage keys"
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/keys [post]
+func (k *Keys) Create(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.keys.Create"
+	log := k.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := k.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	data := &CreateRequest{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		k.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Any("data", data))
+
+	in := apikey.Input{Label: data.Label, Scopes: data.Scopes}
+	if data.ExpiresAt != nil {
+		in.ExpiresAt = *data.ExpiresAt
+	}
+	ctx := context.WithValue(r.Context(), k.requestIdKey, middleware.GetReqID(r.Context()))
+	res, err := k.uc.Create(ctx, owner, in)
+	if err != nil {
+		k.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.String("id", res.ID))
+	k.responder.OutputCreated(w, res)
+}
+
+// @Summary		API keys of the user
+// @Description	Keys are listed oldest first, without their secrets.
+// @Tags			keys
+// @Success		200	{object}	ListResponse
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403	"Forbidden: API keys cannot manage keys"
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/keys [get]
+func (k *Keys) List(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.keys.List"
+	log := k.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := k.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), k.requestIdKey, middleware.GetReqID(r.Context()))
+	keys, err := k.uc.List(ctx, owner)
+	if err != nil {
+		k.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.Int("count", len(keys)))
+	k.responder.OutputJSON(w, ListResponse{Keys: keys})
+}
+
+// @Summary		Relabel an API key
+// @Tags			keys
+// @Param			id	path		string			true	"key id"
+// @Param			key	body		UpdateRequest	true	"new label"
+// @Success		200	{object}	apikey.Key
+// @Failure		400	{object}	responder.Response{data=validation.Error}	"invalid label"
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403	"Forbidden: API keys cannot manage keys"
+// @Failure		404	{object}	response.ErrResponse	"no such key of the user"
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/keys/{id} [patch]
+func (k *Keys) Update(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.keys.Update"
+	log := k.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := k.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	data := &UpdateRequest{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		k.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Any("data", data))
+
+	ctx := context.WithValue(r.Context(), k.requestIdKey, middleware.GetReqID(r.Context()))
+	res, err := k.uc.Rename(ctx, owner, chi.URLParam(r, "id"), data.Label)
+	if err != nil {
+		k.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.String("id", res.ID))
+	k.responder.OutputJSON(w, res)
+}
+
+// @Summary		Revoke an API key
+// @Description	The requests made with the key are rejected from now on.
+// @Tags			keys
+// @Param			id	path	string	true	"key id"
+// @Success		204
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403	"Forbidden: API keys cannot manage keys"
+// @Failure		404	{object}	response.ErrResponse	"no such key of the user"
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/keys/{id} [delete]
+func (k *Keys) Revoke(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.keys.Revoke"
+	log := k.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := k.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), k.requestIdKey, middleware.GetReqID(r.Context()))
+	if err := k.uc.Revoke(ctx, owner, chi.URLParam(r, "id")); err != nil {
+		k.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// owner returns the subject of the token the keys belong to.
+func (k *Keys) owner(w http.ResponseWriter, r *http.Request, log *slog.Logger) (string, bool) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	sub, _ := claims["sub"].(string)
+	if strings.TrimSpace(sub) == "" {
+		log.Error("token passed through middleware without subject")
+		k.responder.ErrorUnauthorized(w, ErrNoSubject)
+		return "", false
+	}
+	return sub, true
+}
+
+func (k *Keys) error(w http.ResponseWriter, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, apikey.ErrNotFound):
+		log.Info("api key not found", sl.Err(err))
+		k.responder.ErrorNotFound(w, err)
+	case errors.Is(err, apikey.ErrEmptyLabel), errors.Is(err, apikey.ErrLabelTooLong),
+		errors.Is(err, apikey.ErrInvalidScope), errors.Is(err, apikey.ErrPastExpiry),
+		errors.Is(err, apikey.ErrTooManyKeys):
+		log.Error("invalid api key request", sl.Err(err))
+		k.responder.ErrorBadRequest(w, err)
+	default:
+		log.Error("failed to handle api key", sl.Err(err))
+		k.responder.ErrorInternal(w, err)
+	}
+}
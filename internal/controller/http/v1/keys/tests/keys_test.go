@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	keysController "geo/internal/controller/http/v1/keys"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/validation"
+	"geo/internal/service/apikey"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const owner = "alice"
+
+func newController(t *testing.T) (*keysController.Keys, *mocks.APIKeys) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	useCaseMock := mocks.NewAPIKeys(t)
+	return keysController.New(log, app.RequestIdKey, useCaseMock, responder.NewResponder(decoder, log)), useCaseMock
+}
+
+// newRequest returns a request authenticated as owner with the id url parameter set.
+func newRequest(t *testing.T, method, body, id string) *http.Request {
+	req, err := http.NewRequest(method, "/api/keys", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	token, _, err := ja.Encode(map[string]interface{}{"sub": owner, "jti": "1", "exp": time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	ctx = jwtauth.NewContext(ctx, token, nil)
+	return req.WithContext(ctx)
+}
+
+var ctxMock = mock.MatchedBy(func(c context.Context) bool {
+	return c.Value(app.RequestIdKey) == "1"
+})
+
+func TestKeysCreateHandler(t *testing.T) {
+	expiresAt := time.Date(2099, 5, 1, 9, 30, 0, 0, time.UTC)
+	created := &apikey.NewKey{
+		Key:    apikey.Key{ID: "1", Label: "batch", Prefix: "geo_abcdefgh", Scopes: []string{apikey.ScopeAddress}},
+		Secret: "geo_abcdefghijk",
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantInput  *apikey.Input
+		mockError  error
+		wantFields map[string]string
+		respStatus int
+	}{
+		{
+			name:       "success",
+			body:       `{"label": "batch", "scopes": ["address"], "expires_at": "2099-05-01T09:30:00Z"}`,
+			wantInput:  &apikey.Input{Label: "batch", Scopes: []string{apikey.ScopeAddress}, ExpiresAt: expiresAt},
+			respStatus: http.StatusCreated,
+		},
+		{
+			name:       "never expires",
+			body:       `{"label": "batch"}`,
+			wantInput:  &apikey.Input{Label: "batch"},
+			respStatus: http.StatusCreated,
+		},
+		{
+			name: "invalid fields",
+			body: `{"label": "", "scopes": ["admin"], "expires_at": "2000-01-01T00:00:00Z"}`,
+			wantFields: map[string]string{
+				"label":      apikey.ErrEmptyLabel.Error(),
+				"scopes":     apikey.ErrInvalidScope.Error(),
+				"expires_at": apikey.ErrPastExpiry.Error(),
+			},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too many keys",
+			body:       `{"label": "batch"}`,
+			wantInput:  &apikey.Input{Label: "batch"},
+			mockError:  apikey.ErrTooManyKeys,
+			respStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			if tt.wantInput != nil {
+				var res *apikey.NewKey
+				if tt.mockError == nil {
+					res = created
+				}
+				useCaseMock.On("Create", ctxMock, owner, mock.MatchedBy(func(in apikey.Input) bool {
+					return in.Label == tt.wantInput.Label && in.ExpiresAt.Equal(tt.wantInput.ExpiresAt) &&
+						len(in.Scopes) == len(tt.wantInput.Scopes)
+				})).Return(res, tt.mockError).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			controller.Create(rr, newRequest(t, http.MethodPost, tt.body, ""))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.respStatus == http.StatusCreated {
+				var res apikey.NewKey
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, *created, res)
+			}
+			if tt.wantFields != nil {
+				var res struct {
+					Data validation.Error `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.wantFields, res.Data.Fields)
+			}
+		})
+	}
+}
+
+func TestKeysListHandler(t *testing.T) {
+	controller, useCaseMock := newController(t)
+	keys := []*apikey.Key{{ID: "1", Label: "batch", Prefix: "geo_abcdefgh", Scopes: []string{}}}
+	useCaseMock.On("List", ctxMock, owner).Return(keys, nil).Once()
+	rr := httptest.NewRecorder()
+
+	controller.List(rr, newRequest(t, http.MethodGet, "", ""))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotContains(t, rr.Body.String(), "secret")
+	var res keysController.ListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, keys, res.Keys)
+}
+
+func TestKeysUpdateHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		mockCall   bool
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "success",
+			body:       `{"label": "nightly"}`,
+			mockCall:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "empty label",
+			body:       `{"label": ""}`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			body:       `{"label": "nightly"}`,
+			mockCall:   true,
+			mockError:  apikey.ErrNotFound,
+			respStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			if tt.mockCall {
+				var res *apikey.Key
+				if tt.mockError == nil {
+					res = &apikey.Key{ID: "1", Label: "nightly", Scopes: []string{}}
+				}
+				useCaseMock.On("Rename", ctxMock, owner, "1", "nightly").Return(res, tt.mockError).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			controller.Update(rr, newRequest(t, http.MethodPatch, tt.body, "1"))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+		})
+	}
+}
+
+func TestKeysRevokeHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "success",
+			respStatus: http.StatusNoContent,
+		},
+		{
+			name:       "not found",
+			mockError:  apikey.ErrNotFound,
+			respStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			useCaseMock.On("Revoke", ctxMock, owner, "1").Return(tt.mockError).Once()
+			rr := httptest.NewRecorder()
+
+			controller.Revoke(rr, newRequest(t, http.MethodDelete, "", "1"))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package places
+
+import (
+	"context"
+	"errors"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/coordinate"
+	"geo/internal/lib/api/validation"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/geo"
+	"geo/internal/service/place"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+var ErrNoSubject = errors.New("token has no subject")
+
+type Placer interface {
+	Create(http.ResponseWriter, *http.Request)
+	List(http.ResponseWriter, *http.Request)
+	Get(http.ResponseWriter, *http.Request)
+	Update(http.ResponseWriter, *http.Request)
+	Delete(http.ResponseWriter, *http.Request)
+}
+
+type Places struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.Places
+	responder    responder.Responder
+}
+
+func New(log *slog.Logger, requestIdKey string, uc service.Places, responder responder.Responder) *Places {
+	return &Places{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder}
+}
+
+type GeocodeSource struct {
+	Lat coordinate.Coordinate `json:"lat" swaggertype:"number" example:"55.8481373"`
+	Lng coordinate.Coordinate `json:"lng" swaggertype:"number" example:"37.6414907"`
+} //@name PlaceGeocodeSource
+
+// SourceRequest is where the address of a place comes from, exactly one of the fields is set.
+type SourceRequest struct {
+	// Address is an address as search or geocode returned it
+	Address *geo.Address `json:"address,omitempty"`
+	// Search is a query whose best match is saved
+	Search string `json:"search,omitempty" example:"г Москва, ул Снежная, д 4"`
+	// Geocode is a point whose nearest address is saved
+	Geocode *GeocodeSource `json:"geocode,omitempty"`
+}
+
+func (sr *SourceRequest) isSet() bool {
+	return sr.Address != nil || sr.Search != "" || sr.Geocode != nil
+}
+
+// source checks the request, problems are added to vErr.
+func (sr *SourceRequest) source(vErr *validation.Error) place.Source {
+	src := place.Source{Address: sr.Address, Search: sr.Search}
+	if sr.Geocode != nil {
+		lat, latErr := geo.ParseLatitude(string(sr.Geocode.Lat))
+		if latErr != nil {
+			vErr.Add("geocode.lat", latErr)
+		}
+		lon, lonErr := geo.ParseLongitude(string(sr.Geocode.Lng))
+		if lonErr != nil {
+			vErr.Add("geocode.lng", lonErr)
+		}
+		if latErr != nil || lonErr != nil {
+			return src
+		}
+		src.Geocode = &geo.Point{Lat: lat, Lon: lon}
+	}
+	err := place.ValidateSource(src)
+	switch {
+	case errors.Is(err, place.ErrInvalidAddress):
+		vErr.Add("address", err)
+	case errors.Is(err, geo.ErrInvalidPoint):
+		vErr.Add("geocode", err)
+	case err != nil:
+		vErr.Add("source", err)
+	}
+	return src
+}
+
+type CreateRequest struct {
+	Label string   `json:"label" example:"Склад на Снежной"`
+	Tags  []string `json:"tags,omitempty" example:"depot,north"`
+	SourceRequest
+
+	src place.Source
+} //@name PlaceCreateRequest
+
+func (cr *CreateRequest) Bind(r *http.Request) error {
+	var vErr validation.Error
+	if err := place.ValidateLabel(cr.Label); err != nil {
+		vErr.Add("label", err)
+	}
+	if err := place.ValidateTags(cr.Tags); err != nil {
+		vErr.Add("tags", err)
+	}
+	cr.src = cr.SourceRequest.source(&vErr)
+	return vErr.Err()
+}
+
+type UpdateRequest struct {
+	Label *string `json:"label,omitempty" example:"Склад на Снежной"`
+	// Tags replace the tags, an empty array removes them
+	Tags []string `json:"tags,omitempty" example:"depot,north"`
+	// A source replaces the address
+	SourceRequest
+
+	patch place.Patch
+} //@name PlaceUpdateRequest
+
+func (ur *UpdateRequest) Bind(r *http.Request) error {
+	var vErr validation.Error
+	if ur.Label != nil {
+		if err := place.ValidateLabel(*ur.Label); err != nil {
+			vErr.Add("label", err)
+		}
+	}
+	if err := place.ValidateTags(ur.Tags); err != nil {
+		vErr.Add("tags", err)
+	}
+	ur.patch = place.Patch{Label: ur.Label, Tags: ur.Tags}
+	if ur.SourceRequest.isSet() {
+		src := ur.SourceRequest.source(&vErr)
+		ur.patch.Source = &src
+	}
+	return vErr.Err()
+}
+
+type ListResponse struct {
+	Places []*place.Place `json:"places"`
+} //@name PlaceList
+
+// @Summary		Save a place
+// @Description	The address is given as a search or geocode result, or is resolved from a query or a point to its best match.
+// @Description	Exactly one of address, search and geocode is set.
+// @Tags			places
+// @Param			place	body		CreateRequest	true	"label, tags and address source"
+// @Success		201		{object}	place.Place
+// @Failure		400		{object}	responder.Response{data=validation.Error}	"invalid label, tags or source, address not found, too many places"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		500		{object}	response.ErrResponse
+// @Failure		504		{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/places [post]
+func (p *Places) Create(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.places.Create"
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := p.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	data := &CreateRequest{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		p.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Any("data", data))
+
+	ctx := context.WithValue(r.Context(), p.requestIdKey, middleware.GetReqID(r.Context()))
+	res, err := p.uc.Create(ctx, owner, place.Input{Label: data.Label, Tags: data.Tags, Source: data.src})
+	if err != nil {
+		p.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.Any("response", res))
+	p.responder.OutputCreated(w, res)
+}
+
+// @Summary		Saved places of the user
+// @Description	Places are listed oldest first.
+// @Tags			places
+// @Param			tag	query		string	false	"only the places with the tag, case insensitive"
+// @Success		200	{object}	ListResponse
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/places [get]
+func (p *Places) List(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.places.List"
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := p.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), p.requestIdKey, middleware.GetReqID(r.Context()))
+	places, err := p.uc.List(ctx, owner, r.URL.Query().Get("tag"))
+	if err != nil {
+		p.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.Int("count", len(places)))
+	p.responder.OutputJSON(w, ListResponse{Places: places})
+}
+
+// @Summary		Saved place
+// @Tags			places
+// @Param			id	path		string	true	"place id"
+// @Success		200	{object}	place.Place
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		404	{object}	response.ErrResponse	"no such place of the user"
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/places/{id} [get]
+func (p *Places) Get(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.places.Get"
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := p.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), p.requestIdKey, middleware.GetReqID(r.Context()))
+	res, err := p.uc.Get(ctx, owner, chi.URLParam(r, "id"))
+	if err != nil {
+		p.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.String("id", res.ID))
+	p.responder.OutputJSON(w, res)
+}
+
+// @Summary		Change a saved place
+// @Description	Only the fields sent are changed. A new address source replaces the address.
+// @Tags			places
+// @Param			id		path		string			true	"place id"
+// @Param			place	body		UpdateRequest	true	"fields to change"
+// @Success		200		{object}	place.Place
+// @Failure		400		{object}	responder.Response{data=validation.Error}	"invalid label, tags or source, address not found"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		404		{object}	response.ErrResponse	"no such place of the user"
+// @Failure		500		{object}	response.ErrResponse
+// @Failure		504		{object}	response.ErrResponse	"upstream timeout"
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/places/{id} [patch]
+func (p *Places) Update(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.places.Update"
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := p.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	data := &UpdateRequest{}
+	if err := render.Bind(r, data); err != nil {
+		log.Error("error decoding request", sl.Err(err))
+		p.responder.ErrorBadRequest(w, err)
+		return
+	}
+	log.Info("request received", slog.Any("data", data))
+
+	ctx := context.WithValue(r.Context(), p.requestIdKey, middleware.GetReqID(r.Context()))
+	res, err := p.uc.Update(ctx, owner, chi.URLParam(r, "id"), data.patch)
+	if err != nil {
+		p.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.Any("response", res))
+	p.responder.OutputJSON(w, res)
+}
+
+// @Summary		Delete a saved place
+// @Tags			places
+// @Param			id	path	string	true	"place id"
+// @Success		204
+//
+// @Failure		401	"Unauthorized: Token missing or invalid"
+// @Header			401	{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		404	{object}	response.ErrResponse	"no such place of the user"
+// @Failure		500	{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Security		XAPIKey
+// @Router			/places/{id} [delete]
+func (p *Places) Delete(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.places.Delete"
+	log := p.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	owner, ok := p.owner(w, r, log)
+	if !ok {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), p.requestIdKey, middleware.GetReqID(r.Context()))
+	if err := p.uc.Delete(ctx, owner, chi.URLParam(r, "id")); err != nil {
+		p.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// owner returns the subject of the token the places belong to.
+func (p *Places) owner(w http.ResponseWriter, r *http.Request, log *slog.Logger) (string, bool) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	sub, _ := claims["sub"].(string)
+	if strings.TrimSpace(sub) == "" {
+		log.Error("token passed through middleware without subject")
+		p.responder.ErrorUnauthorized(w, ErrNoSubject)
+		return "", false
+	}
+	return sub, true
+}
+
+func (p *Places) error(w http.ResponseWriter, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, place.ErrNotFound):
+		log.Info("place not found", sl.Err(err))
+		p.responder.ErrorNotFound(w, err)
+	case errors.Is(err, place.ErrEmptyLabel), errors.Is(err, place.ErrLabelTooLong),
+		errors.Is(err, place.ErrTooManyTags), errors.Is(err, place.ErrInvalidTag),
+		errors.Is(err, place.ErrInvalidSource), errors.Is(err, place.ErrInvalidAddress),
+		errors.Is(err, place.ErrAddressNotFound), errors.Is(err, place.ErrTooManyPlaces),
+		errors.Is(err, geo.ErrInvalidPoint):
+		log.Error("invalid place request", sl.Err(err))
+		p.responder.ErrorBadRequest(w, err)
+	case errors.Is(err, geo.ErrUpstreamTimeout):
+		log.Error("geo provider timed out", sl.Err(err))
+		p.responder.ErrorGatewayTimeout(w, err)
+	default:
+		log.Error("failed to handle place", sl.Err(err))
+		p.responder.ErrorInternal(w, err)
+	}
+}
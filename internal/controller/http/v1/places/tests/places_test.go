@@ -0,0 +1,331 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	placesController "geo/internal/controller/http/v1/places"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/api/validation"
+	"geo/internal/service/geo"
+	"geo/internal/service/mocks"
+	"geo/internal/service/place"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const owner = "alice"
+
+func newController(t *testing.T) (*placesController.Places, *mocks.Places) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	useCaseMock := mocks.NewPlaces(t)
+	return placesController.New(log, app.RequestIdKey, useCaseMock, responder.NewResponder(decoder, log)), useCaseMock
+}
+
+// newRequest returns a request authenticated as sub with the id url parameter set.
+func newRequest(t *testing.T, method, body, sub, id string) *http.Request {
+	req, err := http.NewRequest(method, "/api/places", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	claims := map[string]interface{}{"jti": "1", "exp": time.Now().Add(time.Hour)}
+	if sub != "" {
+		claims["sub"] = sub
+	}
+	token, _, err := ja.Encode(claims)
+	require.NoError(t, err)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	ctx = jwtauth.NewContext(ctx, token, nil)
+	return req.WithContext(ctx)
+}
+
+var ctxMock = mock.MatchedBy(func(c context.Context) bool {
+	return c.Value(app.RequestIdKey) == "1"
+})
+
+func TestPlacesCreateHandler(t *testing.T) {
+	snezhnaya := &geo.Address{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"}
+	created := &place.Place{ID: "1", Label: "Склад", Tags: []string{"depot"}, Address: *snezhnaya}
+
+	tests := []struct {
+		name       string
+		body       string
+		sub        string
+		wantInput  *place.Input
+		mockError  error
+		wantFields map[string]string
+		respStatus int
+	}{
+		{
+			name: "from a search result",
+			body: `{"label": "Склад", "tags": ["depot"], "address": {"city": "Москва", "street": "Снежная", "house": "4",
+				"lat": "55.8481373", "lon": "37.6414907"}}`,
+			sub:        owner,
+			wantInput:  &place.Input{Label: "Склад", Tags: []string{"depot"}, Source: place.Source{Address: snezhnaya}},
+			respStatus: http.StatusCreated,
+		},
+		{
+			name:       "from a query",
+			body:       `{"label": "Склад", "search": "г Москва, ул Снежная, д 4"}`,
+			sub:        owner,
+			wantInput:  &place.Input{Label: "Склад", Source: place.Source{Search: "г Москва, ул Снежная, д 4"}},
+			respStatus: http.StatusCreated,
+		},
+		{
+			name:       "from a point",
+			body:       `{"label": "Склад", "geocode": {"lat": 55.8481373, "lng": "37.6414907"}}`,
+			sub:        owner,
+			wantInput:  &place.Input{Label: "Склад", Source: place.Source{Geocode: &geo.Point{Lat: 55.8481373, Lon: 37.6414907}}},
+			respStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid fields",
+			body:       `{"label": " ", "tags": [""], "geocode": {"lat": 95, "lng": 37}}`,
+			sub:        owner,
+			wantFields: map[string]string{"label": place.ErrEmptyLabel.Error(), "tags": place.ErrInvalidTag.Error(), "geocode.lat": "must be between -90 and 90"},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "two sources",
+			body:       `{"label": "Склад", "search": "Снежная", "geocode": {"lat": 55, "lng": 37}}`,
+			sub:        owner,
+			wantFields: map[string]string{"source": place.ErrInvalidSource.Error()},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "address without coordinates",
+			body:       `{"label": "Склад", "address": {"city": "Москва"}}`,
+			sub:        owner,
+			wantFields: map[string]string{"address": place.ErrInvalidAddress.Error()},
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "address not found",
+			body:       `{"label": "Склад", "search": "нигде"}`,
+			sub:        owner,
+			wantInput:  &place.Input{Label: "Склад", Source: place.Source{Search: "нигде"}},
+			mockError:  place.ErrAddressNotFound,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too many places",
+			body:       `{"label": "Склад", "search": "Снежная"}`,
+			sub:        owner,
+			wantInput:  &place.Input{Label: "Склад", Source: place.Source{Search: "Снежная"}},
+			mockError:  place.ErrTooManyPlaces,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "upstream timeout",
+			body:       `{"label": "Склад", "search": "Снежная"}`,
+			sub:        owner,
+			wantInput:  &place.Input{Label: "Склад", Source: place.Source{Search: "Снежная"}},
+			mockError:  geo.ErrUpstreamTimeout,
+			respStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "token without subject",
+			body:       `{"label": "Склад", "search": "Снежная"}`,
+			respStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			if tt.wantInput != nil {
+				var res *place.Place
+				if tt.mockError == nil {
+					res = created
+				}
+				useCaseMock.On("Create", ctxMock, owner, *tt.wantInput).Return(res, tt.mockError).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			controller.Create(rr, newRequest(t, http.MethodPost, tt.body, tt.sub, ""))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.respStatus == http.StatusCreated {
+				var res place.Place
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, *created, res)
+			}
+			if tt.wantFields != nil {
+				var res struct {
+					Data validation.Error `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.wantFields, res.Data.Fields)
+			}
+		})
+	}
+}
+
+func TestPlacesListHandler(t *testing.T) {
+	controller, useCaseMock := newController(t)
+	places := []*place.Place{{ID: "1", Label: "Склад", Tags: []string{"depot"}}}
+	useCaseMock.On("List", ctxMock, owner, "depot").Return(places, nil).Once()
+	rr := httptest.NewRecorder()
+	req := newRequest(t, http.MethodGet, "", owner, "")
+	req.URL.RawQuery = "tag=depot"
+
+	controller.List(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res placesController.ListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, places, res.Places)
+}
+
+func TestPlacesGetHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockResult *place.Place
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "success",
+			mockResult: &place.Place{ID: "1", Label: "Склад", Tags: []string{}},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "not found",
+			mockError:  place.ErrNotFound,
+			respStatus: http.StatusNotFound,
+		},
+		{
+			name:       "use case error",
+			mockError:  place.ErrInternal,
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			useCaseMock.On("Get", ctxMock, owner, "1").Return(tt.mockResult, tt.mockError).Once()
+			rr := httptest.NewRecorder()
+
+			controller.Get(rr, newRequest(t, http.MethodGet, "", owner, "1"))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.mockResult != nil {
+				var res place.Place
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, *tt.mockResult, res)
+			}
+		})
+	}
+}
+
+func TestPlacesUpdateHandler(t *testing.T) {
+	label := "Офис"
+	tests := []struct {
+		name       string
+		body       string
+		wantPatch  *place.Patch
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "label only keeps tags",
+			body:       `{"label": "Офис"}`,
+			wantPatch:  &place.Patch{Label: &label},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "empty tags remove them",
+			body:       `{"tags": []}`,
+			wantPatch:  &place.Patch{Tags: []string{}},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "new address source",
+			body:       `{"search": "Снежная"}`,
+			wantPatch:  &place.Patch{Source: &place.Source{Search: "Снежная"}},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "empty label",
+			body:       `{"label": ""}`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			body:       `{"label": "Офис"}`,
+			wantPatch:  &place.Patch{Label: &label},
+			mockError:  place.ErrNotFound,
+			respStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			if tt.wantPatch != nil {
+				var res *place.Place
+				if tt.mockError == nil {
+					res = &place.Place{ID: "1", Label: label, Tags: []string{}}
+				}
+				useCaseMock.On("Update", ctxMock, owner, "1", *tt.wantPatch).Return(res, tt.mockError).Once()
+			}
+			rr := httptest.NewRecorder()
+
+			controller.Update(rr, newRequest(t, http.MethodPatch, tt.body, owner, "1"))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+		})
+	}
+}
+
+func TestPlacesDeleteHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "success",
+			respStatus: http.StatusNoContent,
+		},
+		{
+			name:       "not found",
+			mockError:  place.ErrNotFound,
+			respStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			useCaseMock.On("Delete", ctxMock, owner, "1").Return(tt.mockError).Once()
+			rr := httptest.NewRecorder()
+
+			controller.Delete(rr, newRequest(t, http.MethodDelete, "", owner, "1"))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+		})
+	}
+}
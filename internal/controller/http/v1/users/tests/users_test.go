@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"geo/internal/app"
+	usersController "geo/internal/controller/http/v1/users"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/service/auth"
+	"geo/internal/service/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+const admin = "root"
+
+func newController(t *testing.T) (*usersController.Users, *mocks.Roles) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	decoder := godecoder.NewDecoder(jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	})
+	useCaseMock := mocks.NewRoles(t)
+	return usersController.New(log, app.RequestIdKey, useCaseMock, responder.NewResponder(decoder, log)), useCaseMock
+}
+
+// newRequest returns a request authenticated as the admin with the login and role url parameters set.
+func newRequest(t *testing.T, method, login, role string) *http.Request {
+	req, err := http.NewRequest(method, "/api/admin/users/"+login+"/roles/"+role, nil)
+	require.NoError(t, err)
+
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	token, _, err := ja.Encode(map[string]interface{}{
+		"sub": admin, "jti": "1", "exp": time.Now().Add(time.Hour), "roles": []string{auth.RoleAdmin},
+	})
+	require.NoError(t, err)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("login", login)
+	rctx.URLParams.Add("role", role)
+	ctx := context.WithValue(req.Context(), middleware.RequestIDKey, "1")
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	ctx = jwtauth.NewContext(ctx, token, nil)
+	return req.WithContext(ctx)
+}
+
+var ctxMock = mock.MatchedBy(func(c context.Context) bool {
+	return c.Value(app.RequestIdKey) == "1"
+})
+
+func TestUsersRolesHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockResult []string
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "success",
+			mockResult: []string{auth.RoleUser},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "user not found",
+			mockError:  auth.ErrUserNotFound,
+			respStatus: http.StatusNotFound,
+		},
+		{
+			name:       "use case error",
+			mockError:  auth.ErrInternal,
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			useCaseMock.On("Roles", ctxMock, "alice").Return(tt.mockResult, tt.mockError).Once()
+			rr := httptest.NewRecorder()
+
+			controller.Roles(rr, newRequest(t, http.MethodGet, "alice", ""))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.mockResult != nil {
+				var res usersController.RolesResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, usersController.RolesResponse{Login: "alice", Roles: tt.mockResult}, res)
+			}
+		})
+	}
+}
+
+func TestUsersGrantRoleHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		mockResult []string
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "custom role",
+			role:       "auditor",
+			mockResult: []string{"auditor", auth.RoleUser},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid role",
+			role:       "Auditor",
+			mockError:  auth.ErrInvalidRole,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "user not found",
+			role:       auth.RoleAdmin,
+			mockError:  auth.ErrUserNotFound,
+			respStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			useCaseMock.On("GrantRole", ctxMock, "alice", tt.role).Return(tt.mockResult, tt.mockError).Once()
+			rr := httptest.NewRecorder()
+
+			controller.GrantRole(rr, newRequest(t, http.MethodPut, "alice", tt.role))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+			if tt.mockResult != nil {
+				var res usersController.RolesResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				require.Equal(t, tt.mockResult, res.Roles)
+			}
+		})
+	}
+}
+
+func TestUsersRevokeRoleHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		login      string
+		role       string
+		mockResult []string
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "success",
+			login:      "alice",
+			role:       auth.RoleAdmin,
+			mockResult: []string{auth.RoleUser},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "own admin role",
+			login:      admin,
+			role:       auth.RoleAdmin,
+			mockError:  auth.ErrRevokeOwnAdmin,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "user not found",
+			login:      "alice",
+			role:       auth.RoleAdmin,
+			mockError:  auth.ErrUserNotFound,
+			respStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, useCaseMock := newController(t)
+			useCaseMock.On("RevokeRole", ctxMock, admin, tt.login, tt.role).Return(tt.mockResult, tt.mockError).Once()
+			rr := httptest.NewRecorder()
+
+			controller.RevokeRole(rr, newRequest(t, http.MethodDelete, tt.login, tt.role))
+
+			require.Equal(t, tt.respStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"geo/internal/infrastructure/responder"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service"
+	"geo/internal/service/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"log/slog"
+	"net/http"
+)
+
+type RoleManager interface {
+	Roles(http.ResponseWriter, *http.Request)
+	GrantRole(http.ResponseWriter, *http.Request)
+	RevokeRole(http.ResponseWriter, *http.Request)
+}
+
+type Users struct {
+	log          *slog.Logger
+	requestIdKey string
+	uc           service.Roles
+	responder    responder.Responder
+}
+
+func New(log *slog.Logger, requestIdKey string, uc service.Roles, responder responder.Responder) *Users {
+	return &Users{log: log, requestIdKey: requestIdKey, uc: uc, responder: responder}
+}
+
+type RolesResponse struct {
+	Login string   `json:"login" example:"admin"`
+	Roles []string `json:"roles" example:"user,admin"`
+} //@name UserRoles
+
+// @Summary		Roles of a user
+// @Tags			admin
+// @Param			login	path		string	true	"user login"
+// @Success		200		{object}	RolesResponse
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403		"Forbidden: the token lacks the admin role"
+// @Failure		404		{object}	response.ErrResponse	"user not found"
+// @Failure		500		{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/admin/users/{login}/roles [get]
+func (u *Users) Roles(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.users.Roles"
+	log := u.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	login := chi.URLParam(r, "login")
+
+	ctx := context.WithValue(r.Context(), u.requestIdKey, middleware.GetReqID(r.Context()))
+	roles, err := u.uc.Roles(ctx, login)
+	if err != nil {
+		u.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.String("login", login), slog.Any("roles", roles))
+	u.responder.OutputJSON(w, RolesResponse{Login: login, Roles: roles})
+}
+
+// @Summary		Grant a role to a user
+// @Description	Roles are the built-in user and admin or custom ones. The user gets the role in the tokens issued from now on.
+// @Tags			admin
+// @Param			login	path		string	true	"user login"
+// @Param			role	path		string	true	"role name"
+// @Success		200		{object}	RolesResponse
+// @Failure		400		{object}	response.ErrResponse	"invalid role name"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403		"Forbidden: the token lacks the admin role"
+// @Failure		404		{object}	response.ErrResponse	"user not found"
+// @Failure		500		{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/admin/users/{login}/roles/{role} [put]
+func (u *Users) GrantRole(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.users.GrantRole"
+	log := u.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	login, role := chi.URLParam(r, "login"), chi.URLParam(r, "role")
+	log.Info("request received", slog.String("login", login), slog.String("role", role))
+
+	ctx := context.WithValue(r.Context(), u.requestIdKey, middleware.GetReqID(r.Context()))
+	roles, err := u.uc.GrantRole(ctx, login, role)
+	if err != nil {
+		u.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.Any("roles", roles))
+	u.responder.OutputJSON(w, RolesResponse{Login: login, Roles: roles})
+}
+
+// @Summary		Revoke a role from a user
+// @Description	The tokens issued before keep the role until they expire. Admins cannot revoke their own admin role.
+// @Tags			admin
+// @Param			login	path		string	true	"user login"
+// @Param			role	path		string	true	"role name"
+// @Success		200		{object}	RolesResponse
+// @Failure		400		{object}	response.ErrResponse	"invalid role name, own admin role"
+//
+// @Failure		401		"Unauthorized: Token missing or invalid"
+// @Header			401		{string}	WWW-Authenticate	"Bearer"
+//
+// @Failure		403		"Forbidden: the token lacks the admin role"
+// @Failure		404		{object}	response.ErrResponse	"user not found"
+// @Failure		500		{object}	response.ErrResponse
+// @Security		ApiKeyAuth
+// @Router			/admin/users/{login}/roles/{role} [delete]
+func (u *Users) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.users.RevokeRole"
+	log := u.log.With(
+		slog.String("op", op),
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+	)
+	login, role := chi.URLParam(r, "login"), chi.URLParam(r, "role")
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	actor, _ := claims["sub"].(string)
+	log.Info("request received", slog.String("login", login), slog.String("role", role))
+
+	ctx := context.WithValue(r.Context(), u.requestIdKey, middleware.GetReqID(r.Context()))
+	roles, err := u.uc.RevokeRole(ctx, actor, login, role)
+	if err != nil {
+		u.error(w, log, err)
+		return
+	}
+
+	log.Info("request executed", slog.Any("roles", roles))
+	u.responder.OutputJSON(w, RolesResponse{Login: login, Roles: roles})
+}
+
+func (u *Users) error(w http.ResponseWriter, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		log.Info("user not found", sl.Err(err))
+		u.responder.ErrorNotFound(w, err)
+	case errors.Is(err, auth.ErrInvalidRole), errors.Is(err, auth.ErrRevokeOwnAdmin):
+		log.Error("invalid role request", sl.Err(err))
+		u.responder.ErrorBadRequest(w, err)
+	default:
+		log.Error("failed to handle roles", sl.Err(err))
+		u.responder.ErrorInternal(w, err)
+	}
+}
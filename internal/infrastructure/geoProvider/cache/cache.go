@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Settings struct {
+	// Size is the maximum number of cached responses
+	Size int
+	// TTL is the time a response is served from the cache
+	TTL time.Duration
+	// Precision is the number of decimal places geocode coordinates are rounded to
+	// before lookup, so that nearby points share one entry
+	Precision int
+	// StaleGrace is the time after expiration an entry is still served
+	// when the provider is unavailable
+	StaleGrace time.Duration
+	// StaleWhileRevalidate makes entries within the grace window be served
+	// at once while they are refreshed in the background
+	StaleWhileRevalidate bool
+	// RevalidateTimeout limits a background refresh
+	RevalidateTimeout time.Duration
+}
+
+// Cache is a geo provider decorator keeping the latest responses of the wrapped provider in memory.
+type Cache struct {
+	next     geo.Provider
+	settings Settings
+	now      func() time.Time
+
+	mu           sync.Mutex
+	items        *lru
+	revalidating map[string]struct{}
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	stale  atomic.Uint64
+}
+
+func New(next geo.Provider, settings Settings) *Cache {
+	if settings.Size <= 0 {
+		settings.Size = 1
+	}
+	if settings.Precision < 0 {
+		settings.Precision = 0
+	}
+	return &Cache{
+		next:         next,
+		settings:     settings,
+		now:          time.Now,
+		items:        newLRU(settings.Size),
+		revalidating: make(map[string]struct{}),
+	}
+}
+
+func (c *Cache) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	return c.cached(ctx, searchKey(input, opts), func(ctx context.Context) ([]*geo.Address, error) {
+		return c.next.AddressSearch(ctx, input, opts)
+	})
+}
+
+func (c *Cache) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	return c.cached(ctx, geocodeKey(point, opts, c.settings.Precision), func(ctx context.Context) ([]*geo.Address, error) {
+		return c.next.AddressGeoCode(ctx, point, opts)
+	})
+}
+
+func (c *Cache) cached(ctx context.Context, key string,
+	load func(ctx context.Context) ([]*geo.Address, error)) ([]*geo.Address, error) {
+	now := c.now()
+	c.mu.Lock()
+	it, ok := c.items.get(key)
+	if ok && !now.Before(it.expiresAt.Add(c.settings.StaleGrace)) {
+		c.items.delete(key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	switch {
+	case ok && now.Before(it.expiresAt):
+		c.hits.Add(1)
+		return clone(it.addresses), nil
+	case ok && c.settings.StaleWhileRevalidate:
+		c.revalidate(ctx, key, load)
+		return c.serveStale(ctx, it), nil
+	}
+	c.misses.Add(1)
+
+	addresses, stale, err := tracked(ctx, load)
+	if ok && errors.Is(err, provider.ErrUnavailable) {
+		return c.serveStale(ctx, it), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stale {
+		// an expired entry of an inner cache must not get a fresh TTL here
+		geo.MarkStale(ctx)
+		return addresses, nil
+	}
+	c.store(key, addresses)
+	return addresses, nil
+}
+
+// tracked calls load with its own freshness, so that a stale result
+// of a wrapped cache can be told apart from a fresh one.
+func tracked(ctx context.Context, load func(ctx context.Context) ([]*geo.Address, error)) ([]*geo.Address, bool, error) {
+	ctx, freshness := geo.WithFreshness(ctx)
+	addresses, err := load(ctx)
+	return addresses, freshness.Stale(), err
+}
+
+func (c *Cache) serveStale(ctx context.Context, it *item) []*geo.Address {
+	c.stale.Add(1)
+	geo.MarkStale(ctx)
+	return clone(it.addresses)
+}
+
+// revalidate refreshes the entry in the background unless a refresh is already running.
+func (c *Cache) revalidate(ctx context.Context, key string, load func(ctx context.Context) ([]*geo.Address, error)) {
+	c.mu.Lock()
+	if _, ok := c.revalidating[key]; ok {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[key] = struct{}{}
+	c.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, key)
+			c.mu.Unlock()
+		}()
+		if c.settings.RevalidateTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.settings.RevalidateTimeout)
+			defer cancel()
+		}
+		if addresses, stale, err := tracked(ctx, load); err == nil && !stale {
+			c.store(key, addresses)
+		}
+	}()
+}
+
+func (c *Cache) store(key string, addresses []*geo.Address) {
+	c.mu.Lock()
+	c.items.put(&item{key: key, addresses: clone(addresses), expiresAt: c.now().Add(c.settings.TTL)})
+	c.mu.Unlock()
+}
+
+// Purge drops every cached response, it never fails.
+func (c *Cache) Purge() error {
+	c.mu.Lock()
+	c.items.clear()
+	c.mu.Unlock()
+	return nil
+}
+
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Stale  uint64 `json:"stale"`
+	Size   int    `json:"size"`
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	size := c.items.len()
+	c.mu.Unlock()
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Stale:  c.stale.Load(),
+		Size:   size,
+	}
+}
+
+func (c *Cache) Name() string {
+	return "cache"
+}
+
+// Status reports the cache counters, the cache itself is always healthy.
+func (c *Cache) Status() (bool, interface{}) {
+	return true, c.Stats()
+}
+
+// searchKey makes queries differing only in case and whitespace share one entry,
+// options are appended only when set so that default requests keep their keys.
+func searchKey(input string, opts geo.SearchOptions) string {
+	key := "search:" + strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	if !opts.IsZero() {
+		// the options hold only strings and numbers, so encoding cannot fail
+		encoded, _ := json.Marshal(opts)
+		key += ";" + string(encoded)
+	}
+	return key
+}
+
+// geocodeKey rounds the coordinates to precision decimal places,
+// options are appended only when set so that default requests keep their keys.
+func geocodeKey(point geo.Point, opts geo.GeocodeOptions, precision int) string {
+	key := "geocode:" + quantize(point.Lat, precision) + "," + quantize(point.Lon, precision)
+	if !opts.IsZero() {
+		key += ";r=" + strconv.Itoa(opts.RadiusMeters) + ";n=" + strconv.Itoa(opts.Count) +
+			";l=" + strings.ToLower(opts.Language)
+	}
+	return key
+}
+
+func quantize(x float64, precision int) string {
+	p := math.Pow10(precision)
+	x = math.Round(x*p) / p
+	// avoid distinct keys for 0 and -0
+	if x == 0 {
+		x = 0
+	}
+	return strconv.FormatFloat(x, 'f', precision, 64)
+}
+
+func clone(addresses []*geo.Address) []*geo.Address {
+	if addresses == nil {
+		return nil
+	}
+	res := make([]*geo.Address, len(addresses))
+	for i, a := range addresses {
+		c := *a
+		res[i] = &c
+	}
+	return res
+}
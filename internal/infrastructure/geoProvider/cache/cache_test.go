@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []*geo.Address{{City: input}}, nil
+}
+
+func (p *countingProvider) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []*geo.Address{{Lat: point.LatString(), Lon: point.LonString()}}, nil
+}
+
+func TestCache_AddressSearch(t *testing.T) {
+	next := &countingProvider{}
+	c := New(next, Settings{Size: 2, TTL: time.Minute, Precision: 3})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		input     string
+		opts      geo.SearchOptions
+		advance   time.Duration
+		upstream  error
+		want      []*geo.Address
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "miss",
+			input:     "г Москва, ул Снежная",
+			want:      []*geo.Address{{City: "г Москва, ул Снежная"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "normalized hit",
+			input:     "  г МОСКВА,   ул  снежная ",
+			want:      []*geo.Address{{City: "г Москва, ул Снежная"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "errors are not cached",
+			input:     "Тверь",
+			upstream:  provider.ErrUnavailable,
+			wantErr:   provider.ErrUnavailable,
+			wantCalls: 2,
+		},
+		{
+			name:      "second key",
+			input:     "Тверь",
+			want:      []*geo.Address{{City: "Тверь"}},
+			wantCalls: 3,
+		},
+		{
+			name:      "least recently used is evicted",
+			input:     "Казань",
+			want:      []*geo.Address{{City: "Казань"}},
+			wantCalls: 4,
+		},
+		{
+			name:      "evicted key is loaded again",
+			input:     "г Москва, ул Снежная",
+			want:      []*geo.Address{{City: "г Москва, ул Снежная"}},
+			wantCalls: 5,
+		},
+		{
+			name:      "expired key is loaded again",
+			input:     "г Москва, ул Снежная",
+			advance:   time.Minute,
+			want:      []*geo.Address{{City: "г Москва, ул Снежная"}},
+			wantCalls: 6,
+		},
+		{
+			name:      "options make a separate entry",
+			input:     "г Москва, ул Снежная",
+			opts:      geo.SearchOptions{Locations: []geo.Location{{City: "Москва"}}, Count: 5},
+			want:      []*geo.Address{{City: "г Москва, ул Снежная"}},
+			wantCalls: 7,
+		},
+		{
+			name:      "same options hit",
+			input:     "г москва, ул снежная",
+			opts:      geo.SearchOptions{Locations: []geo.Location{{City: "Москва"}}, Count: 5},
+			want:      []*geo.Address{{City: "г Москва, ул Снежная"}},
+			wantCalls: 7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			next.err = tt.upstream
+			got, err := c.AddressSearch(context.Background(), tt.input, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressSearch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressSearch() got = %v, want %v", got, tt.want)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", next.calls, tt.wantCalls)
+			}
+		})
+	}
+
+	if got, want := c.Stats(), (Stats{Hits: 2, Misses: 7, Size: 2}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCache_AddressGeoCode(t *testing.T) {
+	next := &countingProvider{}
+	c := New(next, Settings{Size: 10, TTL: time.Minute, Precision: 3})
+
+	tests := []struct {
+		name      string
+		point     geo.Point
+		opts      geo.GeocodeOptions
+		wantCalls int
+	}{
+		{
+			name:      "miss",
+			point:     geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			wantCalls: 1,
+		},
+		{
+			name:      "nearby point shares the entry",
+			point:     geo.Point{Lat: 55.8483, Lon: 37.6411},
+			wantCalls: 1,
+		},
+		{
+			name:      "distant point",
+			point:     geo.Point{Lat: 55.8493, Lon: 37.6414907},
+			wantCalls: 2,
+		},
+		{
+			name:      "options make a separate entry",
+			point:     geo.Point{Lat: 55.8493, Lon: 37.6414907},
+			opts:      geo.GeocodeOptions{Count: 5, Language: "en"},
+			wantCalls: 3,
+		},
+		{
+			name:      "language case is ignored",
+			point:     geo.Point{Lat: 55.8493, Lon: 37.6414907},
+			opts:      geo.GeocodeOptions{Count: 5, Language: "EN"},
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.AddressGeoCode(context.Background(), tt.point, tt.opts); err != nil {
+				t.Errorf("AddressGeoCode() error = %v", err)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", next.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_quantize(t *testing.T) {
+	tests := []struct {
+		x         float64
+		precision int
+		want      string
+	}{
+		{x: 55.8481373, precision: 4, want: "55.8481"},
+		{x: 55.84815, precision: 4, want: "55.8482"},
+		{x: -0.00001, precision: 3, want: "0.000"},
+		{x: 37.6414907, precision: 0, want: "38"},
+	}
+	for _, tt := range tests {
+		if got := quantize(tt.x, tt.precision); got != tt.want {
+			t.Errorf("quantize(%v, %d) = %v, want %v", tt.x, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestCache_ServeStaleOnError(t *testing.T) {
+	next := &countingProvider{}
+	c := New(next, Settings{Size: 10, TTL: time.Minute, StaleGrace: 10 * time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	errOther := errors.New("malformed response")
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		upstream  error
+		want      []*geo.Address
+		wantErr   error
+		wantStale bool
+	}{
+		{
+			name: "fresh",
+			want: []*geo.Address{{City: "Москва"}},
+		},
+		{
+			name:      "expired entry served when provider is unavailable",
+			advance:   2 * time.Minute,
+			upstream:  provider.ErrUnavailable,
+			want:      []*geo.Address{{City: "Москва"}},
+			wantStale: true,
+		},
+		{
+			name:     "other errors are returned",
+			upstream: errOther,
+			wantErr:  errOther,
+		},
+		{
+			name:     "entry beyond grace window is dropped",
+			advance:  10 * time.Minute,
+			upstream: provider.ErrUnavailable,
+			wantErr:  provider.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			next.err = tt.upstream
+			ctx, freshness := geo.WithFreshness(context.Background())
+
+			got, err := c.AddressSearch(ctx, "Москва", geo.SearchOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressSearch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressSearch() got = %v, want %v", got, tt.want)
+			}
+			if freshness.Stale() != tt.wantStale {
+				t.Errorf("Stale() = %v, want %v", freshness.Stale(), tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	next := &countingProvider{}
+	c := New(next, Settings{Size: 10, TTL: time.Minute, StaleGrace: time.Hour, StaleWhileRevalidate: true})
+	var mu sync.Mutex
+	now := time.Now()
+	c.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	if _, err := c.AddressSearch(context.Background(), "Москва", geo.SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	now = now.Add(2 * time.Minute)
+	mu.Unlock()
+
+	ctx, freshness := geo.WithFreshness(context.Background())
+	if _, err := c.AddressSearch(ctx, "Москва", geo.SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !freshness.Stale() {
+		t.Errorf("expired entry must be served as stale")
+	}
+
+	// the background refresh makes the entry fresh again
+	require.Eventually(t, func() bool {
+		ctx, freshness := geo.WithFreshness(context.Background())
+		_, err := c.AddressSearch(ctx, "Москва", geo.SearchOptions{})
+		return err == nil && !freshness.Stale()
+	}, time.Second, 10*time.Millisecond)
+	if got := c.Stats().Misses; got != 1 {
+		t.Errorf("Stats().Misses = %d, want 1", got)
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"container/list"
+	"geo/internal/service/geo"
+	"time"
+)
+
+type item struct {
+	key       string
+	addresses []*geo.Address
+	expiresAt time.Time
+}
+
+// lru is a size-bounded map evicting the least recently used items, it is not safe for concurrent use.
+type lru struct {
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (l *lru) get(key string) (*item, bool) {
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(e)
+	return e.Value.(*item), true
+}
+
+func (l *lru) put(it *item) {
+	if e, ok := l.items[it.key]; ok {
+		e.Value = it
+		l.order.MoveToFront(e)
+		return
+	}
+	l.items[it.key] = l.order.PushFront(it)
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *lru) delete(key string) {
+	if e, ok := l.items[key]; ok {
+		l.remove(e)
+	}
+}
+
+func (l *lru) remove(e *list.Element) {
+	l.order.Remove(e)
+	delete(l.items, e.Value.(*item).key)
+}
+
+func (l *lru) len() int {
+	return l.order.Len()
+}
+
+func (l *lru) clear() {
+	l.order.Init()
+	l.items = make(map[string]*list.Element, l.size)
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"geo/db/geoCache"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service/geo"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Store keeps encoded responses until they expire.
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte, expiresAt time.Time) error
+	Purge() error
+	Len() int
+}
+
+type PersistentSettings struct {
+	// TTL is the time a response is served from the store
+	TTL time.Duration
+	// Precision is the number of decimal places geocode coordinates are rounded to
+	Precision int
+	// StaleGrace is the time after expiration an entry is kept and served
+	// when the provider is unavailable
+	StaleGrace time.Duration
+}
+
+// Persistent is a geo provider decorator keeping the responses of the wrapped provider
+// in a store that survives restarts.
+type Persistent struct {
+	log      *slog.Logger
+	next     geo.Provider
+	store    Store
+	settings PersistentSettings
+	now      func() time.Time
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	stale  atomic.Uint64
+}
+
+// entry is the stored form of a response
+type entry struct {
+	ExpiresAt time.Time      `json:"expires_at"`
+	Addresses []*geo.Address `json:"addresses"`
+}
+
+func NewPersistent(log *slog.Logger, next geo.Provider, store Store, settings PersistentSettings) *Persistent {
+	if settings.Precision < 0 {
+		settings.Precision = 0
+	}
+	return &Persistent{
+		log:      log,
+		next:     next,
+		store:    store,
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+func (p *Persistent) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	return p.cached(ctx, searchKey(input, opts), func(ctx context.Context) ([]*geo.Address, error) {
+		return p.next.AddressSearch(ctx, input, opts)
+	})
+}
+
+func (p *Persistent) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	return p.cached(ctx, geocodeKey(point, opts, p.settings.Precision), func(ctx context.Context) ([]*geo.Address, error) {
+		return p.next.AddressGeoCode(ctx, point, opts)
+	})
+}
+
+func (p *Persistent) cached(ctx context.Context, key string,
+	load func(ctx context.Context) ([]*geo.Address, error)) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.cache.Persistent.cached"
+	log := p.log.With(slog.String("op", op))
+
+	e, ok := p.get(log, key)
+	if ok && p.now().Before(e.ExpiresAt) {
+		p.hits.Add(1)
+		return e.Addresses, nil
+	}
+	p.misses.Add(1)
+
+	addresses, err := load(ctx)
+	if ok && errors.Is(err, provider.ErrUnavailable) {
+		p.stale.Add(1)
+		geo.MarkStale(ctx)
+		return e.Addresses, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := p.now().Add(p.settings.TTL)
+	value, err := json.Marshal(entry{ExpiresAt: expiresAt, Addresses: addresses})
+	if err == nil {
+		err = p.store.Put(key, value, expiresAt.Add(p.settings.StaleGrace))
+	}
+	if err != nil {
+		log.Error("failed to store response", sl.Err(err))
+	}
+	return addresses, nil
+}
+
+// get returns the stored entry, store failures are logged and treated as a miss.
+func (p *Persistent) get(log *slog.Logger, key string) (*entry, bool) {
+	value, err := p.store.Get(key)
+	if errors.Is(err, geoCache.NotFound) {
+		return nil, false
+	}
+	if err != nil {
+		log.Error("failed to read stored response", sl.Err(err))
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(value, &e); err != nil {
+		log.Error("failed to decode stored response", sl.Err(err))
+		return nil, false
+	}
+	return &e, true
+}
+
+// Purge drops every stored response.
+func (p *Persistent) Purge() error {
+	return p.store.Purge()
+}
+
+func (p *Persistent) Stats() Stats {
+	return Stats{
+		Hits:   p.hits.Load(),
+		Misses: p.misses.Load(),
+		Stale:  p.stale.Load(),
+		Size:   p.store.Len(),
+	}
+}
+
+func (p *Persistent) Name() string {
+	return "persistent_cache"
+}
+
+// Status reports the store counters, the store itself is always healthy.
+func (p *Persistent) Status() (bool, interface{}) {
+	return true, p.Stats()
+}
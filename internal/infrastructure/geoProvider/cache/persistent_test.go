@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"geo/db/geoCache"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type storedValue struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type mapStore struct {
+	now   func() time.Time
+	items map[string]storedValue
+}
+
+func (s *mapStore) Get(key string) ([]byte, error) {
+	v, ok := s.items[key]
+	if !ok || !s.now().Before(v.expiresAt) {
+		return nil, geoCache.NotFound
+	}
+	return v.value, nil
+}
+
+func (s *mapStore) Put(key string, value []byte, expiresAt time.Time) error {
+	s.items[key] = storedValue{value: value, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *mapStore) Purge() error {
+	clear(s.items)
+	return nil
+}
+
+func (s *mapStore) Len() int {
+	return len(s.items)
+}
+
+func TestPersistent(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := &mapStore{now: clock, items: make(map[string]storedValue)}
+	next := &countingProvider{}
+	p := NewPersistent(log, next, store, PersistentSettings{TTL: time.Minute, Precision: 3, StaleGrace: 10 * time.Minute})
+	p.now = clock
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		upstream  error
+		purge     bool
+		want      []*geo.Address
+		wantErr   error
+		wantStale bool
+		wantCalls int
+	}{
+		{
+			name:      "miss",
+			want:      []*geo.Address{{Lat: "55.8481373", Lon: "37.6414907"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "hit",
+			want:      []*geo.Address{{Lat: "55.8481373", Lon: "37.6414907"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "expired entry served when provider is unavailable",
+			advance:   2 * time.Minute,
+			upstream:  provider.ErrUnavailable,
+			want:      []*geo.Address{{Lat: "55.8481373", Lon: "37.6414907"}},
+			wantStale: true,
+			wantCalls: 2,
+		},
+		{
+			name:      "entry beyond grace window is dropped",
+			advance:   10 * time.Minute,
+			upstream:  provider.ErrUnavailable,
+			wantErr:   provider.ErrUnavailable,
+			wantCalls: 3,
+		},
+		{
+			name:      "refreshed",
+			want:      []*geo.Address{{Lat: "55.8481373", Lon: "37.6414907"}},
+			wantCalls: 4,
+		},
+		{
+			name:      "purged",
+			purge:     true,
+			upstream:  provider.ErrUnavailable,
+			wantErr:   provider.ErrUnavailable,
+			wantCalls: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			next.err = tt.upstream
+			if tt.purge {
+				if err := p.Purge(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctx, freshness := geo.WithFreshness(context.Background())
+
+			got, err := p.AddressGeoCode(ctx, geo.Point{Lat: 55.8481373, Lon: 37.6414907}, geo.GeocodeOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressGeoCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressGeoCode() got = %v, want %v", got, tt.want)
+			}
+			if freshness.Stale() != tt.wantStale {
+				t.Errorf("Stale() = %v, want %v", freshness.Stale(), tt.wantStale)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", next.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCache_OverPersistent(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := &mapStore{now: clock, items: make(map[string]storedValue)}
+	next := &countingProvider{}
+	p := NewPersistent(log, next, store, PersistentSettings{TTL: 5 * time.Minute, StaleGrace: time.Hour})
+	p.now = clock
+	c := New(p, Settings{Size: 10, TTL: time.Minute})
+	c.now = clock
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		upstream  error
+		wantStale bool
+		wantCalls int
+	}{
+		{
+			name:      "miss",
+			wantCalls: 1,
+		},
+		{
+			name:      "stale persistent entry is served",
+			advance:   10 * time.Minute,
+			upstream:  provider.ErrUnavailable,
+			wantStale: true,
+			wantCalls: 2,
+		},
+		{
+			name:      "stale persistent entry is not stored in memory",
+			upstream:  provider.ErrUnavailable,
+			wantStale: true,
+			wantCalls: 3,
+		},
+		{
+			name:      "refreshed",
+			wantCalls: 4,
+		},
+		{
+			name:      "memory hit",
+			wantCalls: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			next.err = tt.upstream
+			ctx, freshness := geo.WithFreshness(context.Background())
+
+			got, err := c.AddressSearch(ctx, "Москва", geo.SearchOptions{})
+			if err != nil {
+				t.Fatalf("AddressSearch() error = %v", err)
+			}
+			if want := []*geo.Address{{City: "Москва"}}; !reflect.DeepEqual(got, want) {
+				t.Errorf("AddressSearch() got = %v, want %v", got, want)
+			}
+			if freshness.Stale() != tt.wantStale {
+				t.Errorf("Stale() = %v, want %v", freshness.Stale(), tt.wantStale)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", next.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package dadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/clean"
+	dadataClean "github.com/ekomobile/dadata/v2/api/clean"
+	"github.com/ekomobile/dadata/v2/client"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// newCleanStandIn returns a service whose clean API is served by handler.
+func newCleanStandIn(t *testing.T, secretKey string, handler http.HandlerFunc) *GeoService {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL + "/api/v1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGeoService("key", secretKey)
+	creds := client.Credentials{ApiKeyValue: "key", SecretKeyValue: secretKey}
+	g.cleaner = &dadataClean.Api{Client: client.NewClient(u, client.WithCredentialProvider(&creds))}
+	return g
+}
+
+func TestGeoService_CleanAddress(t *testing.T) {
+	const response = `[{
+		"source": "мск сухонская 11 89",
+		"result": "г Москва, ул Сухонская, д 11, кв 89",
+		"postal_code": "127642",
+		"country": "Россия",
+		"region": "Москва",
+		"city": "Москва",
+		"city_district": "Северное Медведково",
+		"street": "Сухонская",
+		"house": "11",
+		"flat": "89",
+		"fias_id": "f26b876b-6857-4951-b060-ec6559f04a9a",
+		"fias_level": "9",
+		"kladr_id": "7700000000028360004",
+		"okato": "45280583000",
+		"oktmo": "45362000",
+		"timezone": "UTC+3",
+		"geo_lat": "55.8782557",
+		"geo_lon": "37.65372",
+		"qc_geo": 0,
+		"qc_complete": 0,
+		"qc_house": 2,
+		"qc": 0,
+		"unparsed_parts": null
+	}]`
+
+	tests := []struct {
+		name      string
+		secretKey string
+		status    int
+		want      *clean.Address
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			secretKey: "secret",
+			status:    http.StatusOK,
+			want: &clean.Address{
+				Source: "мск сухонская 11 89",
+				Result: "г Москва, ул Сухонская, д 11, кв 89",
+				Components: clean.Components{
+					PostalCode:   "127642",
+					Country:      "Россия",
+					Region:       "Москва",
+					City:         "Москва",
+					CityDistrict: "Северное Медведково",
+					Street:       "Сухонская",
+					House:        "11",
+					Flat:         "89",
+					FiasID:       "f26b876b-6857-4951-b060-ec6559f04a9a",
+					FiasLevel:    "9",
+					KladrID:      "7700000000028360004",
+					Okato:        "45280583000",
+					Oktmo:        "45362000",
+					Timezone:     "UTC+3",
+				},
+				Lat:        "55.8782557",
+				Lon:        "37.65372",
+				Qc:         "0",
+				QcGeo:      "0",
+				QcComplete: "0",
+				QcHouse:    "2",
+			},
+		},
+		{
+			name:      "upstream error",
+			secretKey: "secret",
+			status:    http.StatusForbidden,
+			wantErr:   provider.ErrUnavailable,
+		},
+		{
+			name:    "no secret key",
+			wantErr: provider.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newCleanStandIn(t, tt.secretKey, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/clean/address" {
+					t.Errorf("path = %q", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Token key" || r.Header.Get("X-Secret") != tt.secretKey {
+					t.Errorf("credentials not sent: %v", r.Header)
+				}
+				var body []string
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !reflect.DeepEqual(body, []string{"мск сухонская 11 89"}) {
+					t.Errorf("body = %v, error %v", body, err)
+				}
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					_, _ = w.Write([]byte(response))
+				}
+			})
+
+			got, err := g.CleanAddress(context.Background(), "мск сухонская 11 89")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CleanAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CleanAddress() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
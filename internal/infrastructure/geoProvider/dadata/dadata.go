@@ -1,27 +1,45 @@
 package dadata
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/clean"
 	"geo/internal/service/geo"
+	dadataClean "github.com/ekomobile/dadata/v2/api/clean"
+	"github.com/ekomobile/dadata/v2/api/model"
 	"github.com/ekomobile/dadata/v2/api/suggest"
 	"github.com/ekomobile/dadata/v2/client"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
+const (
+	suggestURL = "https://suggestions.dadata.ru/suggestions/api/4_1/rs/"
+	cleanerURL = "https://cleaner.dadata.ru/api/v1/"
+)
+
 type GeoService struct {
-	api       *suggest.Api
+	api *suggest.Api
+	// suggestURL is the base of the suggestions API, geolocate is called without the client library
+	suggestURL *url.URL
+	// cleaner needs the secret key besides the api key
+	cleaner   *dadataClean.Api
 	apiKey    string
 	secretKey string
 }
 
 func NewGeoService(apiKey, secretKey string) *GeoService {
-	var err error
-	endpointUrl, err := url.Parse("https://suggestions.dadata.ru/suggestions/api/4_1/rs/")
+	return newGeoService(suggestURL, cleanerURL, apiKey, secretKey)
+}
+
+// newGeoService creates a client of the APIs located at the given base URLs.
+func newGeoService(suggestBaseURL, cleanerBaseURL, apiKey, secretKey string) *GeoService {
+	endpointUrl, err := url.Parse(suggestBaseURL)
 	if err != nil {
 		return nil
 	}
@@ -34,37 +52,115 @@ func NewGeoService(apiKey, secretKey string) *GeoService {
 	api := suggest.Api{
 		Client: client.NewClient(endpointUrl, client.WithCredentialProvider(&creds)),
 	}
+	cleanerUrl, err := url.Parse(cleanerBaseURL)
+	if err != nil {
+		return nil
+	}
 
 	return &GeoService{
-		api:       &api,
-		apiKey:    apiKey,
-		secretKey: secretKey,
+		api:        &api,
+		suggestURL: endpointUrl,
+		cleaner:    &dadataClean.Api{Client: client.NewClient(cleanerUrl, client.WithCredentialProvider(&creds))},
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+	}
+}
+
+// suggestRequest adds the parameters the client library does not know to its request
+type suggestRequest struct {
+	suggest.RequestParams
+	LocationsBoost []*suggest.RequestParamsLocation `json:"locations_boost,omitempty"`
+}
+
+func newSuggestRequest(input string, opts geo.SearchOptions) *suggestRequest {
+	req := &suggestRequest{RequestParams: suggest.RequestParams{Query: input, Count: opts.Count}}
+	for _, l := range opts.Locations {
+		req.Locations = append(req.Locations, &suggest.RequestParamsLocation{
+			Country:    l.Country,
+			Region:     l.Region,
+			City:       l.City,
+			Settlement: l.Settlement,
+			Street:     l.Street,
+			FiasID:     l.FiasID,
+			KladrID:    l.KladrID,
+		})
+	}
+	for _, l := range opts.LocationsBoost {
+		req.LocationsBoost = append(req.LocationsBoost, &suggest.RequestParamsLocation{KladrID: l.KladrID})
+	}
+	if opts.FromBound != "" {
+		req.FromBound = &suggest.Bound{Value: model.BoundValue(opts.FromBound)}
 	}
+	if opts.ToBound != "" {
+		req.ToBound = &suggest.Bound{Value: model.BoundValue(opts.ToBound)}
+	}
+	return req
 }
 
-func (g *GeoService) AddressSearch(input string) ([]*geo.Address, error) {
+func (g *GeoService) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
 	const op = "lib.geoProvider.dadata.AddressSearch"
 	var res []*geo.Address
-	rawRes, err := g.api.Address(context.Background(), &suggest.RequestParams{Query: input})
+	var rawRes suggest.AddressResponse
+	err := g.api.Client.Post(ctx, "suggest/address", newSuggestRequest(input, opts), &rawRes)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("%s: %w", op, ctxErr)
+	}
 	if err != nil {
-		return nil, provider.ErrUnavailable
+		return nil, fmt.Errorf("%s: %w: %w", op, provider.ErrUnavailable, err)
 	}
 
-	for _, r := range rawRes {
-		if r.Data.City == "" || r.Data.Street == "" {
-			continue
-		}
-		res = append(res, &geo.Address{City: r.Data.City, Street: r.Data.Street, House: r.Data.House, Lat: r.Data.GeoLat, Lon: r.Data.GeoLon})
+	for _, r := range rawRes.Suggestions {
+		res = append(res, &geo.Address{
+			City:       r.Data.City,
+			Street:     r.Data.Street,
+			House:      r.Data.House,
+			Lat:        r.Data.GeoLat,
+			Lon:        r.Data.GeoLon,
+			Value:      r.Value,
+			PostalCode: r.Data.PostalCode,
+			Country:    r.Data.Country,
+			Region:     r.Data.Region,
+			Area:       r.Data.Area,
+			Settlement: r.Data.Settlement,
+			FiasID:     r.Data.FiasID,
+			KladrID:    r.Data.KladrID,
+			Okato:      r.Data.Okato,
+			Oktmo:      r.Data.Oktmo,
+			QcGeo:      qualityCode(r.Data.QualityCodeGeoRaw),
+			Timezone:   r.Data.Timezone,
+		})
 	}
 
 	return res, nil
 }
 
-func (g *GeoService) AddressGeoCode(lat, lng string) ([]*geo.Address, error) {
+type geolocateRequest struct {
+	Lat          float64 `json:"lat"`
+	Lon          float64 `json:"lon"`
+	RadiusMeters int     `json:"radius_meters,omitempty"`
+	Count        int     `json:"count,omitempty"`
+	Language     string  `json:"language,omitempty"`
+}
+
+func (g *GeoService) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
 	const op = "lib.geoProvider.dadata.AddressGeoCode"
+	language := strings.ToLower(opts.Language)
+	// the API answers only in russian and english
+	if language != "" && language != "ru" && language != "en" {
+		return nil, fmt.Errorf("%s: %w: language %q", op, provider.ErrUnsupportedOption, opts.Language)
+	}
 	httpClient := &http.Client{}
-	var data = strings.NewReader(fmt.Sprintf(`{"lat": %s, "lon": %s}`, lat, lng))
-	req, err := http.NewRequest("POST", "https://suggestions.dadata.ru/suggestions/api/4_1/rs/geolocate/address", data)
+	body, err := json.Marshal(geolocateRequest{
+		Lat:          point.Lat,
+		Lon:          point.Lon,
+		RadiusMeters: opts.RadiusMeters,
+		Count:        opts.Count,
+		Language:     language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", g.suggestURL.JoinPath("geolocate/address").String(), bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -72,8 +168,15 @@ func (g *GeoService) AddressGeoCode(lat, lng string) ([]*geo.Address, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Token %s", g.apiKey))
 	resp, err := httpClient.Do(req)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("%s: %w", op, ctxErr)
+	}
 	if err != nil {
-		return nil, provider.ErrUnavailable
+		return nil, fmt.Errorf("%s: %w: %w", op, provider.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s: %w: status %d", op, provider.ErrUnavailable, resp.StatusCode)
 	}
 	var geoCode Response
 
@@ -89,9 +192,82 @@ func (g *GeoService) AddressGeoCode(lat, lng string) ([]*geo.Address, error) {
 		address.House = r.Data.House
 		address.Lat = r.Data.GeoLat
 		address.Lon = r.Data.GeoLon
+		address.Value = r.Value
+		address.PostalCode = r.Data.PostalCode
+		address.Country = string(r.Data.Country)
+		address.Region = string(r.Data.Region)
+		address.FiasID = r.Data.FiasID
+		address.KladrID = r.Data.KladrID
+		address.Okato = r.Data.Okato
+		address.Oktmo = r.Data.Oktmo
+		address.QcGeo = r.Data.QcGeo
+		address.Timezone = r.Data.Timezone
 
 		res = append(res, &address)
 	}
 
 	return res, nil
 }
+
+func (g *GeoService) CleanAddress(ctx context.Context, source string) (*clean.Address, error) {
+	const op = "lib.geoProvider.dadata.CleanAddress"
+	if g.secretKey == "" {
+		return nil, fmt.Errorf("%s: %w: secret key is not set", op, provider.ErrUnavailable)
+	}
+	rawRes, err := g.cleaner.Address(ctx, source)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("%s: %w", op, ctxErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, provider.ErrUnavailable, err)
+	}
+	if len(rawRes) == 0 {
+		return nil, fmt.Errorf("%s: %w: empty response", op, provider.ErrUnavailable)
+	}
+
+	r := rawRes[0]
+	return &clean.Address{
+		Source: r.Source,
+		Result: r.Result,
+		Components: clean.Components{
+			PostalCode:   r.PostalCode,
+			Country:      r.Country,
+			Region:       r.Region,
+			Area:         r.Area,
+			City:         r.City,
+			CityDistrict: r.CityDistrict,
+			Settlement:   r.Settlement,
+			Street:       r.Street,
+			House:        r.House,
+			Block:        r.Block,
+			Flat:         r.Flat,
+			FiasID:       r.FiasID,
+			FiasLevel:    r.FiasLevel,
+			KladrID:      r.KladrID,
+			Okato:        r.Okato,
+			Oktmo:        r.Oktmo,
+			Timezone:     r.Timezone,
+		},
+		Lat:           r.GeoLat,
+		Lon:           r.GeoLon,
+		Qc:            qualityCode(r.QualityCodeRaw),
+		QcGeo:         qualityCode(r.QualityCodeGeoRaw),
+		QcComplete:    qualityCode(r.QualityCodeCompleteRaw),
+		QcHouse:       qualityCode(r.QualityCodeHouseRaw),
+		UnparsedParts: r.UnparsedParts,
+	}, nil
+}
+
+// qualityCode formats a quality code, the API sends it either as a string or as a number.
+func qualityCode(raw interface{}) string {
+	switch v := raw.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
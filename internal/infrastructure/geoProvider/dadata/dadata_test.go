@@ -1,15 +1,69 @@
 package dadata
 
 import (
-	"geo/internal/config"
+	"context"
+	"encoding/json"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/infrastructure/geoProvider/failover"
 	"geo/internal/service/geo"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
+	"time"
 )
 
+const apiKey = "key"
+
+// newStandIn returns a service whose suggestions API serves the recorded responses of testdata.
+func newStandIn(t *testing.T) *GeoService {
+	serve := func(w http.ResponseWriter, r *http.Request, file string) {
+		if r.Header.Get("Authorization") != "Token "+apiKey {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /suggestions/api/4_1/rs/suggest/address", func(w http.ResponseWriter, r *http.Request) {
+		var req suggestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Query != "г Москва, ул Снежная" {
+			w.Write([]byte(`{"suggestions":[]}`))
+			return
+		}
+		serve(w, r, "suggest_address.json")
+	})
+	mux.HandleFunc("POST /suggestions/api/4_1/rs/geolocate/address", func(w http.ResponseWriter, r *http.Request) {
+		var req geolocateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Lat != 55.8481373 || req.Lon != 37.6414907 {
+			w.Write([]byte(`{"suggestions":[]}`))
+			return
+		}
+		serve(w, r, "geolocate_address.json")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return newGeoService(srv.URL+"/suggestions/api/4_1/rs/", srv.URL+"/api/v1/", apiKey, "")
+}
+
 func TestGeoService_AddressSearch(t *testing.T) {
-	conf := config.MustLoadConfig("../../../../config/local.yaml")
-	geoService := NewGeoService(conf.Dadata.ApiKey, conf.Dadata.ApiSecret)
+	geoService := newStandIn(t)
 
 	type args struct {
 		input string
@@ -27,82 +81,53 @@ func TestGeoService_AddressSearch(t *testing.T) {
 			},
 			want: []*geo.Address{
 				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "",
-					Lat:    "55.852405",
-					Lon:    "37.646947",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "",
-					Lat:    "55.475475",
-					Lon:    "36.902316",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "",
-					Lat:    "55.520941",
-					Lon:    "37.307258",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "1",
-					Lat:    "55.849384",
-					Lon:    "37.64015",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "1А",
-					Lat:    "55.846724",
-					Lon:    "37.639545",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "3А",
-					Lat:    "55.8495825",
-					Lon:    "37.6409167",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "4",
-					Lat:    "55.8481373",
-					Lon:    "37.6414907",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "5",
-					Lat:    "55.849247",
-					Lon:    "37.641514",
-				},
-				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "6",
-					Lat:    "55.84864",
-					Lon:    "37.642159",
+					City:       "Москва",
+					Street:     "Снежная",
+					House:      "",
+					Lat:        "55.852405",
+					Lon:        "37.646947",
+					Value:      "г Москва, ул Снежная",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+					FiasID:     "4e5f3a4e-3a4a-4d0c-8b24-6e1c5a0f8d37",
+					KladrID:    "77000000000275000",
+					Okato:      "45280580000",
+					Oktmo:      "45358000",
+					QcGeo:      "2",
 				},
 				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "7",
-					Lat:    "55.84959",
-					Lon:    "37.642051",
+					City:       "Москва",
+					Street:     "Снежная",
+					House:      "4",
+					Lat:        "55.8481373",
+					Lon:        "37.6414907",
+					Value:      "г Москва, ул Снежная, д 4",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+					FiasID:     "7c0d4b4c-1b1e-4a52-9d1b-6b6e7f5c1a19",
+					KladrID:    "7700000000027500021",
+					Okato:      "45280580000",
+					Oktmo:      "45358000",
+					QcGeo:      "0",
+					Timezone:   "UTC+3",
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "no matches",
+			args: args{
+				input: "Атлантида",
+			},
+			want:    nil,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := geoService.AddressSearch(tt.args.input)
+			got, err := geoService.AddressSearch(context.Background(), tt.args.input, geo.SearchOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AddressSearch() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -115,47 +140,143 @@ func TestGeoService_AddressSearch(t *testing.T) {
 }
 
 func TestGeoService_AddressGeoCode(t *testing.T) {
-	conf := config.MustLoadConfig("../../../../config/local.yaml")
-	geoService := NewGeoService(conf.Dadata.ApiKey, conf.Dadata.ApiSecret)
+	geoService := newStandIn(t)
 
-	type args struct {
-		lat string
-		lng string
-	}
 	tests := []struct {
 		name    string
-		args    args
+		point   geo.Point
+		opts    geo.GeocodeOptions
 		want    []*geo.Address
 		wantErr bool
 	}{
 		{
-			name: "success",
-			args: args{
-				lat: "55.8481373",
-				lng: "37.6414907",
-			},
+			name:  "success",
+			point: geo.Point{Lat: 55.8481373, Lon: 37.6414907},
 			want: []*geo.Address{
 				{
-					City:   "Москва",
-					Street: "Снежная",
-					House:  "4",
-					Lat:    "55.8481373",
-					Lon:    "37.6414907",
+					City:       "Москва",
+					Street:     "Снежная",
+					House:      "4",
+					Lat:        "55.8481373",
+					Lon:        "37.6414907",
+					Value:      "г Москва, ул Снежная, д 4",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+					FiasID:     "7c0d4b4c-1b1e-4a52-9d1b-6b6e7f5c1a19",
+					KladrID:    "7700000000027500021",
+					Okato:      "45280580000",
+					Oktmo:      "45358000",
+					QcGeo:      "0",
+					Timezone:   "UTC+3",
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name:    "nothing around",
+			point:   geo.Point{Lat: 0, Lon: 0},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "unsupported language",
+			point:   geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:    geo.GeocodeOptions{Language: "de"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := geoService.AddressGeoCode(tt.args.lat, tt.args.lng)
+			got, err := geoService.AddressGeoCode(context.Background(), tt.point, tt.opts)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AddressGeoCode() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got[0], tt.want[0]) {
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("AddressGeoCode() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestGeoService_AddressGeoCodeFailover(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	down := newGeoService(srv.URL+"/suggestions/api/4_1/rs/", srv.URL+"/api/v1/", apiKey, "")
+	point := geo.Point{Lat: 55.8481373, Lon: 37.6414907}
+
+	_, err := down.AddressGeoCode(context.Background(), point, geo.GeocodeOptions{})
+	require.ErrorIs(t, err, provider.ErrUnavailable)
+
+	chain := failover.New(log, []failover.Member{
+		{Name: "down", Provider: down},
+		{Name: "up", Provider: newStandIn(t)},
+	}, failover.Settings{FailureThreshold: 1, CoolDown: time.Hour})
+	got, err := chain.AddressGeoCode(context.Background(), point, geo.GeocodeOptions{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "г Москва, ул Снежная, д 4", got[0].Value)
+	require.Equal(t, failover.StateOpen.String(), chain.States()[0].State)
+}
+
+func TestQualityCode(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  interface{}
+		want string
+	}{
+		{name: "string", raw: "0", want: "0"},
+		{name: "number", raw: float64(2), want: "2"},
+		{name: "missing", raw: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualityCode(tt.raw); got != tt.want {
+				t.Errorf("qualityCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSuggestRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		opts geo.SearchOptions
+		want string
+	}{
+		{
+			name: "no options",
+			want: `{"query":"Снежная","count":0,"locations":null,"restrict_value":false,"from_bound":null,"to_bound":null}`,
+		},
+		{
+			name: "streets of a city",
+			opts: geo.SearchOptions{
+				Locations:      []geo.Location{{City: "Москва"}},
+				LocationsBoost: []geo.BoostLocation{{KladrID: "7700000000000"}},
+				FromBound:      geo.GranularityStreet,
+				ToBound:        geo.GranularityStreet,
+				Count:          5,
+			},
+			want: `{"query":"Снежная","count":5,"locations":[{"city":"Москва"}],"restrict_value":false,` +
+				`"from_bound":{"value":"street"},"to_bound":{"value":"street"},` +
+				`"locations_boost":[{"kladr_id":"7700000000000"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(newSuggestRequest("Снежная", tt.opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("newSuggestRequest() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
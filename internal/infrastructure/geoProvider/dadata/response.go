@@ -106,7 +106,7 @@ type Data struct {
 	Oktmo                string          `json:"oktmo"`
 	TaxOffice            string          `json:"tax_office"`
 	TaxOfficeLegal       string          `json:"tax_office_legal"`
-	Timezone             interface{}     `json:"timezone"`
+	Timezone             string          `json:"timezone"`
 	GeoLat               string          `json:"geo_lat"`
 	GeoLon               string          `json:"geo_lon"`
 	BeltwayHit           interface{}     `json:"beltway_hit"`
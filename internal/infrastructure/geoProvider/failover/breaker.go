@@ -0,0 +1,192 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	ErrOpen = errors.New("circuit breaker is open")
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// CoolDown is the time the breaker stays open before letting probe calls through
+	CoolDown time.Duration
+	// HalfOpenProbes is the number of concurrent calls allowed in the half-open state
+	HalfOpenProbes int
+}
+
+// Breaker is a circuit breaker protecting a single provider.
+type Breaker struct {
+	log      *slog.Logger
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	failures   int
+	inFlight   int
+	changedAt  time.Time
+}
+
+func NewBreaker(log *slog.Logger, name string, settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 1
+	}
+	if settings.HalfOpenProbes <= 0 {
+		settings.HalfOpenProbes = 1
+	}
+	return &Breaker{
+		log: log.With(
+			slog.String("component", "geoProvider/failover"),
+			slog.String("provider", name),
+		),
+		name:      name,
+		settings:  settings,
+		now:       time.Now,
+		changedAt: time.Now(),
+	}
+}
+
+// Execute runs fn unless the breaker is open. An error returned by fn counts
+// as a failure unless ctx is done by then: the caller giving up says nothing
+// about the provider health.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	generation, ok := b.allow()
+	if !ok {
+		return ErrOpen
+	}
+	err := fn(ctx)
+	// rejected options say nothing about the provider health
+	b.report(generation, err, ctx.Err() != nil || errors.Is(err, provider.ErrUnsupportedOption))
+	return err
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+type BreakerState struct {
+	Provider string    `json:"provider"`
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	Since    time.Time `json:"since"`
+}
+
+func (b *Breaker) Snapshot() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return BreakerState{
+		Provider: b.name,
+		State:    b.state.String(),
+		Failures: b.failures,
+		Since:    b.changedAt,
+	}
+}
+
+func (b *Breaker) allow() (uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return b.generation, false
+	case StateHalfOpen:
+		if b.inFlight >= b.settings.HalfOpenProbes {
+			return b.generation, false
+		}
+		b.inFlight++
+	}
+	return b.generation, true
+}
+
+func (b *Breaker) report(generation uint64, err error, ignore bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// the state has changed while the call was running
+	if generation != b.generation {
+		return
+	}
+	if b.state == StateHalfOpen {
+		b.inFlight--
+	}
+	switch {
+	case ignore:
+		return
+	case err == nil:
+		b.failures = 0
+		if b.state == StateHalfOpen {
+			b.setState(StateClosed)
+		}
+	default:
+		b.failures++
+		if b.state == StateHalfOpen || b.failures >= b.settings.FailureThreshold {
+			b.setState(StateOpen)
+		}
+	}
+}
+
+// refresh moves an open breaker to the half-open state once the cool-down is over.
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && b.now().Sub(b.changedAt) >= b.settings.CoolDown {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	prev := b.state
+	b.state = state
+	b.generation++
+	b.inFlight = 0
+	b.changedAt = b.now()
+	if state == StateClosed {
+		b.failures = 0
+	}
+
+	log := b.log.With(
+		slog.String("from", prev.String()),
+		slog.String("to", state.String()),
+		slog.Int("failures", b.failures),
+	)
+	if state == StateOpen {
+		log.Warn("circuit breaker state changed")
+	} else {
+		log.Info("circuit breaker state changed")
+	}
+}
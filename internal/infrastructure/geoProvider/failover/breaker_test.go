@@ -0,0 +1,102 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+var errUpstream = errors.New("upstream failure")
+
+func TestBreaker_Execute(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	now := time.Now()
+	b := NewBreaker(log, "test", Settings{FailureThreshold: 2, CoolDown: time.Minute, HalfOpenProbes: 1})
+	b.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		callErr   error
+		wantErr   error
+		wantState State
+	}{
+		{
+			name:      "success keeps closed",
+			callErr:   nil,
+			wantErr:   nil,
+			wantState: StateClosed,
+		},
+		{
+			name:      "failure below threshold",
+			callErr:   errUpstream,
+			wantErr:   errUpstream,
+			wantState: StateClosed,
+		},
+		{
+			name:      "failure reaches threshold",
+			callErr:   errUpstream,
+			wantErr:   errUpstream,
+			wantState: StateOpen,
+		},
+		{
+			name:      "open rejects calls",
+			advance:   30 * time.Second,
+			callErr:   nil,
+			wantErr:   ErrOpen,
+			wantState: StateOpen,
+		},
+		{
+			name:      "failed probe opens again",
+			advance:   30 * time.Second,
+			callErr:   errUpstream,
+			wantErr:   errUpstream,
+			wantState: StateOpen,
+		},
+		{
+			name:      "successful probe closes",
+			advance:   time.Minute,
+			callErr:   nil,
+			wantErr:   nil,
+			wantState: StateClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			err := b.Execute(context.Background(), func(ctx context.Context) error {
+				return tt.callErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := b.State(); got != tt.wantState {
+				t.Errorf("State() = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestBreaker_IgnoresCancelledCalls(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	b := NewBreaker(log, "test", Settings{FailureThreshold: 1, CoolDown: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := b.Execute(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Execute() error = %v, wantErr %v", err, context.Canceled)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %v, want %v", got, StateClosed)
+	}
+}
@@ -0,0 +1,108 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"log/slog"
+)
+
+type Member struct {
+	Name     string
+	Provider geo.Provider
+}
+
+type member struct {
+	name     string
+	provider geo.Provider
+	breaker  *Breaker
+}
+
+// Chain is a geo provider that asks the members in order and returns the answer
+// of the first one that succeeds. Every member is guarded by its own circuit breaker,
+// so a member that keeps failing is skipped until its cool-down is over.
+type Chain struct {
+	members []*member
+}
+
+func New(log *slog.Logger, members []Member, settings Settings) *Chain {
+	c := &Chain{members: make([]*member, 0, len(members))}
+	for _, m := range members {
+		c.members = append(c.members, &member{
+			name:     m.Name,
+			provider: m.Provider,
+			breaker:  NewBreaker(log, m.Name, settings),
+		})
+	}
+	return c
+}
+
+func (c *Chain) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.failover.AddressSearch"
+	return c.do(ctx, op, func(ctx context.Context, p geo.Provider) ([]*geo.Address, error) {
+		return p.AddressSearch(ctx, input, opts)
+	})
+}
+
+func (c *Chain) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.failover.AddressGeoCode"
+	return c.do(ctx, op, func(ctx context.Context, p geo.Provider) ([]*geo.Address, error) {
+		return p.AddressGeoCode(ctx, point, opts)
+	})
+}
+
+func (c *Chain) do(ctx context.Context, op string,
+	call func(ctx context.Context, p geo.Provider) ([]*geo.Address, error)) ([]*geo.Address, error) {
+	var errs []error
+	unsupported := 0
+	for _, m := range c.members {
+		var res []*geo.Address
+		err := m.breaker.Execute(ctx, func(ctx context.Context) error {
+			var err error
+			res, err = call(ctx, m.provider)
+			return err
+		})
+		if err == nil {
+			return res, nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("%s: %w", op, ctxErr)
+		}
+		// a member rejecting the options is skipped in favour of the next one
+		if errors.Is(err, provider.ErrUnsupportedOption) {
+			unsupported++
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+	}
+	if unsupported > 0 && unsupported == len(c.members) {
+		return nil, fmt.Errorf("%s: %w", op, errors.Join(errs...))
+	}
+	return nil, fmt.Errorf("%s: %w: %w", op, provider.ErrUnavailable, errors.Join(errs...))
+}
+
+// States returns the circuit breaker state of every member in chain order.
+func (c *Chain) States() []BreakerState {
+	states := make([]BreakerState, 0, len(c.members))
+	for _, m := range c.members {
+		states = append(states, m.breaker.Snapshot())
+	}
+	return states
+}
+
+func (c *Chain) Name() string {
+	return "geo_provider"
+}
+
+// Status reports the chain as healthy while at least one member accepts calls.
+func (c *Chain) Status() (bool, interface{}) {
+	states := c.States()
+	healthy := false
+	for _, s := range states {
+		if s.State != StateOpen.String() {
+			healthy = true
+		}
+	}
+	return healthy, states
+}
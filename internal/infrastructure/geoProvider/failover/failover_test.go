@@ -0,0 +1,171 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	city     string
+	language string
+	err      error
+	calls    int
+}
+
+func (p *stubProvider) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []*geo.Address{{City: p.city}}, nil
+}
+
+func (p *stubProvider) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	if opts.Language != "" && opts.Language != p.language {
+		p.calls++
+		return nil, provider.ErrUnsupportedOption
+	}
+	return p.AddressSearch(ctx, point.String(), geo.SearchOptions{})
+}
+
+func TestChain(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	primary := &stubProvider{city: "primary"}
+	secondary := &stubProvider{city: "secondary"}
+	chain := New(log, []Member{
+		{Name: "primary", Provider: primary},
+		{Name: "secondary", Provider: secondary},
+	}, Settings{FailureThreshold: 2, CoolDown: time.Hour})
+
+	tests := []struct {
+		name           string
+		primaryErr     error
+		secondaryErr   error
+		want           []*geo.Address
+		wantErr        error
+		wantPrimaryHit bool
+		wantStates     []string
+	}{
+		{
+			name:           "primary answers",
+			want:           []*geo.Address{{City: "primary"}},
+			wantPrimaryHit: true,
+			wantStates:     []string{"closed", "closed"},
+		},
+		{
+			name:           "falls through to secondary",
+			primaryErr:     provider.ErrUnavailable,
+			want:           []*geo.Address{{City: "secondary"}},
+			wantPrimaryHit: true,
+			wantStates:     []string{"closed", "closed"},
+		},
+		{
+			name:           "primary breaker opens",
+			primaryErr:     provider.ErrUnavailable,
+			want:           []*geo.Address{{City: "secondary"}},
+			wantPrimaryHit: true,
+			wantStates:     []string{"open", "closed"},
+		},
+		{
+			name:           "open primary is skipped",
+			want:           []*geo.Address{{City: "secondary"}},
+			wantPrimaryHit: false,
+			wantStates:     []string{"open", "closed"},
+		},
+		{
+			name:           "all members fail",
+			secondaryErr:   provider.ErrUnavailable,
+			wantErr:        provider.ErrUnavailable,
+			wantPrimaryHit: false,
+			wantStates:     []string{"open", "closed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary.err, secondary.err = tt.primaryErr, tt.secondaryErr
+			primary.calls = 0
+
+			got, err := chain.AddressSearch(context.Background(), "query", geo.SearchOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressSearch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressSearch() got = %v, want %v", got, tt.want)
+			}
+			if (primary.calls > 0) != tt.wantPrimaryHit {
+				t.Errorf("primary called %d times, want called = %v", primary.calls, tt.wantPrimaryHit)
+			}
+			var states []string
+			for _, s := range chain.States() {
+				states = append(states, s.State)
+			}
+			if !reflect.DeepEqual(states, tt.wantStates) {
+				t.Errorf("States() = %v, want %v", states, tt.wantStates)
+			}
+		})
+	}
+}
+
+func TestChain_UnsupportedOption(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	primary := &stubProvider{city: "primary", language: "ru"}
+	secondary := &stubProvider{city: "secondary", language: "en"}
+	chain := New(log, []Member{
+		{Name: "primary", Provider: primary},
+		{Name: "secondary", Provider: secondary},
+	}, Settings{FailureThreshold: 1, CoolDown: time.Hour})
+	point := geo.Point{Lat: 55.8481373, Lon: 37.6414907}
+
+	tests := []struct {
+		name     string
+		language string
+		want     []*geo.Address
+		wantErr  error
+	}{
+		{
+			name:     "member rejecting the option is skipped",
+			language: "en",
+			want:     []*geo.Address{{City: "secondary"}},
+		},
+		{
+			name:     "rejection does not open the breaker",
+			language: "ru",
+			want:     []*geo.Address{{City: "primary"}},
+		},
+		{
+			name:     "option rejected by every member",
+			language: "de",
+			wantErr:  provider.ErrUnsupportedOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chain.AddressGeoCode(context.Background(), point, geo.GeocodeOptions{Language: tt.language})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressGeoCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.Is(err, provider.ErrUnavailable) {
+				t.Errorf("AddressGeoCode() error = %v, want no ErrUnavailable", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressGeoCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	for _, s := range chain.States() {
+		if s.State != "closed" {
+			t.Errorf("%s breaker is %s, want closed", s.Provider, s.State)
+		}
+	}
+}
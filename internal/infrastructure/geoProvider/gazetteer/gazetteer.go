@@ -0,0 +1,127 @@
+package gazetteer
+
+import (
+	"context"
+	"fmt"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"strings"
+)
+
+const (
+	// searchLimit and geocodeLimit mirror the defaults of the dadata API
+	searchLimit   = 10
+	geocodeLimit  = 10
+	geocodeRadius = 100 // meters
+)
+
+// GeoService answers queries from a local gazetteer loaded into memory,
+// it does not need network access.
+type GeoService struct {
+	entries []entry
+	index   *index
+	tree    *kdTree
+}
+
+// NewGeoService loads the gazetteer from a CSV or GeoJSON file and builds the search indexes.
+func NewGeoService(path string) (*GeoService, error) {
+	const op = "lib.geoProvider.gazetteer.NewGeoService"
+	entries, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return newGeoService(entries), nil
+}
+
+func newGeoService(entries []entry) *GeoService {
+	items := make([]kdItem, len(entries))
+	for i, e := range entries {
+		items[i] = kdItem{id: i, p: toPoint(e.lat, e.lon)}
+	}
+	return &GeoService{
+		entries: entries,
+		index:   newIndex(entries),
+		tree:    newKDTree(items),
+	}
+}
+
+// AddressSearch finds entries matching input. The gazetteer knows only cities and streets,
+// so locations may set only them and address level bounds are not supported.
+// Boosted locations are ignored.
+func (g *GeoService) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.gazetteer.AddressSearch"
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if opts.FromBound != "" || opts.ToBound != "" {
+		return nil, fmt.Errorf("%s: %w: from_bound and to_bound", op, provider.ErrUnsupportedOption)
+	}
+	for _, l := range opts.Locations {
+		if l != (geo.Location{City: l.City, Street: l.Street}) {
+			return nil, fmt.Errorf("%s: %w: locations other than city and street", op, provider.ErrUnsupportedOption)
+		}
+	}
+	limit := searchLimit
+	if opts.Count > 0 {
+		limit = opts.Count
+	}
+	var ids []int
+	for _, id := range g.index.search(input) {
+		if !inLocations(g.entries[id], opts.Locations) {
+			continue
+		}
+		ids = append(ids, id)
+		if len(ids) == limit {
+			break
+		}
+	}
+	return g.addresses(ids), nil
+}
+
+// inLocations reports whether the entry is inside any of the locations, or there are none.
+func inLocations(e entry, locations []geo.Location) bool {
+	if len(locations) == 0 {
+		return true
+	}
+	for _, l := range locations {
+		if sameName(l.City, e.City) && sameName(l.Street, e.Street) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameName compares names ignoring case and address element types, an empty want matches anything.
+func sameName(want, got string) bool {
+	return want == "" || strings.Join(tokenize(want), " ") == strings.Join(tokenize(got), " ")
+}
+
+// AddressGeoCode returns the entries nearest to the point. The gazetteer holds
+// addresses in a single language, so the language option is not supported.
+func (g *GeoService) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.gazetteer.AddressGeoCode"
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if opts.Language != "" {
+		return nil, fmt.Errorf("%s: %w: language", op, provider.ErrUnsupportedOption)
+	}
+	limit, radius := geocodeLimit, geocodeRadius
+	if opts.Count > 0 {
+		limit = opts.Count
+	}
+	if opts.RadiusMeters > 0 {
+		radius = opts.RadiusMeters
+	}
+	ids := g.tree.nearest(toPoint(point.Lat, point.Lon), limit, float64(radius))
+	return g.addresses(ids), nil
+}
+
+func (g *GeoService) addresses(ids []int) []*geo.Address {
+	var res []*geo.Address
+	for _, id := range ids {
+		e := g.entries[id]
+		res = append(res, &geo.Address{City: e.City, Street: e.Street, House: e.House, Lat: e.Lat, Lon: e.Lon})
+	}
+	return res
+}
@@ -0,0 +1,252 @@
+package gazetteer
+
+import (
+	"context"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"reflect"
+	"testing"
+)
+
+func TestGeoService_AddressSearch(t *testing.T) {
+	geoService, err := NewGeoService("testdata/gazetteer.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snezhnaya := []*geo.Address{
+		{City: "Москва", Street: "Снежная", House: "", Lat: "55.852405", Lon: "37.646947"},
+		{City: "Москва", Street: "Снежная", House: "1", Lat: "55.849384", Lon: "37.64015"},
+		{City: "Москва", Street: "Снежная", House: "1А", Lat: "55.846724", Lon: "37.639545"},
+		{City: "Москва", Street: "Снежная", House: "3А", Lat: "55.8495825", Lon: "37.6409167"},
+		{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+		{City: "Москва", Street: "Снежная", House: "5", Lat: "55.849247", Lon: "37.641514"},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    geo.SearchOptions
+		want    []*geo.Address
+		wantErr error
+	}{
+		{
+			name:  "abbreviations",
+			input: "г Москва, ул Снежная",
+			want:  snezhnaya,
+		},
+		{
+			name:  "full type names",
+			input: "город Москва, улица Снежная",
+			want:  snezhnaya,
+		},
+		{
+			name:  "typos",
+			input: "Моксва Снжная",
+			want:  snezhnaya,
+		},
+		{
+			name:  "house number",
+			input: "г. Москва, ул. Снежная, д. 4",
+			want: []*geo.Address{
+				{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+			},
+		},
+		{
+			name:  "prefix",
+			input: "Санкт-Петербург, Невс",
+			want: []*geo.Address{
+				{City: "Санкт-Петербург", Street: "Невский", House: "28", Lat: "59.935634", Lon: "30.325935"},
+			},
+		},
+		{
+			name:  "yo",
+			input: "елкино лесная",
+			want: []*geo.Address{
+				{City: "Ёлкино", Street: "Лесная", House: "12", Lat: "56.153421", Lon: "37.981001"},
+			},
+		},
+		{
+			name:  "city location",
+			input: "Снежная",
+			opts:  geo.SearchOptions{Locations: []geo.Location{{City: "г Санкт-Петербург"}}},
+			want: []*geo.Address{
+				{City: "Санкт-Петербург", Street: "Снежная", House: "2", Lat: "60.004261", Lon: "30.381462"},
+			},
+		},
+		{
+			name:  "any of the locations",
+			input: "Снежная",
+			opts: geo.SearchOptions{Locations: []geo.Location{
+				{City: "Казань"},
+				{City: "Москва", Street: "ул Снежная"},
+			}},
+			want: snezhnaya,
+		},
+		{
+			name:  "count",
+			input: "г Москва, ул Снежная",
+			opts:  geo.SearchOptions{Count: 2},
+			want:  snezhnaya[:2],
+		},
+		{
+			name:    "bounds are not supported",
+			input:   "Москва",
+			opts:    geo.SearchOptions{FromBound: geo.GranularityCity, ToBound: geo.GranularityCity},
+			wantErr: provider.ErrUnsupportedOption,
+		},
+		{
+			name:    "region location is not supported",
+			input:   "Снежная",
+			opts:    geo.SearchOptions{Locations: []geo.Location{{Region: "Москва"}}},
+			wantErr: provider.ErrUnsupportedOption,
+		},
+		{
+			name:  "not found",
+			input: "Владивосток",
+			want:  nil,
+		},
+		{
+			name:  "only address types",
+			input: "г ул д",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geoService.AddressSearch(context.Background(), tt.input, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressSearch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressSearch() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoService_AddressGeoCode(t *testing.T) {
+	geoService, err := NewGeoService("testdata/gazetteer.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		point   geo.Point
+		opts    geo.GeocodeOptions
+		want    []*geo.Address
+		wantErr error
+	}{
+		{
+			name:  "nearest within radius",
+			point: geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			want: []*geo.Address{
+				{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+				{City: "Москва", Street: "Серебрякова", House: "1/2", Lat: "55.847447", Lon: "37.640803"},
+				{City: "Москва", Street: "Лазоревый", House: "1", Lat: "55.848574", Lon: "37.640309"},
+			},
+		},
+		{
+			name:  "count",
+			point: geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:  geo.GeocodeOptions{Count: 1},
+			want: []*geo.Address{
+				{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+			},
+		},
+		{
+			name:  "radius",
+			point: geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:  geo.GeocodeOptions{RadiusMeters: 1},
+			want: []*geo.Address{
+				{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+			},
+		},
+		{
+			name:    "language is not supported",
+			point:   geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:    geo.GeocodeOptions{Language: "en"},
+			wantErr: provider.ErrUnsupportedOption,
+		},
+		{
+			name:  "nothing nearby",
+			point: geo.Point{Lat: 0, Lon: 0},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geoService.AddressGeoCode(context.Background(), tt.point, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressGeoCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressGeoCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGeoService(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantLen int
+		wantErr error
+	}{
+		{
+			name:    "csv",
+			path:    "testdata/gazetteer.csv",
+			wantLen: 12,
+		},
+		{
+			name:    "geojson",
+			path:    "testdata/gazetteer.geojson",
+			wantLen: 2,
+		},
+		{
+			name:    "unknown format",
+			path:    "testdata/gazetteer.txt",
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:    "NaN coordinate",
+			path:    "testdata/nan.csv",
+			wantErr: ErrMalformed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewGeoService(tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewGeoService() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && len(got.entries) != tt.wantLen {
+				t.Errorf("NewGeoService() loaded %d entries, want %d", len(got.entries), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestKDTree_Nearest(t *testing.T) {
+	// points on both sides of the antimeridian are close to each other
+	entries := []entry{
+		{lat: 65.0, lon: 179.999},
+		{lat: 65.0, lon: -179.999},
+		{lat: 65.0, lon: 170.0},
+	}
+	items := make([]kdItem, len(entries))
+	for i, e := range entries {
+		items[i] = kdItem{id: i, p: toPoint(e.lat, e.lon)}
+	}
+	tree := newKDTree(items)
+
+	got := tree.nearest(toPoint(65.0, -179.9995), 2, 1000)
+	if want := []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nearest() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package gazetteer
+
+import (
+	"sort"
+	"strings"
+)
+
+// prefixCost is the cost of matching a query token as a prefix of an entry token,
+// so that complete words rank above autocompleted ones.
+const prefixCost = 0.5
+
+// index is an inverted index from tokens to entries, tolerant to typos.
+type index struct {
+	// postings maps a token to the sorted ids of entries containing it
+	postings map[string][]int
+	// vocabulary is the sorted list of all tokens, used for prefix lookups
+	vocabulary []string
+	// trigrams maps a trigram to the tokens containing it
+	trigrams map[string][]string
+}
+
+func newIndex(entries []entry) *index {
+	idx := &index{
+		postings: make(map[string][]int),
+		trigrams: make(map[string][]string),
+	}
+	for id, e := range entries {
+		seen := make(map[string]struct{})
+		for _, t := range e.tokens() {
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			idx.postings[t] = append(idx.postings[t], id)
+		}
+	}
+	for t := range idx.postings {
+		idx.vocabulary = append(idx.vocabulary, t)
+		for _, tg := range trigramsOf(t) {
+			idx.trigrams[tg] = append(idx.trigrams[tg], t)
+		}
+	}
+	sort.Strings(idx.vocabulary)
+	return idx
+}
+
+// search returns ids of entries matching every token of the query, best matches first.
+func (idx *index) search(query string) []int {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var scores map[int]float64
+	for _, qt := range tokens {
+		matched := make(map[int]float64)
+		for t, cost := range idx.lookup(qt) {
+			for _, id := range idx.postings[t] {
+				if c, ok := matched[id]; !ok || cost < c {
+					matched[id] = cost
+				}
+			}
+		}
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for id := range scores {
+			if c, ok := matched[id]; ok {
+				scores[id] += c
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] < scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// lookup finds the tokens of the vocabulary similar to qt along with the match cost.
+func (idx *index) lookup(qt string) map[string]float64 {
+	res := make(map[string]float64)
+	if _, ok := idx.postings[qt]; ok {
+		res[qt] = 0
+	}
+	// house numbers are matched exactly
+	if isNumeric(qt) {
+		return res
+	}
+
+	from := sort.SearchStrings(idx.vocabulary, qt)
+	for _, t := range idx.vocabulary[from:] {
+		if !strings.HasPrefix(t, qt) {
+			break
+		}
+		if _, ok := res[t]; !ok {
+			res[t] = prefixCost
+		}
+	}
+
+	q := []rune(qt)
+	limit := maxTypos(len(q))
+	if limit == 0 {
+		return res
+	}
+	checked := make(map[string]struct{})
+	for _, tg := range trigramsOf(qt) {
+		for _, t := range idx.trigrams[tg] {
+			if _, ok := checked[t]; ok {
+				continue
+			}
+			checked[t] = struct{}{}
+			if _, ok := res[t]; ok {
+				continue
+			}
+			if d := distance(q, []rune(t), limit); d <= limit {
+				res[t] = float64(d)
+			}
+		}
+	}
+	return res
+}
+
+func trigramsOf(token string) []string {
+	r := []rune("$$" + token + "$")
+	res := make([]string, 0, len(r)-2)
+	for i := 0; i+3 <= len(r); i++ {
+		res = append(res, string(r[i:i+3]))
+	}
+	return res
+}
@@ -0,0 +1,133 @@
+package gazetteer
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+const earthRadius = 6371008.8 // meters
+
+// point is a location on the unit sphere, so that the euclidean (chord) distance
+// grows monotonically with the great-circle distance and no longitude wrapping
+// is needed.
+type point [3]float64
+
+func toPoint(lat, lon float64) point {
+	phi, lambda := lat*math.Pi/180, lon*math.Pi/180
+	return point{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+func (p point) chord(q point) float64 {
+	dx, dy, dz := p[0]-q[0], p[1]-q[1], p[2]-q[2]
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// chordOf converts a great-circle distance in meters to the chord length on the unit sphere.
+func chordOf(meters float64) float64 {
+	return 2 * math.Sin(math.Min(meters/earthRadius, math.Pi)/2)
+}
+
+type kdNode struct {
+	id          int
+	p           point
+	axis        int
+	left, right *kdNode
+}
+
+// kdTree is a static 3-d tree over entry locations.
+type kdTree struct {
+	root *kdNode
+}
+
+type kdItem struct {
+	id int
+	p  point
+}
+
+func newKDTree(items []kdItem) *kdTree {
+	return &kdTree{root: buildKD(items, 0)}
+}
+
+func buildKD(items []kdItem, depth int) *kdNode {
+	if len(items) == 0 {
+		return nil
+	}
+	axis := depth % 3
+	sort.Slice(items, func(i, j int) bool { return items[i].p[axis] < items[j].p[axis] })
+	m := len(items) / 2
+	return &kdNode{
+		id:    items[m].id,
+		p:     items[m].p,
+		axis:  axis,
+		left:  buildKD(items[:m], depth+1),
+		right: buildKD(items[m+1:], depth+1),
+	}
+}
+
+type neighbour struct {
+	id   int
+	dist float64
+}
+
+// neighbours is a max-heap of the best candidates found so far.
+type neighbours []neighbour
+
+func (h neighbours) Len() int            { return len(h) }
+func (h neighbours) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h neighbours) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighbours) Push(x interface{}) { *h = append(*h, x.(neighbour)) }
+func (h *neighbours) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// nearest returns ids of at most k entries within radius meters of target, closest first.
+func (t *kdTree) nearest(target point, k int, radius float64) []int {
+	if k <= 0 {
+		return nil
+	}
+	h := &neighbours{}
+	t.search(t.root, target, k, chordOf(radius), h)
+
+	res := make([]int, h.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(h).(neighbour).id
+	}
+	return res
+}
+
+func (t *kdTree) search(n *kdNode, target point, k int, maxDist float64, h *neighbours) {
+	if n == nil {
+		return
+	}
+	if d := n.p.chord(target); d <= maxDist {
+		if h.Len() < k {
+			heap.Push(h, neighbour{id: n.id, dist: d})
+		} else if d < (*h)[0].dist {
+			(*h)[0] = neighbour{id: n.id, dist: d}
+			heap.Fix(h, 0)
+		}
+	}
+
+	diff := target[n.axis] - n.p[n.axis]
+	near, far := n.left, n.right
+	if diff > 0 {
+		near, far = n.right, n.left
+	}
+	t.search(near, target, k, maxDist, h)
+
+	bound := maxDist
+	if h.Len() == k {
+		bound = math.Min(bound, (*h)[0].dist)
+	}
+	if math.Abs(diff) <= bound {
+		t.search(far, target, k, maxDist, h)
+	}
+}
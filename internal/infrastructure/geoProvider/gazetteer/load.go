@@ -0,0 +1,148 @@
+package gazetteer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"geo/internal/service/geo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown gazetteer format")
+	ErrMalformed     = errors.New("malformed gazetteer record")
+)
+
+type entry struct {
+	City   string
+	Street string
+	House  string
+	Lat    string
+	Lon    string
+	lat    float64
+	lon    float64
+}
+
+func (e entry) tokens() []string {
+	return tokenize(e.City + " " + e.Street + " " + e.House)
+}
+
+// load reads gazetteer entries from a CSV or GeoJSON file, the format is chosen by extension.
+func load(path string) ([]entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return loadCSV(f)
+	case ".geojson", ".json":
+		return loadGeoJSON(f)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+}
+
+// loadCSV reads a CSV file with the header city,street,house,lat,lon in any column order.
+func loadCSV(r io.Reader) ([]entry, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, c := range []string{"city", "street", "house", "lat", "lon"} {
+		if _, ok := columns[c]; !ok {
+			return nil, fmt.Errorf("%w: column %q is missing", ErrMalformed, c)
+		}
+	}
+
+	var entries []entry
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		line, _ := cr.FieldPos(0)
+		e, err := newEntry(record[columns["city"]], record[columns["street"]], record[columns["house"]],
+			record[columns["lat"]], record[columns["lon"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+type featureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry struct {
+			Type        string    `json:"type"`
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties struct {
+			City   string `json:"city"`
+			Street string `json:"street"`
+			House  string `json:"house"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+// loadGeoJSON reads a FeatureCollection of Point features with city, street and house properties.
+func loadGeoJSON(r io.Reader) ([]entry, error) {
+	var fc featureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: FeatureCollection expected, got %q", ErrMalformed, fc.Type)
+	}
+
+	entries := make([]entry, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		if f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+			return nil, fmt.Errorf("feature %d: %w: Point geometry expected", i, ErrMalformed)
+		}
+		lon := strconv.FormatFloat(f.Geometry.Coordinates[0], 'f', -1, 64)
+		lat := strconv.FormatFloat(f.Geometry.Coordinates[1], 'f', -1, 64)
+		e, err := newEntry(f.Properties.City, f.Properties.Street, f.Properties.House, lat, lon)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func newEntry(city, street, house, lat, lon string) (entry, error) {
+	e := entry{
+		City:   strings.TrimSpace(city),
+		Street: strings.TrimSpace(street),
+		House:  strings.TrimSpace(house),
+		Lat:    strings.TrimSpace(lat),
+		Lon:    strings.TrimSpace(lon),
+	}
+	var err error
+	if e.lat, err = geo.ParseLatitude(e.Lat); err != nil {
+		return entry{}, fmt.Errorf("%w: invalid lat %q", ErrMalformed, lat)
+	}
+	if e.lon, err = geo.ParseLongitude(e.Lon); err != nil {
+		return entry{}, fmt.Errorf("%w: invalid lon %q", ErrMalformed, lon)
+	}
+	return e, nil
+}
@@ -0,0 +1,111 @@
+package gazetteer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// typeWords are address element types in full and abbreviated form. They carry
+// no information for matching, so "г Москва, ул Снежная" and "Москва Снежная улица"
+// produce the same tokens.
+var typeWords = map[string]struct{}{
+	"г": {}, "гор": {}, "город": {},
+	"ул": {}, "улица": {},
+	"пр": {}, "пр-т": {}, "пр-кт": {}, "просп": {}, "проспект": {},
+	"пер": {}, "переулок": {},
+	"пл": {}, "площадь": {},
+	"наб": {}, "набережная": {},
+	"б-р": {}, "бул": {}, "бульвар": {},
+	"ш": {}, "шоссе": {},
+	"туп": {}, "тупик": {},
+	"пр-д": {}, "проезд": {},
+	"мкр": {}, "микрорайон": {},
+	"пос": {}, "поселок": {}, "п": {},
+	"с": {}, "село": {},
+	"д": {}, "дом": {},
+	"к": {}, "корп": {}, "корпус": {},
+	"стр": {}, "строение": {},
+}
+
+// tokenize splits s into lowercase tokens without address element types.
+func tokenize(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '/'
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, "-/")
+		if f == "" {
+			continue
+		}
+		if _, ok := typeWords[f]; ok {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+func isNumeric(token string) bool {
+	for _, r := range token {
+		if unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxTypos is the edit distance tolerated for a token of the given length.
+func maxTypos(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance is the optimal string alignment distance between a and b,
+// it stops early and returns limit+1 once the distance exceeds limit.
+func distance(a, b []rune, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
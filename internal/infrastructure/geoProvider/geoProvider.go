@@ -6,4 +6,6 @@ import (
 
 var (
 	ErrUnavailable = errors.New("geoProvider unavailable")
+	// ErrUnsupportedOption is returned for a geocode option the provider cannot apply
+	ErrUnsupportedOption = errors.New("geoProvider option not supported")
 )
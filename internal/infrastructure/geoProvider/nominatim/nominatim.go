@@ -0,0 +1,177 @@
+package nominatim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	searchLimit = 10
+	// filteredSearchLimit is the number of places fetched when they are filtered
+	// by location afterwards, it is the largest limit the API accepts
+	filteredSearchLimit = 40
+)
+
+type GeoService struct {
+	client    *http.Client
+	baseURL   *url.URL
+	userAgent string
+	limiter   *limiter
+}
+
+// NewGeoService creates a client of a Nominatim-compatible API located at baseURL.
+// rateLimit is the maximum number of requests per second, zero disables limiting.
+func NewGeoService(baseURL, userAgent string, rateLimit float64) (*GeoService, error) {
+	const op = "lib.geoProvider.nominatim.NewGeoService"
+	endpointUrl, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &GeoService{
+		client:    &http.Client{},
+		baseURL:   endpointUrl,
+		userAgent: userAgent,
+		limiter:   newLimiter(rateLimit),
+	}, nil
+}
+
+// AddressSearch finds places matching input. Locations are matched by name after the search,
+// FIAS and KLADR IDs and address level bounds are not supported, boosted locations are ignored.
+func (g *GeoService) AddressSearch(ctx context.Context, input string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.nominatim.AddressSearch"
+	if opts.FromBound != "" || opts.ToBound != "" {
+		return nil, fmt.Errorf("%s: %w: from_bound and to_bound", op, provider.ErrUnsupportedOption)
+	}
+	for _, l := range opts.Locations {
+		if l.FiasID != "" || l.KladrID != "" {
+			return nil, fmt.Errorf("%s: %w: locations by fias_id or kladr_id", op, provider.ErrUnsupportedOption)
+		}
+	}
+	limit := searchLimit
+	if opts.Count > 0 {
+		limit = opts.Count
+	}
+	fetch := limit
+	if len(opts.Locations) > 0 {
+		fetch = filteredSearchLimit
+	}
+	params := url.Values{}
+	params.Set("q", input)
+	params.Set("limit", strconv.Itoa(fetch))
+
+	var places []Place
+	if err := g.get(ctx, "search", params, &places); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var res []*geo.Address
+	for _, p := range places {
+		if !inLocations(p.Address, opts.Locations) {
+			continue
+		}
+		res = append(res, toAddress(p))
+		if len(res) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+// inLocations reports whether the address is inside any of the locations, or there are none.
+// Streets match by a part of the name, since OSM names include the street type.
+func inLocations(a PlaceAddress, locations []geo.Location) bool {
+	if len(locations) == 0 {
+		return true
+	}
+	for _, l := range locations {
+		if matches(l.Country, a.Country) && matches(l.Region, a.State) &&
+			matches(l.City, a.Locality()) && matches(l.Settlement, a.Locality()) &&
+			(l.Street == "" || strings.Contains(strings.ToLower(a.Road), strings.ToLower(l.Street))) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// AddressGeoCode returns the single place nearest to the point, so any count is satisfied.
+// A search radius is not supported by the reverse API.
+func (g *GeoService) AddressGeoCode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	const op = "lib.geoProvider.nominatim.AddressGeoCode"
+	if opts.RadiusMeters != 0 {
+		return nil, fmt.Errorf("%s: %w: radius_meters", op, provider.ErrUnsupportedOption)
+	}
+	params := url.Values{}
+	params.Set("lat", point.LatString())
+	params.Set("lon", point.LonString())
+	if opts.Language != "" {
+		params.Set("accept-language", opts.Language)
+	}
+
+	var place Place
+	if err := g.get(ctx, "reverse", params, &place); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if place.Error != "" {
+		return nil, nil
+	}
+	return []*geo.Address{toAddress(place)}, nil
+}
+
+func (g *GeoService) get(ctx context.Context, method string, params url.Values, result interface{}) error {
+	if err := g.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	params.Set("format", "jsonv2")
+	params.Set("addressdetails", "1")
+	endpoint := g.baseURL.JoinPath(method)
+	endpoint.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", g.userAgent)
+	resp, err := g.client.Do(req)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", provider.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", provider.ErrUnavailable, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: decode: %v", provider.ErrUnavailable, err)
+	}
+	return nil
+}
+
+func toAddress(p Place) *geo.Address {
+	return &geo.Address{
+		City:   p.Address.Locality(),
+		Street: p.Address.Road,
+		House:  p.Address.HouseNumber,
+		Lat:    p.Lat,
+		Lon:    p.Lon,
+
+		Value:      p.DisplayName,
+		PostalCode: p.Address.Postcode,
+		Country:    p.Address.Country,
+		Region:     p.Address.State,
+		Area:       p.Address.County,
+	}
+}
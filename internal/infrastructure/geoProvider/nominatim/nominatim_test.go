@@ -0,0 +1,293 @@
+package nominatim
+
+import (
+	"context"
+	"errors"
+	provider "geo/internal/infrastructure/geoProvider"
+	"geo/internal/service/geo"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const userAgent = "geoservice-test"
+
+// newStandIn serves recorded Nominatim responses from testdata.
+func newStandIn(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	serve := func(w http.ResponseWriter, r *http.Request, file string) {
+		if r.UserAgent() != userAgent {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("format") != "jsonv2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("q") {
+		case "Москва, Снежная":
+			serve(w, r, "search.json")
+		case "overload":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "maintenance":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>Down for maintenance</body></html>"))
+		default:
+			w.Write([]byte("[]"))
+		}
+	})
+	mux.HandleFunc("/reverse", func(w http.ResponseWriter, r *http.Request) {
+		lang := r.URL.Query().Get("accept-language")
+		if r.URL.Query().Get("lat") == "55.8481373" && r.URL.Query().Get("lon") == "37.6414907" &&
+			(lang == "" || lang == "ru") {
+			serve(w, r, "reverse.json")
+			return
+		}
+		serve(w, r, "reverse_not_found.json")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGeoService_AddressSearch(t *testing.T) {
+	srv := newStandIn(t)
+	geoService, err := NewGeoService(srv.URL, userAgent, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		opts    geo.SearchOptions
+		want    []*geo.Address
+		wantErr error
+	}{
+		{
+			name:  "success",
+			input: "Москва, Снежная",
+			want: []*geo.Address{
+				{
+					City:   "Москва",
+					Street: "Снежная улица",
+					House:  "",
+					Lat:    "55.8515097",
+					Lon:    "37.6465391",
+
+					Value:      "Снежная улица, Свиблово, Москва, Центральный федеральный округ, 129323, Россия",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+				},
+				{
+					City:   "Москва",
+					Street: "Снежная улица",
+					House:  "4",
+					Lat:    "55.8481373",
+					Lon:    "37.6414907",
+
+					Value:      "4, Снежная улица, Свиблово, Москва, Центральный федеральный округ, 129323, Россия",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+				},
+				// places without a road are left to the search rules of the use case
+				{
+					City: "Москва",
+					Lat:  "55.8510457",
+					Lon:  "37.6458744",
+
+					Value:      "Снежная улица, Свиблово, Москва, Центральный федеральный округ, 129323, Россия",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name:  "count and location",
+			input: "Москва, Снежная",
+			opts: geo.SearchOptions{
+				Locations: []geo.Location{{City: "москва", Street: "снежная"}},
+				Count:     1,
+			},
+			want: []*geo.Address{
+				{
+					City:   "Москва",
+					Street: "Снежная улица",
+					House:  "",
+					Lat:    "55.8515097",
+					Lon:    "37.6465391",
+
+					Value:      "Снежная улица, Свиблово, Москва, Центральный федеральный округ, 129323, Россия",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+				},
+			},
+		},
+		{
+			name:  "outside of the location",
+			input: "Москва, Снежная",
+			opts:  geo.SearchOptions{Locations: []geo.Location{{City: "Санкт-Петербург"}}},
+			want:  nil,
+		},
+		{
+			name:    "location by id is not supported",
+			input:   "Москва, Снежная",
+			opts:    geo.SearchOptions{Locations: []geo.Location{{KladrID: "7700000000000"}}},
+			wantErr: provider.ErrUnsupportedOption,
+		},
+		{
+			name:    "bounds are not supported",
+			input:   "Москва",
+			opts:    geo.SearchOptions{FromBound: geo.GranularityCity},
+			wantErr: provider.ErrUnsupportedOption,
+		},
+		{
+			name:    "nothing found",
+			input:   "nowhere",
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			name:    "rate limited by upstream",
+			input:   "overload",
+			want:    nil,
+			wantErr: provider.ErrUnavailable,
+		},
+		{
+			name:    "maintenance page instead of json",
+			input:   "maintenance",
+			want:    nil,
+			wantErr: provider.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geoService.AddressSearch(context.Background(), tt.input, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressSearch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressSearch() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoService_AddressGeoCode(t *testing.T) {
+	srv := newStandIn(t)
+	geoService, err := NewGeoService(srv.URL, userAgent, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		point   geo.Point
+		opts    geo.GeocodeOptions
+		want    []*geo.Address
+		wantErr error
+	}{
+		{
+			name:  "success",
+			point: geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			want: []*geo.Address{
+				{
+					City:   "Москва",
+					Street: "Снежная улица",
+					House:  "4",
+					Lat:    "55.8481373",
+					Lon:    "37.6414907",
+
+					Value:      "4, Снежная улица, Свиблово, Москва, Центральный федеральный округ, 129323, Россия",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+				},
+			},
+		},
+		{
+			name:  "language",
+			point: geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:  geo.GeocodeOptions{Language: "ru", Count: 5},
+			want: []*geo.Address{
+				{
+					City:   "Москва",
+					Street: "Снежная улица",
+					House:  "4",
+					Lat:    "55.8481373",
+					Lon:    "37.6414907",
+
+					Value:      "4, Снежная улица, Свиблово, Москва, Центральный федеральный округ, 129323, Россия",
+					PostalCode: "129323",
+					Country:    "Россия",
+					Region:     "Москва",
+				},
+			},
+		},
+		{
+			name:    "radius is not supported",
+			point:   geo.Point{Lat: 55.8481373, Lon: 37.6414907},
+			opts:    geo.GeocodeOptions{RadiusMeters: 100},
+			wantErr: provider.ErrUnsupportedOption,
+		},
+		{
+			name:  "unable to geocode",
+			point: geo.Point{Lat: 0, Lon: 0},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geoService.AddressGeoCode(context.Background(), tt.point, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddressGeoCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddressGeoCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoService_RateLimit(t *testing.T) {
+	srv := newStandIn(t)
+	geoService, err := NewGeoService(srv.URL, userAgent, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := geoService.AddressSearch(context.Background(), "nowhere", geo.SearchOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("3 requests at 10 rps took %v, want at least 200ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	geoService.AddressSearch(context.Background(), "nowhere", geo.SearchOptions{})
+	if _, err := geoService.AddressSearch(ctx, "nowhere", geo.SearchOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AddressSearch() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
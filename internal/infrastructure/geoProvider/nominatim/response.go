@@ -0,0 +1,49 @@
+package nominatim
+
+// Place is a single result of the /search and /reverse endpoints in jsonv2 format.
+type Place struct {
+	PlaceID     int64        `json:"place_id"`
+	Licence     string       `json:"licence"`
+	OsmType     string       `json:"osm_type"`
+	OsmID       int64        `json:"osm_id"`
+	Lat         string       `json:"lat"`
+	Lon         string       `json:"lon"`
+	Category    string       `json:"category"`
+	Type        string       `json:"type"`
+	PlaceRank   int          `json:"place_rank"`
+	Importance  float64      `json:"importance"`
+	AddressType string       `json:"addresstype"`
+	Name        string       `json:"name"`
+	DisplayName string       `json:"display_name"`
+	Address     PlaceAddress `json:"address"`
+	BoundingBox []string     `json:"boundingbox"`
+	// Error is set by /reverse when nothing is found at the given coordinates
+	Error string `json:"error,omitempty"`
+}
+
+type PlaceAddress struct {
+	HouseNumber  string `json:"house_number"`
+	Road         string `json:"road"`
+	Suburb       string `json:"suburb"`
+	CityDistrict string `json:"city_district"`
+	City         string `json:"city"`
+	Town         string `json:"town"`
+	Village      string `json:"village"`
+	Hamlet       string `json:"hamlet"`
+	County       string `json:"county"`
+	State        string `json:"state"`
+	Region       string `json:"region"`
+	Postcode     string `json:"postcode"`
+	Country      string `json:"country"`
+	CountryCode  string `json:"country_code"`
+}
+
+// Locality returns the name of the settlement the place belongs to.
+func (a PlaceAddress) Locality() string {
+	for _, l := range []string{a.City, a.Town, a.Village, a.Hamlet} {
+		if l != "" {
+			return l
+		}
+	}
+	return ""
+}
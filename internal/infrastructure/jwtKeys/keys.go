@@ -0,0 +1,199 @@
+package jwtKeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"os"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("signing algorithm must be one of HS256, RS256, ES256, EdDSA")
+	ErrNoSecret             = errors.New("HS256 requires a secret")
+	ErrNoSigningKey         = errors.New("asymmetric algorithms require a signing key")
+	ErrNotPrivateKey        = errors.New("signing key must be a private key")
+	ErrKeyMismatch          = errors.New("key does not fit the algorithm")
+	ErrDuplicateKey         = errors.New("key is listed twice")
+)
+
+// The tokens of another issuer or audience are told apart from the other invalid tokens,
+// whose errors are the ones of jwtauth.
+var (
+	ErrInvalidIssuer   = errors.New("token issuer mismatch")
+	ErrInvalidAudience = errors.New("token audience mismatch")
+)
+
+// Keys signs the tokens with one key and verifies them with any of the active ones, so that
+// the signing key is rotated without logging everyone out: the public key of the previous
+// signing key stays among the verification keys until the tokens it signed expire.
+// Asymmetric keys are told apart by the kid header, their RFC 7638 thumbprint.
+type Keys struct {
+	alg     jwa.SignatureAlgorithm
+	signKey interface{}
+	// verifyKeys is nil for HS256, the tokens are verified with the secret then
+	verifyKeys      jwk.Set
+	validateOptions []jwt.ValidateOption
+}
+
+// New returns HS256 keys of the secret or loads the PEM files of the other algorithms:
+// the private signing key and the verification keys, public or private, of any supported type.
+func New(alg, secret, signingKey string, verificationKeys []string, validateOptions ...jwt.ValidateOption) (*Keys, error) {
+	k := &Keys{alg: jwa.SignatureAlgorithm(alg), validateOptions: validateOptions}
+	switch k.alg {
+	case jwa.HS256:
+		if secret == "" {
+			return nil, ErrNoSecret
+		}
+		k.signKey = []byte(secret)
+		return k, nil
+	case jwa.RS256, jwa.ES256, jwa.EdDSA:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+	if signingKey == "" {
+		return nil, ErrNoSigningKey
+	}
+
+	sign, err := loadKey(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if !isPrivate(sign) {
+		return nil, fmt.Errorf("%w: %s", ErrNotPrivateKey, signingKey)
+	}
+	if keyAlgorithm(sign) != k.alg {
+		return nil, fmt.Errorf("%w: %s is not a %s key", ErrKeyMismatch, signingKey, alg)
+	}
+	if err := sign.Set(jwk.AlgorithmKey, k.alg); err != nil {
+		return nil, err
+	}
+	k.signKey = sign
+
+	k.verifyKeys = jwk.NewSet()
+	if err := k.addVerifyKey(signingKey, sign); err != nil {
+		return nil, err
+	}
+	for _, path := range verificationKeys {
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.addVerifyKey(path, key); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// JWTAuth signs the tokens with the signing key, the kid header is set for asymmetric keys.
+func (k *Keys) JWTAuth() *jwtauth.JWTAuth {
+	return jwtauth.New(k.alg.String(), k.signKey, nil, k.validateOptions...)
+}
+
+// Verify checks the signature and the claims of the token like jwtauth.VerifyToken does.
+func (k *Keys) Verify(tokenString string) (jwt.Token, error) {
+	var keyOption jwt.ParseOption = jwt.WithKey(k.alg, k.signKey)
+	if k.verifyKeys != nil {
+		keyOption = jwt.WithKeySet(k.verifyKeys)
+	}
+	token, err := jwt.Parse([]byte(tokenString), keyOption, jwt.WithValidate(false))
+	if err != nil {
+		return token, jwtauth.ErrorReason(err)
+	}
+
+	err = jwt.Validate(token, k.validateOptions...)
+	switch {
+	case err == nil:
+		return token, nil
+	case errors.Is(err, jwt.ErrInvalidIssuer()):
+		return token, fmt.Errorf("%w: %v", ErrInvalidIssuer, err)
+	case errors.Is(err, jwt.ErrInvalidAudience()):
+		return token, fmt.Errorf("%w: %v", ErrInvalidAudience, err)
+	}
+	return token, jwtauth.ErrorReason(err)
+}
+
+// JWKS returns the public verification keys, empty for HS256 whose secret is never published.
+func (k *Keys) JWKS() (jwk.Set, error) {
+	if k.verifyKeys == nil {
+		return jwk.NewSet(), nil
+	}
+	return jwk.PublicSetOf(k.verifyKeys)
+}
+
+func (k *Keys) addVerifyKey(path string, key jwk.Key) error {
+	public, err := jwk.PublicKeyOf(key)
+	if err != nil {
+		return fmt.Errorf("public key of %s: %w", path, err)
+	}
+	alg := keyAlgorithm(public)
+	if alg == "" {
+		return fmt.Errorf("%w: %s", ErrKeyMismatch, path)
+	}
+	if err := jwk.AssignKeyID(public); err != nil {
+		return fmt.Errorf("key id of %s: %w", path, err)
+	}
+	if _, exists := k.verifyKeys.LookupKeyID(public.KeyID()); exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateKey, path)
+	}
+	if err := public.Set(jwk.AlgorithmKey, alg); err != nil {
+		return err
+	}
+	if err := public.Set(jwk.KeyUsageKey, jwk.ForSignature); err != nil {
+		return err
+	}
+	if isPrivate(key) {
+		// the signing key carries the kid of its public key into the header
+		if err := key.Set(jwk.KeyIDKey, public.KeyID()); err != nil {
+			return err
+		}
+	}
+	return k.verifyKeys.AddKey(public)
+}
+
+func loadKey(path string) (jwk.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := jwk.ParseKey(data, jwk.WithPEM(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func isPrivate(key jwk.Key) bool {
+	ak, ok := key.(jwk.AsymmetricKey)
+	return ok && ak.IsPrivate()
+}
+
+// keyAlgorithm returns the algorithm the key is used with, empty for unsupported keys.
+func keyAlgorithm(key jwk.Key) jwa.SignatureAlgorithm {
+	var raw interface{}
+	if err := key.Raw(&raw); err != nil {
+		return ""
+	}
+	switch raw := raw.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return jwa.RS256
+	case *ecdsa.PrivateKey:
+		if raw.Curve == elliptic.P256() {
+			return jwa.ES256
+		}
+	case *ecdsa.PublicKey:
+		if raw.Curve == elliptic.P256() {
+			return jwa.ES256
+		}
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return jwa.EdDSA
+	}
+	return ""
+}
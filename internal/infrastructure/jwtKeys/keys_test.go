@@ -0,0 +1,220 @@
+package jwtKeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKey saves the private key, or its public key if public is set, as a PEM file.
+func writeKey(t *testing.T, key crypto.Signer, public bool) string {
+	t.Helper()
+	var (
+		der   []byte
+		err   error
+		block = "PRIVATE KEY"
+	)
+	if public {
+		der, err = x509.MarshalPKIXPublicKey(key.Public())
+		block = "PUBLIC KEY"
+	} else {
+		der, err = x509.MarshalPKCS8PrivateKey(key)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := pem.Encode(f, &pem.Block{Type: block, Bytes: der}); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func sign(t *testing.T, k *Keys) string {
+	t.Helper()
+	_, token, err := k.JWTAuth().Encode(map[string]interface{}{
+		"sub": "alice",
+		"exp": time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestKeys_Algorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for alg, key := range map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey} {
+		t.Run(alg, func(t *testing.T) {
+			k, err := New(alg, "", writeKey(t, key, false), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token := sign(t, k)
+			msg, err := jws.Parse([]byte(token))
+			if err != nil {
+				t.Fatal(err)
+			}
+			headers := msg.Signatures()[0].ProtectedHeaders()
+			if headers.Algorithm().String() != alg || headers.KeyID() == "" {
+				t.Errorf("headers alg = %v, kid = %q", headers.Algorithm(), headers.KeyID())
+			}
+			if _, err := k.Verify(token); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+
+			set, err := k.JWKS()
+			if err != nil {
+				t.Fatal(err)
+			}
+			published, ok := set.LookupKeyID(headers.KeyID())
+			if !ok || published.Algorithm().String() != alg {
+				t.Fatalf("JWKS() lacks the signing key %q", headers.KeyID())
+			}
+			if isPrivate(published) {
+				t.Error("JWKS() publishes a private key")
+			}
+		})
+	}
+}
+
+func TestKeys_Rotation(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	strangerKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	before, err := New("RS256", "", writeKey(t, oldKey, false), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken := sign(t, before)
+
+	after, err := New("EdDSA", "", writeKey(t, newKey, false), []string{writeKey(t, oldKey, true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.Verify(oldToken); err != nil {
+		t.Errorf("Verify() of a token signed with the previous key error = %v", err)
+	}
+	if _, err := after.Verify(sign(t, after)); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if set, _ := after.JWKS(); set.Len() != 2 {
+		t.Errorf("JWKS() has %d keys, want 2", set.Len())
+	}
+
+	stranger, err := New("ES256", "", writeKey(t, strangerKey, false), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.Verify(sign(t, stranger)); !errors.Is(err, jwtauth.ErrUnauthorized) {
+		t.Errorf("Verify() of a token of an unknown key error = %v, wantErr %v", err, jwtauth.ErrUnauthorized)
+	}
+	hmac, _ := New("HS256", "secret", "", nil)
+	if _, err := after.Verify(sign(t, hmac)); !errors.Is(err, jwtauth.ErrUnauthorized) {
+		t.Errorf("Verify() of an HS256 token error = %v, wantErr %v", err, jwtauth.ErrUnauthorized)
+	}
+}
+
+func TestKeys_HS256(t *testing.T) {
+	k, err := New("HS256", "secret", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the tokens issued before the keys were introduced have no kid
+	_, token, _ := jwtauth.New("HS256", []byte("secret"), nil).Encode(map[string]interface{}{"sub": "alice"})
+	if _, err := k.Verify(token); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	_, expired, _ := k.JWTAuth().Encode(map[string]interface{}{"exp": time.Now().Add(-time.Minute)})
+	if _, err := k.Verify(expired); !errors.Is(err, jwtauth.ErrExpired) {
+		t.Errorf("Verify() of an expired token error = %v, wantErr %v", err, jwtauth.ErrExpired)
+	}
+	set, _ := k.JWKS()
+	if b, _ := json.Marshal(set); string(b) != `{"keys":[]}` {
+		t.Errorf("JWKS() = %s, the secret must not be published", b)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaPath := writeKey(t, rsaKey, false)
+
+	tests := []struct {
+		name             string
+		alg              string
+		secret           string
+		signingKey       string
+		verificationKeys []string
+		wantErr          error
+	}{
+		{name: "unknown algorithm", alg: "none", wantErr: ErrUnsupportedAlgorithm},
+		{name: "HS256 without secret", alg: "HS256", wantErr: ErrNoSecret},
+		{name: "no signing key", alg: "RS256", wantErr: ErrNoSigningKey},
+		{name: "public signing key", alg: "RS256", signingKey: writeKey(t, rsaKey, true), wantErr: ErrNotPrivateKey},
+		{name: "algorithm mismatch", alg: "ES256", signingKey: rsaPath, wantErr: ErrKeyMismatch},
+		{name: "signing key listed again", alg: "RS256", signingKey: rsaPath, verificationKeys: []string{rsaPath}, wantErr: ErrDuplicateKey},
+		{name: "missing file", alg: "RS256", signingKey: filepath.Join(t.TempDir(), "missing.pem"), wantErr: os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.alg, tt.secret, tt.signingKey, tt.verificationKeys)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeys_IssuerAudience(t *testing.T) {
+	k, err := New("HS256", "secret", "", nil, jwt.WithIssuer("geo.prod"), jwt.WithAudience("geo-api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr error
+	}{
+		{name: "valid", claims: map[string]interface{}{"iss": "geo.prod", "aud": "geo-api"}},
+		{name: "one of the audiences", claims: map[string]interface{}{"iss": "geo.prod", "aud": []string{"billing", "geo-api"}}},
+		{name: "another issuer", claims: map[string]interface{}{"iss": "geo.staging", "aud": "geo-api"}, wantErr: ErrInvalidIssuer},
+		{name: "no issuer", claims: map[string]interface{}{"aud": "geo-api"}, wantErr: ErrInvalidIssuer},
+		{name: "another audience", claims: map[string]interface{}{"iss": "geo.prod", "aud": "billing"}, wantErr: ErrInvalidAudience},
+		{name: "no audience", claims: map[string]interface{}{"iss": "geo.prod"}, wantErr: ErrInvalidAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["exp"] = time.Now().Add(time.Minute)
+			_, token, err := k.JWTAuth().Encode(tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := k.Verify(token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
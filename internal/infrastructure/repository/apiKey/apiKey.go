@@ -0,0 +1,66 @@
+package apiKey
+
+import (
+	"errors"
+	"geo/db/apiKeyStorage"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("api key not found")
+	ErrAlreadyExists = errors.New("api key already exists")
+)
+
+type Storage interface {
+	Add(k apiKeyStorage.Key) error
+	Get(owner, id string) (apiKeyStorage.Key, error)
+	ByHash(hash string, usedAt time.Time) (apiKeyStorage.Key, error)
+	List(owner string) ([]apiKeyStorage.Key, error)
+	SetLabel(owner, id, label string) (apiKeyStorage.Key, error)
+	Delete(owner, id string) error
+}
+
+type Repository struct {
+	storage Storage
+}
+
+func New(s Storage) *Repository {
+	return &Repository{s}
+}
+
+func (kr *Repository) Add(k apiKeyStorage.Key) error {
+	return storageErr(kr.storage.Add(k))
+}
+
+func (kr *Repository) Get(owner, id string) (apiKeyStorage.Key, error) {
+	k, err := kr.storage.Get(owner, id)
+	return k, storageErr(err)
+}
+
+func (kr *Repository) ByHash(hash string, usedAt time.Time) (apiKeyStorage.Key, error) {
+	k, err := kr.storage.ByHash(hash, usedAt)
+	return k, storageErr(err)
+}
+
+func (kr *Repository) List(owner string) ([]apiKeyStorage.Key, error) {
+	keys, err := kr.storage.List(owner)
+	return keys, storageErr(err)
+}
+
+func (kr *Repository) SetLabel(owner, id, label string) (apiKeyStorage.Key, error) {
+	k, err := kr.storage.SetLabel(owner, id, label)
+	return k, storageErr(err)
+}
+
+func (kr *Repository) Delete(owner, id string) error {
+	return storageErr(kr.storage.Delete(owner, id))
+}
+
+func storageErr(err error) error {
+	if errors.Is(err, apiKeyStorage.ErrNotFound) {
+		return ErrNotFound
+	} else if errors.Is(err, apiKeyStorage.ErrAlreadyExists) {
+		return ErrAlreadyExists
+	}
+	return err
+}
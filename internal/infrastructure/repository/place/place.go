@@ -0,0 +1,58 @@
+package place
+
+import (
+	"errors"
+	"geo/db/placeStorage"
+)
+
+var (
+	ErrNotFound      = errors.New("place not found")
+	ErrAlreadyExists = errors.New("place already exists")
+)
+
+type Storage interface {
+	Add(p placeStorage.Place) error
+	Get(owner, id string) (placeStorage.Place, error)
+	List(owner string) ([]placeStorage.Place, error)
+	Update(p placeStorage.Place) error
+	Delete(owner, id string) error
+}
+
+type Repository struct {
+	storage Storage
+}
+
+func New(s Storage) *Repository {
+	return &Repository{s}
+}
+
+func (pr *Repository) Add(p placeStorage.Place) error {
+	return storageErr(pr.storage.Add(p))
+}
+
+func (pr *Repository) Get(owner, id string) (placeStorage.Place, error) {
+	p, err := pr.storage.Get(owner, id)
+	return p, storageErr(err)
+}
+
+func (pr *Repository) List(owner string) ([]placeStorage.Place, error) {
+	places, err := pr.storage.List(owner)
+	return places, storageErr(err)
+}
+
+func (pr *Repository) Update(p placeStorage.Place) error {
+	return storageErr(pr.storage.Update(p))
+}
+
+func (pr *Repository) Delete(owner, id string) error {
+	return storageErr(pr.storage.Delete(owner, id))
+}
+
+func storageErr(err error) error {
+	if errors.Is(err, placeStorage.ErrNotFound) {
+		return ErrNotFound
+	} else if errors.Is(err, placeStorage.ErrAlreadyExists) {
+		return ErrAlreadyExists
+	}
+	return err
+}
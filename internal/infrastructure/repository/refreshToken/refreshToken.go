@@ -0,0 +1,67 @@
+package refreshToken
+
+import (
+	"errors"
+	"geo/db/refreshTokenStorage"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("refresh token not found")
+	ErrAlreadyExists = errors.New("refresh token already exists")
+	ErrUsed          = errors.New("refresh token already used")
+	ErrRevoked       = errors.New("refresh token family revoked")
+)
+
+type Storage interface {
+	Add(t refreshTokenStorage.Token) error
+	Rotate(hash, nextHash string, expiresAt time.Time) (refreshTokenStorage.Token, error)
+	AddAccessToken(family, jti string, exp time.Time) error
+	FamilyOf(jti string) (string, error)
+	RevokeFamily(family string) ([]refreshTokenStorage.AccessToken, error)
+}
+
+type Repository struct {
+	storage Storage
+}
+
+func New(s Storage) *Repository {
+	return &Repository{s}
+}
+
+func (r *Repository) Add(t refreshTokenStorage.Token) error {
+	return storageErr(r.storage.Add(t))
+}
+
+func (r *Repository) Rotate(hash, nextHash string, expiresAt time.Time) (refreshTokenStorage.Token, error) {
+	t, err := r.storage.Rotate(hash, nextHash, expiresAt)
+	return t, storageErr(err)
+}
+
+func (r *Repository) AddAccessToken(family, jti string, exp time.Time) error {
+	return storageErr(r.storage.AddAccessToken(family, jti, exp))
+}
+
+func (r *Repository) FamilyOf(jti string) (string, error) {
+	family, err := r.storage.FamilyOf(jti)
+	return family, storageErr(err)
+}
+
+func (r *Repository) RevokeFamily(family string) ([]refreshTokenStorage.AccessToken, error) {
+	access, err := r.storage.RevokeFamily(family)
+	return access, storageErr(err)
+}
+
+func storageErr(err error) error {
+	switch {
+	case errors.Is(err, refreshTokenStorage.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, refreshTokenStorage.ErrAlreadyExists):
+		return ErrAlreadyExists
+	case errors.Is(err, refreshTokenStorage.ErrUsed):
+		return ErrUsed
+	case errors.Is(err, refreshTokenStorage.ErrRevoked):
+		return ErrRevoked
+	}
+	return err
+}
@@ -15,6 +15,9 @@ var (
 type Storage interface {
 	Register(login, password string) error
 	Login(login, password string) error
+	UserRoles(login string) ([]string, error)
+	Grant(login, role string) error
+	Revoke(login, role string) error
 }
 
 type Repository struct {
@@ -44,3 +47,27 @@ func (ur *Repository) LoginUser(login, password string) error {
 	}
 	return err
 }
+
+func (ur *Repository) Roles(login string) ([]string, error) {
+	roles, err := ur.storage.UserRoles(login)
+	if errors.Is(err, userStorage.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return roles, err
+}
+
+func (ur *Repository) GrantRole(login, role string) error {
+	err := ur.storage.Grant(login, role)
+	if errors.Is(err, userStorage.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (ur *Repository) RevokeRole(login, role string) error {
+	err := ur.storage.Revoke(login, role)
+	if errors.Is(err, userStorage.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
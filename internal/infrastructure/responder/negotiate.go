@@ -0,0 +1,111 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatJSON is the format query value selecting JSON.
+const formatJSON = "json"
+
+var (
+	ErrUnknownFormat      = errors.New("unknown format")
+	ErrFormatNotAvailable = errors.New("format not available for this response")
+)
+
+// Encoder writes responses in a media type other than JSON.
+type Encoder interface {
+	// Format is the short name selecting the encoder with the format query parameter, e.g. csv
+	Format() string
+	MediaType() string
+	// Accepts reports whether data has a representation in the media type
+	Accepts(data interface{}) bool
+	Encode(w io.Writer, data interface{}) error
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media ranges of an Accept header by descending quality,
+// ranges of equal quality keep the order of the header.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges
+}
+
+// CheckFormat returns ErrUnknownFormat if the format query parameter of req names no known format.
+func (r *Respond) CheckFormat(req *http.Request) error {
+	format := req.URL.Query().Get("format")
+	if format == "" || format == formatJSON {
+		return nil
+	}
+	for _, enc := range r.encoders {
+		if enc.Format() == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// encoder returns the encoder of the format query parameter of req or negotiates one with the Accept header,
+// nil means JSON. A format that cannot represent data is an error, unlike a media type of the Accept header.
+func (r *Respond) encoder(req *http.Request, data interface{}) (Encoder, error) {
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		return r.negotiate(req.Header.Get("Accept"), data), nil
+	}
+	if err := r.CheckFormat(req); err != nil {
+		return nil, err
+	}
+	if format == formatJSON {
+		return nil, nil
+	}
+	for _, enc := range r.encoders {
+		if enc.Format() == format && enc.Accepts(data) {
+			return enc, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFormatNotAvailable, format)
+}
+
+// negotiate returns the encoder of the most preferred media type that can represent data,
+// nil means JSON. JSON is also used when no acceptable media type can represent data.
+func (r *Respond) negotiate(accept string, data interface{}) Encoder {
+	for _, ar := range parseAccept(accept) {
+		switch ar.mediaType {
+		case "application/json", "application/*", "*/*":
+			return nil
+		}
+		for _, enc := range r.encoders {
+			if enc.MediaType() == ar.mediaType && enc.Accepts(data) {
+				return enc
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package responder
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+type textEncoder struct{}
+
+func (textEncoder) Format() string { return "text" }
+
+func (textEncoder) MediaType() string { return "text/plain" }
+
+func (textEncoder) Accepts(data interface{}) bool {
+	_, ok := data.(fmt.Stringer)
+	return ok
+}
+
+func (textEncoder) Encode(w io.Writer, data interface{}) error {
+	_, err := io.WriteString(w, data.(fmt.Stringer).String())
+	return err
+}
+
+type stringer struct{}
+
+func (stringer) String() string { return "text" }
+
+func TestRespond_negotiate(t *testing.T) {
+	r := &Respond{encoders: []Encoder{textEncoder{}}}
+
+	tests := []struct {
+		name   string
+		accept string
+		data   interface{}
+		want   Encoder
+	}{
+		{name: "no header", data: stringer{}},
+		{name: "media type", accept: "text/plain", data: stringer{}, want: textEncoder{}},
+		{name: "parameters", accept: "Text/Plain; charset=utf-8", data: stringer{}, want: textEncoder{}},
+		{name: "json first", accept: "application/json, text/plain", data: stringer{}},
+		{name: "quality", accept: "application/json;q=0.5, text/plain", data: stringer{}, want: textEncoder{}},
+		{name: "refused", accept: "text/plain;q=0, */*", data: stringer{}},
+		{name: "no representation", accept: "text/plain", data: struct{}{}},
+		{name: "unknown media type", accept: "application/xml, text/plain;q=0.1", data: stringer{}, want: textEncoder{}},
+		{name: "malformed", accept: "text/plain;q=x, ;;", data: stringer{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.negotiate(tt.accept, tt.data); got != tt.want {
+				t.Errorf("negotiate(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
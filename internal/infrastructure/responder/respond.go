@@ -3,6 +3,7 @@ package responder
 import (
 	"context"
 	"errors"
+	"geo/internal/lib/api/validation"
 	"geo/internal/lib/logger/sl"
 	"github.com/ptflp/godecoder"
 	"log/slog"
@@ -11,12 +12,21 @@ import (
 
 type Responder interface {
 	OutputJSON(w http.ResponseWriter, responseData interface{})
+	// Output writes responseData in the format query parameter of req
+	// or in the media type negotiated with its Accept header
+	Output(w http.ResponseWriter, req *http.Request, responseData interface{})
+	// CheckFormat lets handlers reject an unknown format before doing any work
+	CheckFormat(req *http.Request) error
 	Created(w http.ResponseWriter, message string)
+	// OutputCreated writes responseData as JSON with the 201 status
+	OutputCreated(w http.ResponseWriter, responseData interface{})
 
 	ErrorUnauthorized(w http.ResponseWriter, err error)
 	ErrorBadRequest(w http.ResponseWriter, err error)
 	ErrorForbidden(w http.ResponseWriter, err error)
+	ErrorNotFound(w http.ResponseWriter, err error)
 	ErrorInternal(w http.ResponseWriter, err error)
+	ErrorGatewayTimeout(w http.ResponseWriter, err error)
 }
 
 type Response struct {
@@ -28,10 +38,12 @@ type Response struct {
 type Respond struct {
 	log *slog.Logger
 	godecoder.Decoder
+	encoders []Encoder
 }
 
-func NewResponder(decoder godecoder.Decoder, logger *slog.Logger) Responder {
-	return &Respond{log: logger, Decoder: decoder}
+// NewResponder returns a responder writing JSON and the media types of encoders.
+func NewResponder(decoder godecoder.Decoder, logger *slog.Logger, encoders ...Encoder) Responder {
+	return &Respond{log: logger, Decoder: decoder, encoders: encoders}
 }
 
 func (r *Respond) OutputJSON(w http.ResponseWriter, responseData interface{}) {
@@ -41,14 +53,38 @@ func (r *Respond) OutputJSON(w http.ResponseWriter, responseData interface{}) {
 	}
 }
 
+func (r *Respond) Output(w http.ResponseWriter, req *http.Request, responseData interface{}) {
+	w.Header().Add("Vary", "Accept")
+	enc, err := r.encoder(req, responseData)
+	if err != nil {
+		r.ErrorBadRequest(w, err)
+		return
+	}
+	if enc == nil {
+		r.OutputJSON(w, responseData)
+		return
+	}
+	w.Header().Set("Content-Type", enc.MediaType()+";charset=utf-8")
+	if err := enc.Encode(w, responseData); err != nil {
+		r.log.Error("responder encode error", slog.String("media_type", enc.MediaType()), sl.Err(err))
+	}
+}
+
+// ErrorBadRequest responds with the error message, the problems
+// of the request fields are sent as data if err holds them.
 func (r *Respond) ErrorBadRequest(w http.ResponseWriter, err error) {
 	r.log.Info("http response bad request status code")
+	var data interface{}
+	var vErr *validation.Error
+	if errors.As(err, &vErr) {
+		data = vErr
+	}
 	w.Header().Set("Content-Type", "application/json;charset=utf-8")
 	w.WriteHeader(http.StatusBadRequest)
 	if err := r.Encode(w, Response{
 		Success: false,
 		Message: err.Error(),
-		Data:    nil,
+		Data:    data,
 	}); err != nil {
 		r.log.Info("response writer error on write", sl.Err(err))
 	}
@@ -67,6 +103,19 @@ func (r *Respond) ErrorForbidden(w http.ResponseWriter, err error) {
 	}
 }
 
+func (r *Respond) ErrorNotFound(w http.ResponseWriter, err error) {
+	r.log.Info("http response not found")
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	if err := r.Encode(w, Response{
+		Success: false,
+		Message: err.Error(),
+		Data:    nil,
+	}); err != nil {
+		r.log.Error("response writer error on write", sl.Err(err))
+	}
+}
+
 func (r *Respond) ErrorUnauthorized(w http.ResponseWriter, err error) {
 	r.log.Warn("http responce Unauthorized")
 	w.Header().Set("Content-Type", "application/json;charset=utf-8")
@@ -96,6 +145,19 @@ func (r *Respond) ErrorInternal(w http.ResponseWriter, err error) {
 	}
 }
 
+func (r *Respond) ErrorGatewayTimeout(w http.ResponseWriter, err error) {
+	r.log.Warn("http response gateway timeout")
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(http.StatusGatewayTimeout)
+	if err := r.Encode(w, Response{
+		Success: false,
+		Message: err.Error(),
+		Data:    nil,
+	}); err != nil {
+		r.log.Error("response writer error on write", sl.Err(err))
+	}
+}
+
 func (r *Respond) Created(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json;charset=utf-8")
 	w.WriteHeader(http.StatusCreated)
@@ -107,3 +169,11 @@ func (r *Respond) Created(w http.ResponseWriter, message string) {
 		r.log.Error("response writer error on write", sl.Err(err))
 	}
 }
+
+func (r *Respond) OutputCreated(w http.ResponseWriter, responseData interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	if err := r.Encode(w, responseData); err != nil {
+		r.log.Error("responder json encode error", sl.Err(err))
+	}
+}
@@ -3,7 +3,7 @@ package JWTAuthTokenGenerator
 import (
 	"fmt"
 	"geo/internal/infrastructure/tokenGenerator"
-	"github.com/brianvoe/gofakeit/v6"
+	"geo/internal/lib/uuid"
 	"github.com/go-chi/jwtauth/v5"
 	"time"
 )
@@ -11,26 +11,37 @@ import (
 type JWTAuth struct {
 	TokenAuth     *jwtauth.JWTAuth
 	tokenLiveTime time.Duration
+	issuer        string
+	audience      string
 }
 
-func New(tokenAuth *jwtauth.JWTAuth, tokenLiveTime time.Duration) *JWTAuth {
+// New returns a generator of tokens issued by issuer for audience, the verifiers reject the
+// tokens of other issuers and audiences so that environments sharing a key stay apart.
+func New(tokenAuth *jwtauth.JWTAuth, tokenLiveTime time.Duration, issuer, audience string) *JWTAuth {
 	return &JWTAuth{
 		TokenAuth:     tokenAuth,
 		tokenLiveTime: tokenLiveTime,
+		issuer:        issuer,
+		audience:      audience,
 	}
 }
 
-func (m *JWTAuth) Generate(userLogin string) (string, error) {
+// Generate signs an access token of the user carrying the roles granted to them.
+func (m *JWTAuth) Generate(userLogin string, roles []string) (*tokenGenerator.Token, error) {
+	now := time.Now().UTC()
+	jti := uuid.New()
+	exp := now.Add(m.tokenLiveTime)
 	_, tokenString, err := m.TokenAuth.Encode(map[string]interface{}{
-		"iss": "localhost:8080",
-		"sub": userLogin,
-		"aud": "localhost:8080",
-		"iat": time.Now().UTC().Unix(),
-		"exp": time.Now().UTC().Add(m.tokenLiveTime).Unix(),
-		"jti": gofakeit.UUID(),
+		"iss":   m.issuer,
+		"sub":   userLogin,
+		"aud":   m.audience,
+		"iat":   now.Unix(),
+		"exp":   exp.Unix(),
+		"jti":   jti,
+		"roles": roles,
 	})
 	if err != nil {
-		return "", fmt.Errorf("%w: %v, login \"%v\"", tokenGenerator.GenerationError, err, userLogin)
+		return nil, fmt.Errorf("%w: %v, login \"%v\"", tokenGenerator.GenerationError, err, userLogin)
 	}
-	return tokenString, nil
+	return &tokenGenerator.Token{Value: tokenString, JTI: jti, ExpiresAt: exp.Truncate(time.Second)}, nil
 }
@@ -3,6 +3,7 @@ package JWTAuthTokenGenerator
 import (
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -11,7 +12,7 @@ import (
 func TestManager_Generate(t *testing.T) {
 	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil,
 		jwt.WithAcceptableSkew(time.Second))
-	m := New(tokenAuth, time.Second*30)
+	m := New(tokenAuth, time.Second*30, "geo.staging", "geo-api")
 
 	type args struct {
 		userLogin string
@@ -31,13 +32,27 @@ func TestManager_Generate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := m.Generate(tt.args.userLogin)
+			got, err := m.Generate(tt.args.userLogin, []string{"user"})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !strings.Contains(got, ".") {
-				t.Errorf("Generate() does not contain .\n got = \n%s", got)
+			if !strings.Contains(got.Value, ".") {
+				t.Errorf("Generate() does not contain .\n got = \n%s", got.Value)
+			}
+			token, err := tokenAuth.Decode(got.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if roles, _ := token.Get("roles"); !reflect.DeepEqual(roles, []interface{}{"user"}) {
+				t.Errorf("Generate() roles = %v, want [user]", roles)
+			}
+			if token.Issuer() != "geo.staging" || !reflect.DeepEqual(token.Audience(), []string{"geo-api"}) {
+				t.Errorf("Generate() iss = %v, aud = %v, want geo.staging, [geo-api]", token.Issuer(), token.Audience())
+			}
+			if token.JwtID() != got.JTI || !token.Expiration().Equal(got.ExpiresAt) {
+				t.Errorf("Generate() jti = %v, exp = %v, want the claims %v, %v",
+					got.JTI, got.ExpiresAt, token.JwtID(), token.Expiration())
 			}
 		})
 	}
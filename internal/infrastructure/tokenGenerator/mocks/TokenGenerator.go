@@ -2,34 +2,40 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	tokenGenerator "geo/internal/infrastructure/tokenGenerator"
+)
 
 // TokenGenerator is an autogenerated mock type for the TokenGenerator type
 type TokenGenerator struct {
 	mock.Mock
 }
 
-// Generate provides a mock function with given fields: userLogin
-func (_m *TokenGenerator) Generate(userLogin string) (string, error) {
-	ret := _m.Called(userLogin)
+// Generate provides a mock function with given fields: userLogin, roles
+func (_m *TokenGenerator) Generate(userLogin string, roles []string) (*tokenGenerator.Token, error) {
+	ret := _m.Called(userLogin, roles)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Generate")
 	}
 
-	var r0 string
+	var r0 *tokenGenerator.Token
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(userLogin)
+	if rf, ok := ret.Get(0).(func(string, []string) (*tokenGenerator.Token, error)); ok {
+		return rf(userLogin, roles)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(userLogin)
+	if rf, ok := ret.Get(0).(func(string, []string) *tokenGenerator.Token); ok {
+		r0 = rf(userLogin, roles)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tokenGenerator.Token)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(userLogin)
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(userLogin, roles)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,7 +1,17 @@
 package tokenGenerator
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	GenerationError = errors.New("token generation error")
 )
+
+// Token is a signed access token along with the claims it is tracked by.
+type Token struct {
+	Value     string
+	JTI       string
+	ExpiresAt time.Time
+}
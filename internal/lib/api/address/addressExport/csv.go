@@ -0,0 +1,67 @@
+package addressExport
+
+import (
+	"encoding/csv"
+	"geo/internal/service/geo"
+	"io"
+	"strconv"
+)
+
+// CSV writes one row per address with a header row, batch exports
+// start with the item index and end with the item error.
+type CSV struct{}
+
+func (CSV) Format() string {
+	return "csv"
+}
+
+func (CSV) MediaType() string {
+	return "text/csv"
+}
+
+func (CSV) Accepts(data interface{}) bool {
+	return accepts(data)
+}
+
+func (CSV) Encode(w io.Writer, data interface{}) error {
+	rows, batch := data.(Table).ExportRows()
+	cw := csv.NewWriter(w)
+
+	header := make([]string, 0, len(fields)+4)
+	if batch {
+		header = append(header, "item")
+	}
+	for _, f := range fields {
+		header = append(header, f.name)
+	}
+	header = append(header, "lat", "lon")
+	if batch {
+		header = append(header, "error")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, 0, len(header))
+		if batch {
+			record = append(record, strconv.Itoa(row.Item))
+		}
+		a := row.Address
+		if a == nil {
+			a = &geo.Address{}
+		}
+		for _, f := range fields {
+			record = append(record, f.value(a))
+		}
+		record = append(record, a.Lat, a.Lon)
+		if batch {
+			record = append(record, row.Error)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
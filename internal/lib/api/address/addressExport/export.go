@@ -0,0 +1,77 @@
+package addressExport
+
+import (
+	"geo/internal/service/geo"
+	"strconv"
+	"strings"
+)
+
+// Row is one exported address. Batch rows carry the index of their item,
+// an item without addresses gives a row with a nil Address.
+type Row struct {
+	Item    int
+	Address *geo.Address
+	Error   string
+}
+
+// Table is implemented by the responses that can be exported.
+type Table interface {
+	// ExportRows returns the rows to export, batch is set when the rows belong to batch items
+	ExportRows() (rows []Row, batch bool)
+}
+
+func accepts(data interface{}) bool {
+	_, ok := data.(Table)
+	return ok
+}
+
+// line returns the address in one line.
+func line(a *geo.Address) string {
+	if a.Value != "" {
+		return a.Value
+	}
+	var parts []string
+	for _, p := range []string{a.City, a.Street, a.House} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// coordinates returns the coordinates of a, ok is false when they are missing or invalid.
+func coordinates(a *geo.Address) (lat, lon string, ok bool) {
+	latF, err := strconv.ParseFloat(a.Lat, 64)
+	if err != nil {
+		return "", "", false
+	}
+	lonF, err := strconv.ParseFloat(a.Lon, 64)
+	if err != nil {
+		return "", "", false
+	}
+	p := geo.Point{Lat: latF, Lon: lonF}
+	if p.Validate() != nil {
+		return "", "", false
+	}
+	return p.LatString(), p.LonString(), true
+}
+
+// fields are the address fields exported besides the coordinates, in column order.
+var fields = []struct {
+	name  string
+	value func(a *geo.Address) string
+}{
+	{"value", func(a *geo.Address) string { return a.Value }},
+	{"postal_code", func(a *geo.Address) string { return a.PostalCode }},
+	{"country", func(a *geo.Address) string { return a.Country }},
+	{"region", func(a *geo.Address) string { return a.Region }},
+	{"city", func(a *geo.Address) string { return a.City }},
+	{"street", func(a *geo.Address) string { return a.Street }},
+	{"house", func(a *geo.Address) string { return a.House }},
+	{"fias_id", func(a *geo.Address) string { return a.FiasID }},
+	{"kladr_id", func(a *geo.Address) string { return a.KladrID }},
+	{"okato", func(a *geo.Address) string { return a.Okato }},
+	{"oktmo", func(a *geo.Address) string { return a.Oktmo }},
+	{"qc_geo", func(a *geo.Address) string { return a.QcGeo }},
+	{"timezone", func(a *geo.Address) string { return a.Timezone }},
+}
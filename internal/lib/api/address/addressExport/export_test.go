@@ -0,0 +1,112 @@
+package addressExport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"geo/internal/service/geo"
+	"testing"
+)
+
+type table struct {
+	rows  []Row
+	batch bool
+}
+
+func (t table) ExportRows() ([]Row, bool) {
+	return t.rows, t.batch
+}
+
+var (
+	snezhnaya = &geo.Address{
+		Value: "г Москва, ул Снежная, д 4", PostalCode: "129323", Country: "Россия", Region: "Москва",
+		City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907",
+	}
+	// quotes, commas, markup and a control character must survive the export
+	tricky        = &geo.Address{City: `Дом "Книги", <Арбат> & Ко`, Street: "ул\x01 Новый Арбат", House: "8", Lat: "55.7527", Lon: "37.5878"}
+	noCoordinates = &geo.Address{City: "Москва", Street: "Снежная"}
+)
+
+func TestCSV_Encode(t *testing.T) {
+	tests := []struct {
+		name  string
+		table table
+		want  string
+	}{
+		{
+			name:  "addresses",
+			table: table{rows: []Row{{Address: snezhnaya}, {Address: tricky}}},
+			want: "value,postal_code,country,region,city,street,house,fias_id,kladr_id,okato,oktmo,qc_geo,timezone,lat,lon\n" +
+				"\"г Москва, ул Снежная, д 4\",129323,Россия,Москва,Москва,Снежная,4,,,,,,,55.8481373,37.6414907\n" +
+				",,,,\"Дом \"\"Книги\"\", <Арбат> & Ко\",ул\x01 Новый Арбат,8,,,,,,,55.7527,37.5878\n",
+		},
+		{
+			name:  "batch",
+			table: table{rows: []Row{{Item: 0, Address: noCoordinates}, {Item: 1, Error: "lat: cannot be empty"}}, batch: true},
+			want: "item,value,postal_code,country,region,city,street,house,fias_id,kladr_id,okato,oktmo,qc_geo,timezone,lat,lon,error\n" +
+				"0,,,,,Москва,Снежная,,,,,,,,,,\n" +
+				"1,,,,,,,,,,,,,,,,lat: cannot be empty\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (CSV{}).Encode(&buf, tt.table); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Encode() got\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestKML_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	rows := table{rows: []Row{{Item: 0, Address: snezhnaya}, {Item: 1, Address: tricky}, {Item: 2, Address: noCoordinates}}, batch: true}
+	if err := (KML{}).Encode(&buf, rows); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var got kml
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(got.Document.Placemarks) != 2 {
+		t.Fatalf("got %d placemarks, want 2", len(got.Document.Placemarks))
+	}
+	first, second := got.Document.Placemarks[0], got.Document.Placemarks[1]
+	if first.Name != snezhnaya.Value || first.Coordinates != "37.6414907,55.8481373" {
+		t.Errorf("placemark = %+v", first)
+	}
+	if first.Data[0] != (kmlData{Name: "item", Value: "0"}) {
+		t.Errorf("first data = %+v, want the batch item", first.Data[0])
+	}
+	if want := "Дом \"Книги\", <Арбат> & Ко, ул� Новый Арбат, 8"; second.Name != want {
+		t.Errorf("name = %q, want %q", second.Name, want)
+	}
+}
+
+func TestGPX_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	rows := table{rows: []Row{{Address: snezhnaya}, {Address: tricky}, {Address: noCoordinates}, {Error: "not found"}}}
+	if err := (GPX{}).Encode(&buf, rows); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var got gpx
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	want := []gpxWaypoint{
+		{Lat: "55.8481373", Lon: "37.6414907", Name: snezhnaya.Value, Desc: "129323, Москва, Россия"},
+		{Lat: "55.7527", Lon: "37.5878", Name: "Дом \"Книги\", <Арбат> & Ко, ул� Новый Арбат, 8"},
+	}
+	if got.Version != "1.1" || len(got.Waypoints) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got.Waypoints[i] != want[i] {
+			t.Errorf("waypoint %d = %+v, want %+v", i, got.Waypoints[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package addressExport
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type gpx struct {
+	XMLName   xml.Name      `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+}
+
+type gpxWaypoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Name string `xml:"name"`
+	// Comment holds the batch item
+	Comment string `xml:"cmt,omitempty"`
+	// Desc holds the postal code, region and country
+	Desc string `xml:"desc,omitempty"`
+}
+
+// GPX writes a GPX 1.1 waypoint per address, addresses without valid coordinates are left out.
+type GPX struct{}
+
+func (GPX) Format() string {
+	return "gpx"
+}
+
+func (GPX) MediaType() string {
+	return "application/gpx+xml"
+}
+
+func (GPX) Accepts(data interface{}) bool {
+	return accepts(data)
+}
+
+func (GPX) Encode(w io.Writer, data interface{}) error {
+	rows, batch := data.(Table).ExportRows()
+	doc := gpx{Version: "1.1", Creator: "geo"}
+	for _, row := range rows {
+		if row.Address == nil {
+			continue
+		}
+		lat, lon, ok := coordinates(row.Address)
+		if !ok {
+			continue
+		}
+		wpt := gpxWaypoint{Lat: lat, Lon: lon, Name: line(row.Address)}
+		if batch {
+			wpt.Comment = "item " + strconv.Itoa(row.Item)
+		}
+		var desc []string
+		for _, v := range []string{row.Address.PostalCode, row.Address.Region, row.Address.Country} {
+			if v != "" {
+				desc = append(desc, v)
+			}
+		}
+		wpt.Desc = strings.Join(desc, ", ")
+		doc.Waypoints = append(doc.Waypoints, wpt)
+	}
+	return writeXML(w, doc)
+}
@@ -0,0 +1,83 @@
+package addressExport
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+type kml struct {
+	XMLName  xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name string    `xml:"name"`
+	Data []kmlData `xml:"ExtendedData>Data"`
+	// Coordinates are longitude, latitude
+	Coordinates string `xml:"Point>coordinates"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+// KML writes a placemark per address with the address fields as extended data,
+// addresses without valid coordinates are left out.
+type KML struct{}
+
+func (KML) Format() string {
+	return "kml"
+}
+
+func (KML) MediaType() string {
+	return "application/vnd.google-earth.kml+xml"
+}
+
+func (KML) Accepts(data interface{}) bool {
+	return accepts(data)
+}
+
+func (KML) Encode(w io.Writer, data interface{}) error {
+	rows, batch := data.(Table).ExportRows()
+	doc := kml{Document: kmlDocument{Name: "addresses"}}
+	for _, row := range rows {
+		if row.Address == nil {
+			continue
+		}
+		lat, lon, ok := coordinates(row.Address)
+		if !ok {
+			continue
+		}
+		pm := kmlPlacemark{Name: line(row.Address), Coordinates: lon + "," + lat}
+		if batch {
+			pm.Data = append(pm.Data, kmlData{Name: "item", Value: strconv.Itoa(row.Item)})
+		}
+		for _, f := range fields {
+			if v := f.value(row.Address); v != "" {
+				pm.Data = append(pm.Data, kmlData{Name: f.name, Value: v})
+			}
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, pm)
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,41 @@
+package addressResponse
+
+import (
+	"geo/internal/lib/api/address/addressExport"
+	"geo/internal/service/geo"
+)
+
+// BatchItem is the result of a single batch item, Error is set if it failed.
+type BatchItem struct {
+	Addresses []*geo.Address `json:"addresses"`
+	// Stale is set when the addresses come from an expired cache entry
+	Stale bool   `json:"stale,omitempty"`
+	Error string `json:"error,omitempty" example:"lat, lng cannot be empty"`
+} //@name AddressBatchItem
+
+// BatchResponse keeps the item results in the order of the request items.
+type BatchResponse struct {
+	Results []*BatchItem `json:"results"`
+} //@name AddressBatchResponse
+
+func NewBatchResponse(size int) *BatchResponse {
+	return &BatchResponse{
+		Results: make([]*BatchItem, size),
+	}
+}
+
+// ExportRows returns a row per address of every item, an item that failed
+// or found nothing gives a single row without an address.
+func (resp *BatchResponse) ExportRows() ([]addressExport.Row, bool) {
+	var rows []addressExport.Row
+	for i, item := range resp.Results {
+		if len(item.Addresses) == 0 {
+			rows = append(rows, addressExport.Row{Item: i, Error: item.Error})
+			continue
+		}
+		for _, a := range item.Addresses {
+			rows = append(rows, addressExport.Row{Item: i, Address: a})
+		}
+	}
+	return rows, true
+}
@@ -0,0 +1,98 @@
+package addressResponse
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"geo/internal/service/geo"
+	"io"
+	"strconv"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// BulkRow is the result of a single row of a bulk search, it holds the top match only.
+type BulkRow struct {
+	// Row is the number of the input row, the header is row 1
+	Row     int    `json:"row"`
+	City    string `json:"city"`
+	Street  string `json:"street"`
+	House   string `json:"house"`
+	Lat     string `json:"lat"`
+	Lon     string `json:"lon"`
+	Matches int    `json:"matches"`
+	Error   string `json:"error,omitempty"`
+} //@name AddressBulkRow
+
+func NewBulkRow(row int, addresses []*geo.Address) *BulkRow {
+	res := &BulkRow{Row: row, Matches: len(addresses)}
+	if len(addresses) > 0 {
+		top := addresses[0]
+		res.City, res.Street, res.House, res.Lat, res.Lon = top.City, top.Street, top.House, top.Lat, top.Lon
+	}
+	return res
+}
+
+// BulkWriter encodes bulk search rows one by one.
+type BulkWriter interface {
+	ContentType() string
+	WriteHeader() error
+	Write(row *BulkRow) error
+}
+
+// NewBulkWriter returns the writer of format, it returns false for unknown formats.
+func NewBulkWriter(w io.Writer, format string) (BulkWriter, bool) {
+	switch format {
+	case FormatCSV:
+		return &csvBulkWriter{w: csv.NewWriter(w)}, true
+	case FormatNDJSON:
+		return &ndjsonBulkWriter{enc: json.NewEncoder(w)}, true
+	default:
+		return nil, false
+	}
+}
+
+type csvBulkWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvBulkWriter) ContentType() string {
+	return "text/csv;charset=utf-8"
+}
+
+func (cw *csvBulkWriter) WriteHeader() error {
+	return cw.write([]string{"row", "city", "street", "house", "lat", "lon", "matches", "error"})
+}
+
+func (cw *csvBulkWriter) Write(row *BulkRow) error {
+	return cw.write([]string{
+		strconv.Itoa(row.Row), row.City, row.Street, row.House, row.Lat, row.Lon,
+		strconv.Itoa(row.Matches), row.Error,
+	})
+}
+
+func (cw *csvBulkWriter) write(record []string) error {
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonBulkWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonBulkWriter) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (nw *ndjsonBulkWriter) WriteHeader() error {
+	return nil
+}
+
+func (nw *ndjsonBulkWriter) Write(row *BulkRow) error {
+	return nw.enc.Encode(row)
+}
@@ -1,12 +1,21 @@
 package addressResponse
 
 import (
+	"geo/internal/lib/api/address/addressExport"
+	"geo/internal/lib/api/geojson"
 	"geo/internal/service/geo"
 	"net/http"
+	"strconv"
 )
 
+// StaleWarning is the Warning header value sent with stale responses.
+const StaleWarning = `110 - "Response is Stale"`
+
 type Response struct {
 	Addresses []*geo.Address `json:"addresses"`
+	// Stale is set when the addresses come from an expired cache entry
+	// because the provider is unavailable
+	Stale bool `json:"stale,omitempty"`
 } //@name AddressResponse
 
 func NewResponse(addresses []*geo.Address) *Response {
@@ -15,6 +24,46 @@ func NewResponse(addresses []*geo.Address) *Response {
 	}
 }
 
+// MarkStale flags the response as stale in the body and the Warning header.
+func (resp *Response) MarkStale(w http.ResponseWriter) {
+	resp.Stale = true
+	w.Header().Set("Warning", StaleWarning)
+}
+
 func (resp *Response) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
+
+// FeatureCollection returns the addresses as Point features with the address fields as properties,
+// an address without valid coordinates gets a null geometry.
+func (resp *Response) FeatureCollection() *geojson.FeatureCollection {
+	features := make([]*geojson.Feature, len(resp.Addresses))
+	for i, a := range resp.Addresses {
+		var point *geojson.Point
+		lat, latErr := strconv.ParseFloat(a.Lat, 64)
+		lon, lonErr := strconv.ParseFloat(a.Lon, 64)
+		if latErr == nil && lonErr == nil {
+			point = geojson.NewPoint(lat, lon)
+		}
+		features[i] = geojson.NewFeature(point, a)
+	}
+	return geojson.NewFeatureCollection(features)
+}
+
+func (resp *Response) ExportRows() ([]addressExport.Row, bool) {
+	rows := make([]addressExport.Row, len(resp.Addresses))
+	for i, a := range resp.Addresses {
+		rows[i] = addressExport.Row{Address: a}
+	}
+	return rows, false
+}
+
+// Suggestions is a message of the autocomplete channel, it answers the request with the same ID.
+type Suggestions struct {
+	ID          int64          `json:"id"`
+	Query       string         `json:"query"`
+	Suggestions []*geo.Address `json:"suggestions"`
+	// Stale is set when the addresses come from an expired cache entry
+	Stale bool   `json:"stale,omitempty"`
+	Error string `json:"error,omitempty"`
+} //@name AddressSuggestions
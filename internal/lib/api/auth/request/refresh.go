@@ -0,0 +1,17 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"q7Ehp3Yv0m2b8yK0Jc4n5fQ1rT9uW6xZaBcDeFgHiJk"`
+} //@name RefreshRequest
+
+func (rr *RefreshRequest) Bind(r *http.Request) error {
+	if rr.RefreshToken == "" {
+		return fmt.Errorf("invalid request")
+	}
+	return nil
+}
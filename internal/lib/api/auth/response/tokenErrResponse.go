@@ -8,21 +8,27 @@ import (
 
 // swaggerignore: true
 type TokenErrResponse struct {
-	HTTPStatusCode int    `json:"-"`
+	HTTPStatusCode int `json:"-"`
+	// Scheme of the WWW-Authenticate challenge, Bearer if empty
+	Scheme         string `json:"-"`
 	Err            string `json:"-"`
 	ErrDescription string `json:"-"`
 }
 
 func (re *TokenErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	render.Status(r, re.HTTPStatusCode)
+	scheme := re.Scheme
+	if scheme == "" {
+		scheme = "Bearer"
+	}
 	if re.Err != "" && re.ErrDescription != "" {
-		desc := fmt.Sprintf("Bearer, error=\"%s\", error_description=\"%s\"", re.Err, re.ErrDescription)
+		desc := fmt.Sprintf("%s, error=\"%s\", error_description=\"%s\"", scheme, re.Err, re.ErrDescription)
 		w.Header().Set("WWW-Authenticate", desc)
 	} else if re.Err != "" {
-		desc := fmt.Sprintf("Bearer, error=\"%s\"", re.Err)
+		desc := fmt.Sprintf("%s, error=\"%s\"", scheme, re.Err)
 		w.Header().Set("WWW-Authenticate", desc)
 	} else {
-		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.Header().Set("WWW-Authenticate", scheme)
 	}
 	return nil
 }
@@ -51,8 +57,67 @@ func ErrTokenMalformed() render.Renderer {
 	}
 }
 
+func ErrTokenIssuer() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		Err:            "invalid_token",
+		ErrDescription: "Token was issued by another issuer",
+	}
+}
+
+func ErrTokenAudience() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		Err:            "invalid_token",
+		ErrDescription: "Token was issued for another audience",
+	}
+}
+
 func ErrNoTokenProvided() render.Renderer {
 	return &TokenErrResponse{
 		HTTPStatusCode: http.StatusUnauthorized,
 	}
 }
+
+func ErrInsufficientRole() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusForbidden,
+		Err:            "insufficient_scope",
+		ErrDescription: "Token lacks the required role",
+	}
+}
+
+func ErrAPIKeyInvalid() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		Scheme:         "ApiKey",
+		Err:            "invalid_token",
+		ErrDescription: "API key is invalid or revoked",
+	}
+}
+
+func ErrAPIKeyExpired() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		Scheme:         "ApiKey",
+		Err:            "invalid_token",
+		ErrDescription: "API key expired",
+	}
+}
+
+func ErrAPIKeyScope() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusForbidden,
+		Scheme:         "ApiKey",
+		Err:            "insufficient_scope",
+		ErrDescription: "API key lacks the required scope",
+	}
+}
+
+func ErrAPIKeyNotAllowed() render.Renderer {
+	return &TokenErrResponse{
+		HTTPStatusCode: http.StatusForbidden,
+		Err:            "insufficient_scope",
+		ErrDescription: "Endpoint requires a bearer token",
+	}
+}
@@ -0,0 +1,21 @@
+package coordinate
+
+import "encoding/json"
+
+// Coordinate is a coordinate in decimal degrees sent either as a JSON number or as a string.
+type Coordinate string
+
+func (c *Coordinate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*c = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Coordinate(s)
+		return nil
+	}
+	// numbers are kept as they are, anything else fails to parse later
+	*c = Coordinate(data)
+	return nil
+}
@@ -0,0 +1,54 @@
+package distanceResponse
+
+import (
+	"geo/internal/service/distance"
+)
+
+type Response struct {
+	Method string            `json:"method" example:"haversine"`
+	From   distance.Location `json:"from"`
+	To     distance.Location `json:"to"`
+	Meters float64           `json:"distance_meters" example:"1523.4"`
+	// Bearing is the initial bearing in degrees clockwise from north
+	Bearing float64 `json:"bearing_degrees" example:"312.7"`
+	Error   string  `json:"error,omitempty"`
+} //@name DistanceResponse
+
+// NewResponse returns the single leg of a one by one matrix.
+func NewResponse(m *distance.Matrix, method distance.Method) *Response {
+	leg := m.Legs[0][0]
+	return &Response{
+		Method:  string(method),
+		From:    m.Origins[0],
+		To:      m.Destinations[0],
+		Meters:  leg.Meters,
+		Bearing: leg.Bearing,
+		Error:   leg.Error,
+	}
+}
+
+type MatrixRow struct {
+	// Legs are the legs from the origin of the row to every destination
+	Legs []distance.Leg `json:"legs"`
+} //@name DistanceMatrixRow
+
+type MatrixResponse struct {
+	Method       string              `json:"method" example:"haversine"`
+	Origins      []distance.Location `json:"origins"`
+	Destinations []distance.Location `json:"destinations"`
+	// Rows follow the order of the origins
+	Rows []MatrixRow `json:"rows"`
+} //@name DistanceMatrixResponse
+
+func NewMatrixResponse(m *distance.Matrix, method distance.Method) *MatrixResponse {
+	res := &MatrixResponse{
+		Method:       string(method),
+		Origins:      m.Origins,
+		Destinations: m.Destinations,
+		Rows:         make([]MatrixRow, len(m.Legs)),
+	}
+	for i, legs := range m.Legs {
+		res.Rows[i] = MatrixRow{Legs: legs}
+	}
+	return res
+}
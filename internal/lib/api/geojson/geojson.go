@@ -0,0 +1,68 @@
+package geojson
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// MediaType is the RFC 7946 media type.
+const MediaType = "application/geo+json"
+
+type FeatureCollection struct {
+	Type     string     `json:"type" example:"FeatureCollection"`
+	Features []*Feature `json:"features"`
+} //@name FeatureCollection
+
+type Feature struct {
+	Type string `json:"type" example:"Feature"`
+	// Geometry is null when the location of the feature is unknown
+	Geometry   *Point      `json:"geometry"`
+	Properties interface{} `json:"properties"`
+} //@name Feature
+
+type Point struct {
+	Type string `json:"type" example:"Point"`
+	// Coordinates are longitude and latitude, in that order
+	Coordinates [2]float64 `json:"coordinates"`
+} //@name GeoJSONPoint
+
+func NewFeatureCollection(features []*Feature) *FeatureCollection {
+	if features == nil {
+		features = []*Feature{}
+	}
+	return &FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// NewFeature returns a feature located at point, a nil point gives a null geometry.
+func NewFeature(point *Point, properties interface{}) *Feature {
+	return &Feature{Type: "Feature", Geometry: point, Properties: properties}
+}
+
+func NewPoint(lat, lon float64) *Point {
+	return &Point{Type: "Point", Coordinates: [2]float64{lon, lat}}
+}
+
+// Collection is implemented by the responses that have a GeoJSON representation.
+type Collection interface {
+	FeatureCollection() *FeatureCollection
+}
+
+// Encoder writes Collection responses as GeoJSON.
+type Encoder struct{}
+
+func (Encoder) Format() string {
+	return "geojson"
+}
+
+func (Encoder) MediaType() string {
+	return MediaType
+}
+
+func (Encoder) Accepts(data interface{}) bool {
+	_, ok := data.(Collection)
+	return ok
+}
+
+func (Encoder) Encode(w io.Writer, data interface{}) error {
+	return json.NewEncoder(w).Encode(data.(Collection).FeatureCollection())
+}
@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"sort"
+	"strings"
+)
+
+// Error holds the problems of the request fields by field name.
+type Error struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// Add records the problem of field, the first problem of a field is kept.
+func (e *Error) Add(field string, err error) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	if _, ok := e.Fields[field]; !ok {
+		e.Fields[field] = err.Error()
+	}
+}
+
+// Err returns e if any problem is recorded and nil otherwise.
+func (e *Error) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for f := range e.Fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	msgs := make([]string, len(fields))
+	for i, f := range fields {
+		msgs[i] = f + ": " + e.Fields[f]
+	}
+	return strings.Join(msgs, "; ")
+}
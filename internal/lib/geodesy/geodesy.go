@@ -0,0 +1,113 @@
+// Package geodesy computes distances and bearings between points given in WGS 84 degrees.
+package geodesy
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	// EarthRadius is the mean radius of the Earth in meters
+	EarthRadius = 6371008.8
+
+	// WGS 84 ellipsoid
+	semiMajorAxis = 6378137.0
+	flattening    = 1 / 298.257223563
+	semiMinorAxis = (1 - flattening) * semiMajorAxis
+
+	vincentyIterations = 200
+	vincentyTolerance  = 1e-12
+)
+
+var (
+	// ErrNoConvergence is returned by Vincenty for nearly antipodal points
+	ErrNoConvergence = errors.New("vincenty formula failed to converge")
+)
+
+// Haversine returns the great-circle distance in meters on a sphere of EarthRadius.
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	φ1, φ2 := radians(lat1), radians(lat2)
+	Δφ, Δλ := φ2-φ1, radians(lon2-lon1)
+	h := math.Pow(math.Sin(Δφ/2), 2) + math.Cos(φ1)*math.Cos(φ2)*math.Pow(math.Sin(Δλ/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Bearing returns the initial great-circle bearing from the first point to the second
+// in degrees clockwise from north, in the range [0, 360).
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	φ1, φ2 := radians(lat1), radians(lat2)
+	Δλ := radians(lon2 - lon1)
+	y := math.Sin(Δλ) * math.Cos(φ2)
+	x := math.Cos(φ1)*math.Sin(φ2) - math.Sin(φ1)*math.Cos(φ2)*math.Cos(Δλ)
+	return normalize(degrees(math.Atan2(y, x)))
+}
+
+// Vincenty returns the geodesic distance in meters on the WGS 84 ellipsoid
+// and the initial bearing in degrees, using the inverse Vincenty formula.
+func Vincenty(lat1, lon1, lat2, lon2 float64) (float64, float64, error) {
+	if lat1 == lat2 && lon1 == lon2 {
+		return 0, 0, nil
+	}
+	L := radians(lon2 - lon1)
+	U1 := math.Atan((1 - flattening) * math.Tan(radians(lat1)))
+	U2 := math.Atan((1 - flattening) * math.Tan(radians(lat2)))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	λ := L
+	var sinσ, cosσ, σ, cos2α, cos2σm, sinλ, cosλ float64
+	converged := false
+	for range vincentyIterations {
+		sinλ, cosλ = math.Sincos(λ)
+		sinσ = math.Hypot(cosU2*sinλ, cosU1*sinU2-sinU1*cosU2*cosλ)
+		if sinσ == 0 {
+			// coincident points
+			return 0, 0, nil
+		}
+		cosσ = sinU1*sinU2 + cosU1*cosU2*cosλ
+		σ = math.Atan2(sinσ, cosσ)
+		sinα := cosU1 * cosU2 * sinλ / sinσ
+		cos2α = 1 - sinα*sinα
+		cos2σm = 0
+		// equatorial line has cos2α == 0
+		if cos2α != 0 {
+			cos2σm = cosσ - 2*sinU1*sinU2/cos2α
+		}
+		C := flattening / 16 * cos2α * (4 + flattening*(4-3*cos2α))
+		prev := λ
+		λ = L + (1-C)*flattening*sinα*(σ+C*sinσ*(cos2σm+C*cosσ*(-1+2*cos2σm*cos2σm)))
+		if math.Abs(λ-prev) < vincentyTolerance {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return 0, 0, ErrNoConvergence
+	}
+
+	u2 := cos2α * (semiMajorAxis*semiMajorAxis - semiMinorAxis*semiMinorAxis) / (semiMinorAxis * semiMinorAxis)
+	A := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+	B := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+	Δσ := B * sinσ * (cos2σm + B/4*(cosσ*(-1+2*cos2σm*cos2σm)-
+		B/6*cos2σm*(-3+4*sinσ*sinσ)*(-3+4*cos2σm*cos2σm)))
+	distance := semiMinorAxis * A * (σ - Δσ)
+	bearing := normalize(degrees(math.Atan2(cosU2*sinλ, cosU1*sinU2-sinU1*cosU2*cosλ)))
+	return distance, bearing, nil
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// normalize maps an angle in degrees to [0, 360).
+func normalize(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
@@ -0,0 +1,83 @@
+package geodesy
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+		tolerance              float64
+	}{
+		{name: "same point", lat1: 55.75, lon1: 37.62, lat2: 55.75, lon2: 37.62, want: 0, tolerance: 1e-9},
+		{name: "one degree of the equator", lat1: 0, lon1: 0, lat2: 0, lon2: 1, want: 111195.08, tolerance: 0.01},
+		{name: "Moscow to Saint Petersburg", lat1: 55.7558, lon1: 37.6173, lat2: 59.9343, lon2: 30.3351, want: 633_000, tolerance: 1_000},
+		{name: "antipodes", lat1: 0, lon1: 0, lat2: 0, lon2: 180, want: math.Pi * EarthRadius, tolerance: 1e-6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Haversine(tt.lat1, tt.lon1, tt.lat2, tt.lon2); math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("Haversine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{name: "north", lat1: 0, lon1: 0, lat2: 1, lon2: 0, want: 0},
+		{name: "east", lat1: 0, lon1: 0, lat2: 0, lon2: 1, want: 90},
+		{name: "south", lat1: 1, lon1: 0, lat2: 0, lon2: 0, want: 180},
+		{name: "west", lat1: 0, lon1: 1, lat2: 0, lon2: 0, want: 270},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Bearing(tt.lat1, tt.lon1, tt.lat2, tt.lon2); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Bearing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVincenty(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+		wantBearing            float64
+		wantErr                error
+	}{
+		{name: "same point", lat1: 55.75, lon1: 37.62, lat2: 55.75, lon2: 37.62},
+		// Flinders Peak to Buninyong, the reference example of Vincenty's paper
+		{
+			name: "Flinders Peak to Buninyong",
+			lat1: -(37 + 57/60.0 + 3.72030/3600), lon1: 144 + 25/60.0 + 29.52440/3600,
+			lat2: -(37 + 39/60.0 + 10.15610/3600), lon2: 143 + 55/60.0 + 35.38390/3600,
+			want: 54972.271, wantBearing: 306 + 52/60.0 + 5.37/3600,
+		},
+		{name: "one degree of the equator", lat1: 0, lon1: 0, lat2: 0, lon2: 1, want: 111319.491, wantBearing: 90},
+		{name: "nearly antipodal", lat1: 0, lon1: 0, lat2: 0.5, lon2: 179.7, wantErr: ErrNoConvergence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, bearing, err := Vincenty(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Vincenty() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("Vincenty() distance = %v, want %v", got, tt.want)
+			}
+			if math.Abs(bearing-tt.wantBearing) > 1e-5 {
+				t.Errorf("Vincenty() bearing = %v, want %v", bearing, tt.wantBearing)
+			}
+		})
+	}
+}
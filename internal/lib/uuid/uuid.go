@@ -0,0 +1,18 @@
+package uuid
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// New returns a random RFC 9562 version 4 UUID read from crypto/rand,
+// it panics if the system random source fails.
+func New() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("uuid: cannot read random bytes: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,21 @@
+package uuid
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	v4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]struct{})
+	for range 1000 {
+		id := New()
+		if !v4.MatchString(id) {
+			t.Fatalf("New() = %q, not a version 4 UUID", id)
+		}
+		if _, ok := seen[id]; ok {
+			t.Fatalf("New() repeated %q", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
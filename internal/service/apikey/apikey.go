@@ -0,0 +1,307 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geo/db/apiKeyStorage"
+	"geo/internal/infrastructure/repository/apiKey"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/lib/uuid"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Scopes limit the parts of the API a key gives access to, a key without scopes
+// gives access to everything its owner's roles allow.
+const (
+	ScopeAddress  = "address"
+	ScopeDistance = "distance"
+	ScopePlaces   = "places"
+)
+
+const (
+	MaxLabelLength = 100
+	MaxKeys        = 20
+)
+
+var Scopes = []string{ScopeAddress, ScopeDistance, ScopePlaces}
+
+var (
+	ErrNotFound     = errors.New("api key not found")
+	ErrEmptyLabel   = errors.New("label cannot be empty")
+	ErrLabelTooLong = fmt.Errorf("label cannot be longer than %d characters", MaxLabelLength)
+	ErrInvalidScope = fmt.Errorf("scope must be one of %s", strings.Join(Scopes, ", "))
+	ErrExpired      = errors.New("api key expired")
+	ErrPastExpiry   = errors.New("expiry must be in the future")
+	ErrTooManyKeys  = fmt.Errorf("at most %d api keys are allowed", MaxKeys)
+	ErrInvalidKey   = errors.New("invalid api key")
+	ErrInternal     = errors.New("internal server error")
+)
+
+// Key is an API key as its owner sees it, the secret is shown only once on creation.
+type Key struct {
+	ID    string `json:"id" example:"1b4e28ba-2fa1-41d2-883f-0016d3cca427"`
+	Label string `json:"label" example:"nightly geocoding"`
+	// Prefix is the beginning of the secret that tells the keys apart
+	Prefix string   `json:"prefix" example:"geo_Jk3v9QpX"`
+	Scopes []string `json:"scopes" example:"address"`
+	// ExpiresAt, CreatedAt and LastUsedAt are in UTC, the keys without ExpiresAt never expire
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2025-05-01T09:30:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-05-01T09:30:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2024-05-02T09:30:00Z"`
+} //@name APIKey
+
+// NewKey is a created key along with its secret.
+type NewKey struct {
+	Key
+	// Secret is sent in the X-API-Key header or as Authorization: ApiKey <secret>
+	Secret string `json:"secret" example:"geo_Jk3v9QpXy8Wm1cZt4RbN6eLh2sUo0aVf5gDi7kQw3E"`
+} //@name NewAPIKey
+
+type Input struct {
+	Label  string
+	Scopes []string
+	// ExpiresAt is zero for a key that never expires
+	ExpiresAt time.Time
+}
+
+// Principal is who a request authenticated with an API key acts for.
+type Principal struct {
+	KeyID  string
+	Owner  string
+	Scopes []string
+	// Roles are the roles the owner has now
+	Roles []string
+	// ExpiresAt is zero for a key that never expires
+	ExpiresAt time.Time
+}
+
+// Allows tells whether the key gives access to the part of the API under scope.
+func (p *Principal) Allows(scope string) bool {
+	return len(p.Scopes) == 0 || slices.Contains(p.Scopes, scope)
+}
+
+type Storage interface {
+	Add(k apiKeyStorage.Key) error
+	Get(owner, id string) (apiKeyStorage.Key, error)
+	ByHash(hash string, usedAt time.Time) (apiKeyStorage.Key, error)
+	List(owner string) ([]apiKeyStorage.Key, error)
+	SetLabel(owner, id, label string) (apiKeyStorage.Key, error)
+	Delete(owner, id string) error
+}
+
+// UserStorage gives the roles the requests of a key are checked against.
+type UserStorage interface {
+	Roles(login string) ([]string, error)
+}
+
+type UseCase struct {
+	log          *slog.Logger
+	requestIdKey string
+	storage      Storage
+	us           UserStorage
+	now          func() time.Time
+}
+
+func New(log *slog.Logger, requestIdKey string, storage Storage, us UserStorage) *UseCase {
+	return &UseCase{log: log, requestIdKey: requestIdKey, storage: storage, us: us, now: time.Now}
+}
+
+// ValidateLabel checks the label of a key.
+func ValidateLabel(label string) error {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return ErrEmptyLabel
+	}
+	if utf8.RuneCountInString(label) > MaxLabelLength {
+		return ErrLabelTooLong
+	}
+	return nil
+}
+
+// ValidateScopes checks that every scope is known.
+func ValidateScopes(scopes []string) error {
+	for _, s := range scopes {
+		if !slices.Contains(Scopes, s) {
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
+
+// Create issues a key of owner and returns it with the secret, only the hash of the secret is kept.
+func (s *UseCase) Create(ctx context.Context, owner string, in Input) (*NewKey, error) {
+	const op = "service.apikey.Create"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if err := ValidateLabel(in.Label); err != nil {
+		return nil, err
+	}
+	if err := ValidateScopes(in.Scopes); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if !in.ExpiresAt.IsZero() && !in.ExpiresAt.After(now) {
+		return nil, ErrPastExpiry
+	}
+	keys, err := s.storage.List(owner)
+	if err != nil {
+		log.Error("failed to list api keys", sl.Err(err))
+		return nil, ErrInternal
+	}
+	if len(keys) >= MaxKeys {
+		return nil, ErrTooManyKeys
+	}
+
+	secret, hash, err := newSecret()
+	if err != nil {
+		log.Error("failed to generate api key", sl.Err(err))
+		return nil, ErrInternal
+	}
+	k := apiKeyStorage.Key{
+		ID:        uuid.New(),
+		Owner:     owner,
+		Label:     strings.TrimSpace(in.Label),
+		Hash:      hash,
+		Prefix:    secret[:prefixLength],
+		Scopes:    normalizeScopes(in.Scopes),
+		CreatedAt: now,
+	}
+	if !in.ExpiresAt.IsZero() {
+		k.ExpiresAt = in.ExpiresAt.UTC()
+	}
+	if err := s.storage.Add(k); err != nil {
+		log.Error("failed to save api key", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("api key created", slog.String("id", k.ID), slog.String("owner", owner))
+	return &NewKey{Key: *newKey(k), Secret: secret}, nil
+}
+
+// List returns the keys of owner oldest first.
+func (s *UseCase) List(ctx context.Context, owner string) ([]*Key, error) {
+	const op = "service.apikey.List"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	keys, err := s.storage.List(owner)
+	if err != nil {
+		log.Error("failed to list api keys", sl.Err(err))
+		return nil, ErrInternal
+	}
+	res := make([]*Key, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, newKey(k))
+	}
+	return res, nil
+}
+
+// Rename changes the label of a key of owner.
+func (s *UseCase) Rename(ctx context.Context, owner, id, label string) (*Key, error) {
+	const op = "service.apikey.Rename"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if err := ValidateLabel(label); err != nil {
+		return nil, err
+	}
+	k, err := s.storage.SetLabel(owner, id, strings.TrimSpace(label))
+	if errors.Is(err, apiKey.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Error("failed to rename api key", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("api key renamed", slog.String("id", id), slog.String("owner", owner))
+	return newKey(k), nil
+}
+
+// Revoke deletes a key of owner, the requests made with it are rejected from now on.
+func (s *UseCase) Revoke(ctx context.Context, owner, id string) error {
+	const op = "service.apikey.Revoke"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	err := s.storage.Delete(owner, id)
+	if errors.Is(err, apiKey.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		log.Error("failed to revoke api key", sl.Err(err))
+		return ErrInternal
+	}
+	log.Info("api key revoked", slog.String("id", id), slog.String("owner", owner))
+	return nil
+}
+
+// Authenticate returns who the secret acts for.
+func (s *UseCase) Authenticate(ctx context.Context, secret string) (*Principal, error) {
+	const op = "service.apikey.Authenticate"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	now := s.now().UTC()
+	k, err := s.storage.ByHash(hashSecret(secret), now)
+	if errors.Is(err, apiKey.ErrNotFound) {
+		return nil, ErrInvalidKey
+	} else if err != nil {
+		log.Error("failed to find api key", sl.Err(err))
+		return nil, ErrInternal
+	}
+	if !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt) {
+		log.Info("api key expired", slog.String("id", k.ID))
+		return nil, ErrExpired
+	}
+	roles, err := s.us.Roles(k.Owner)
+	if err != nil {
+		log.Error("failed to get owner roles", slog.String("id", k.ID), sl.Err(err))
+		return nil, ErrInvalidKey
+	}
+	return &Principal{KeyID: k.ID, Owner: k.Owner, Scopes: k.Scopes, Roles: roles, ExpiresAt: k.ExpiresAt}, nil
+}
+
+// normalizeScopes drops the repeated scopes.
+func normalizeScopes(scopes []string) []string {
+	res := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if !slices.Contains(res, s) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func newKey(k apiKeyStorage.Key) *Key {
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	res := &Key{
+		ID:        k.ID,
+		Label:     k.Label,
+		Prefix:    k.Prefix,
+		Scopes:    scopes,
+		CreatedAt: k.CreatedAt,
+	}
+	if !k.ExpiresAt.IsZero() {
+		res.ExpiresAt = &k.ExpiresAt
+	}
+	if !k.LastUsedAt.IsZero() {
+		res.LastUsedAt = &k.LastUsedAt
+	}
+	return res
+}
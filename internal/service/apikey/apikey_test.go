@@ -0,0 +1,142 @@
+package apikey
+
+import (
+	"context"
+	"geo/db/apiKeyStorage/inMemoryAPIKeyStorage"
+	"geo/db/userStorage/inMemoryUserStorage"
+	"geo/internal/infrastructure/repository/apiKey"
+	"geo/internal/infrastructure/repository/user"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newUseCase(t *testing.T) *UseCase {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	users := user.New(inMemoryUserStorage.New())
+	require.NoError(t, users.RegisterUser("alice", "password"))
+	require.NoError(t, users.GrantRole("alice", "user"))
+	return New(log, "request_id", apiKey.New(inMemoryAPIKeyStorage.New()), users)
+}
+
+func TestUseCase_Create(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(t)
+
+	tests := []struct {
+		name    string
+		in      Input
+		wantErr error
+	}{
+		{
+			name: "never expires",
+			in:   Input{Label: " batch ", Scopes: []string{ScopeAddress, ScopeAddress}},
+		},
+		{
+			name: "expires",
+			in:   Input{Label: "batch", ExpiresAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:    "empty label",
+			in:      Input{Label: " "},
+			wantErr: ErrEmptyLabel,
+		},
+		{
+			name:    "unknown scope",
+			in:      Input{Label: "batch", Scopes: []string{"admin"}},
+			wantErr: ErrInvalidScope,
+		},
+		{
+			name:    "expiry in the past",
+			in:      Input{Label: "batch", ExpiresAt: time.Now().Add(-time.Minute)},
+			wantErr: ErrPastExpiry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uc.Create(ctx, "alice", tt.in)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "batch", got.Label)
+			require.True(t, strings.HasPrefix(got.Secret, got.Prefix))
+			require.Equal(t, tt.in.ExpiresAt.IsZero(), got.ExpiresAt == nil)
+			if tt.in.Scopes != nil {
+				require.Equal(t, []string{ScopeAddress}, got.Scopes)
+			}
+
+			p, err := uc.Authenticate(ctx, got.Secret)
+			require.NoError(t, err)
+			require.True(t, tt.in.ExpiresAt.Equal(p.ExpiresAt), "the principal carries the key expiry")
+			p.ExpiresAt = time.Time{}
+			require.Equal(t, Principal{KeyID: got.ID, Owner: "alice", Scopes: got.Scopes, Roles: []string{"user"}}, *p)
+		})
+	}
+}
+
+func TestUseCase_Authenticate(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(t)
+	now := time.Now()
+	uc.now = func() time.Time { return now }
+
+	key, err := uc.Create(ctx, "alice", Input{Label: "batch", Scopes: []string{ScopePlaces}, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	p, err := uc.Authenticate(ctx, key.Secret)
+	require.NoError(t, err)
+	require.True(t, p.Allows(ScopePlaces))
+	require.False(t, p.Allows(ScopeAddress))
+	keys, err := uc.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].LastUsedAt)
+
+	_, err = uc.Authenticate(ctx, "geo_unknown")
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	now = now.Add(time.Hour)
+	_, err = uc.Authenticate(ctx, key.Secret)
+	require.ErrorIs(t, err, ErrExpired)
+}
+
+func TestUseCase_Owner(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(t)
+	key, err := uc.Create(ctx, "alice", Input{Label: "batch"})
+	require.NoError(t, err)
+
+	_, err = uc.Rename(ctx, "bob", key.ID, "stolen")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, uc.Revoke(ctx, "bob", key.ID), ErrNotFound)
+	keys, err := uc.List(ctx, "bob")
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	renamed, err := uc.Rename(ctx, "alice", key.ID, "nightly")
+	require.NoError(t, err)
+	require.Equal(t, "nightly", renamed.Label)
+	_, err = uc.Rename(ctx, "alice", key.ID, "")
+	require.ErrorIs(t, err, ErrEmptyLabel)
+
+	require.NoError(t, uc.Revoke(ctx, "alice", key.ID))
+	_, err = uc.Authenticate(ctx, key.Secret)
+	require.ErrorIs(t, err, ErrInvalidKey, "revoked keys are rejected")
+}
+
+func TestUseCase_MaxKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(t)
+	for i := 0; i < MaxKeys; i++ {
+		_, err := uc.Create(ctx, "alice", Input{Label: "batch"})
+		require.NoError(t, err)
+	}
+	_, err := uc.Create(ctx, "alice", Input{Label: "batch"})
+	require.ErrorIs(t, err, ErrTooManyKeys)
+}
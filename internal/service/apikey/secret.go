@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	secretPrefix = "geo_"
+	secretBytes  = 32
+	// prefixLength is the length of the beginning of a secret shown in the key list
+	prefixLength = len(secretPrefix) + 8
+)
+
+// newSecret returns the secret of a key and the hash it is stored by.
+func newSecret() (secret, hash string, err error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret = secretPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return secret, hashSecret(secret), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
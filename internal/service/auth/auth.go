@@ -3,20 +3,31 @@ package auth
 import (
 	"context"
 	"errors"
+	"geo/db/refreshTokenStorage"
+	"geo/internal/infrastructure/repository/refreshToken"
 	"geo/internal/infrastructure/repository/token"
 	"geo/internal/infrastructure/repository/user"
 	"geo/internal/infrastructure/tokenGenerator"
 	"geo/internal/lib/logger/sl"
+	"geo/internal/lib/uuid"
 	"log/slog"
 	"time"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInternal           = errors.New("internal server error")
-	ErrBadRequest         = errors.New("bad request")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInternal            = errors.New("internal server error")
+	ErrBadRequest          = errors.New("bad request")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, the session is revoked")
 )
 
+// Tokens are the access token and the refresh token it is renewed with.
+type Tokens struct {
+	Access  string
+	Refresh string
+}
+
 //go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name=Blacklister
 type Blacklister interface {
 	Add(jti string, exp time.Time) error
@@ -25,13 +36,25 @@ type Blacklister interface {
 
 //go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name=TokenGenerator
 type TokenGenerator interface {
-	Generate(userLogin string) (string, error)
+	Generate(userLogin string, roles []string) (*tokenGenerator.Token, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name=UserStorage
 type UserStorage interface {
 	LoginUser(string, string) error
 	RegisterUser(string, string) error
+	Roles(login string) ([]string, error)
+	GrantRole(login, role string) error
+	RevokeRole(login, role string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name=RefreshTokenStorage
+type RefreshTokenStorage interface {
+	Add(t refreshTokenStorage.Token) error
+	Rotate(hash, nextHash string, expiresAt time.Time) (refreshTokenStorage.Token, error)
+	AddAccessToken(family, jti string, exp time.Time) error
+	FamilyOf(jti string) (string, error)
+	RevokeFamily(family string) ([]refreshTokenStorage.AccessToken, error)
 }
 
 type UseCase struct {
@@ -40,19 +63,26 @@ type UseCase struct {
 	bl           Blacklister
 	tg           TokenGenerator
 	us           UserStorage
+	rt           RefreshTokenStorage
+	refreshTTL   time.Duration
 }
 
-func New(log *slog.Logger, requestIDKey string, bl Blacklister, tg TokenGenerator, us UserStorage) *UseCase {
+// New creates auth use case, refresh tokens live for refreshTTL since they are issued.
+func New(log *slog.Logger, requestIDKey string, bl Blacklister, tg TokenGenerator, us UserStorage,
+	rt RefreshTokenStorage, refreshTTL time.Duration) *UseCase {
 	return &UseCase{
 		log:          log,
 		requestIdKey: requestIDKey,
 		bl:           bl,
 		tg:           tg,
 		us:           us,
+		rt:           rt,
+		refreshTTL:   refreshTTL,
 	}
 }
 
-func (s *UseCase) Login(ctx context.Context, login, password string) (string, error) {
+// Login checks the credentials and starts a family of refresh tokens.
+func (s *UseCase) Login(ctx context.Context, login, password string) (*Tokens, error) {
 	const op = "service.auth.Login"
 	requestID := ctx.Value(s.requestIdKey).(string)
 	log := s.log.With(
@@ -62,17 +92,87 @@ func (s *UseCase) Login(ctx context.Context, login, password string) (string, er
 	err := s.us.LoginUser(login, password)
 	if errors.Is(err, user.ErrNotFound) {
 		log.Error("user not found", sl.Err(err))
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	} else if errors.Is(err, user.ErrIncorrectPassword) {
 		log.Error("incorrect password", sl.Err(err))
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	} else if err != nil {
 		log.Error("failed to login", sl.Err(err))
-		return "", ErrInternal
+		return nil, ErrInternal
 	}
 	log.Info("user logged in successfully", sl.Info(login))
 
-	t, err := s.tg.Generate(login)
+	refresh, hash, err := newRefreshToken()
+	if err != nil {
+		log.Error("unable to generate refresh token", sl.Err(err))
+		return nil, ErrInternal
+	}
+	family := uuid.New()
+	err = s.rt.Add(refreshTokenStorage.Token{
+		Hash:      hash,
+		Family:    family,
+		Login:     login,
+		ExpiresAt: time.Now().UTC().Add(s.refreshTTL),
+	})
+	if err != nil {
+		log.Error("failed to save refresh token", sl.Err(err))
+		return nil, ErrInternal
+	}
+
+	access, err := s.issue(log, login, family)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{Access: access, Refresh: refresh}, nil
+}
+
+// Refresh rotates the refresh token and issues a new access token. A refresh token
+// used twice revokes its family along with the access tokens issued from it.
+func (s *UseCase) Refresh(ctx context.Context, refresh string) (*Tokens, error) {
+	const op = "service.auth.Refresh"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	next, nextHash, err := newRefreshToken()
+	if err != nil {
+		log.Error("unable to generate refresh token", sl.Err(err))
+		return nil, ErrInternal
+	}
+
+	t, err := s.rt.Rotate(hashRefreshToken(refresh), nextHash, time.Now().UTC().Add(s.refreshTTL))
+	if errors.Is(err, refreshToken.ErrUsed) {
+		log.Warn("refresh token reused, revoking family", slog.String("family", t.Family), sl.Info(t.Login))
+		if err := s.revokeFamily(log, t.Family, ""); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	} else if errors.Is(err, refreshToken.ErrNotFound) || errors.Is(err, refreshToken.ErrRevoked) {
+		log.Error("invalid refresh token", sl.Err(err))
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Error("failed to rotate refresh token", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("refresh token rotated", slog.String("family", t.Family), sl.Info(t.Login))
+
+	access, err := s.issue(log, t.Login, t.Family)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{Access: access, Refresh: next}, nil
+}
+
+// issue generates an access token of login with the roles the user has now
+// and records it in the family.
+func (s *UseCase) issue(log *slog.Logger, login, family string) (string, error) {
+	roles, err := s.us.Roles(login)
+	if err != nil {
+		log.Error("failed to get user roles", sl.Err(err))
+		return "", ErrInternal
+	}
+	t, err := s.tg.Generate(login, roles)
 	if errors.Is(err, tokenGenerator.GenerationError) {
 		log.Error("error generating token", sl.Err(err))
 		return "", ErrInternal
@@ -80,11 +180,45 @@ func (s *UseCase) Login(ctx context.Context, login, password string) (string, er
 		log.Error("unable to generate token", sl.Err(err))
 		return "", ErrInternal
 	}
-	log.Info("token generated", sl.Info(t), sl.Info(login))
+	err = s.rt.AddAccessToken(family, t.JTI, t.ExpiresAt)
+	if errors.Is(err, refreshToken.ErrRevoked) {
+		// revoked since the refresh token was rotated
+		log.Error("token family revoked", sl.Err(err))
+		return "", ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Error("failed to record access token", sl.Err(err))
+		return "", ErrInternal
+	}
+	log.Info("token generated", sl.Info(t.JTI), sl.Info(login))
+
+	return t.Value, nil
+}
 
-	return t, nil
+// revokeFamily revokes the refresh tokens of the family and blacklists
+// its access tokens except skip, which is already blacklisted.
+func (s *UseCase) revokeFamily(log *slog.Logger, family, skip string) error {
+	access, err := s.rt.RevokeFamily(family)
+	if err != nil {
+		log.Error("failed to revoke token family", slog.String("family", family), sl.Err(err))
+		return ErrInternal
+	}
+	for _, a := range access {
+		if a.JTI == skip {
+			continue
+		}
+		err := s.bl.Add(a.JTI, a.ExpiresAt)
+		if errors.Is(err, token.JTIAlreadyExists) || errors.Is(err, token.Expired) {
+			continue
+		} else if err != nil {
+			log.Error("failed to add jti into blacklist", sl.Err(err))
+			return ErrInternal
+		}
+	}
+	log.Info("token family revoked", slog.String("family", family), slog.Int("access_tokens", len(access)))
+	return nil
 }
 
+// Logout blacklists the token and revokes the refresh tokens of its family.
 func (s *UseCase) Logout(ctx context.Context, claims map[string]interface{}) error {
 	const op = "service.auth.Logout"
 	requestID := ctx.Value(s.requestIdKey).(string)
@@ -115,7 +249,15 @@ func (s *UseCase) Logout(ctx context.Context, claims map[string]interface{}) err
 		return ErrInternal
 	}
 	log.Info("jti invalidated", sl.Info(jti))
-	return nil
+
+	family, err := s.rt.FamilyOf(jti)
+	if errors.Is(err, refreshToken.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		log.Error("failed to find token family", sl.Err(err))
+		return ErrInternal
+	}
+	return s.revokeFamily(log, family, jti)
 }
 
 func (s *UseCase) Register(ctx context.Context, login, password string) error {
@@ -132,6 +274,13 @@ func (s *UseCase) Register(ctx context.Context, login, password string) error {
 	} else if errors.Is(err, user.ErrHashingPassword) {
 		log.Error("error hashing password", sl.Err(err))
 		return ErrInternal
+	} else if err != nil {
+		log.Error("failed to register user", sl.Err(err))
+		return ErrInternal
+	}
+	if err := s.us.GrantRole(login, RoleUser); err != nil {
+		log.Error("failed to grant default role", sl.Err(err))
+		return ErrInternal
 	}
 
 	log.Info("user registered successfully", sl.Info(login))
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"geo/db/refreshTokenStorage/inMemoryRefreshTokenStorage"
+	"geo/db/tokenBlacklist/inMemoryTokenBlacklist"
+	"geo/db/userStorage/inMemoryUserStorage"
+	"geo/internal/infrastructure/repository/refreshToken"
+	"geo/internal/infrastructure/repository/token"
+	"geo/internal/infrastructure/repository/user"
+	"geo/internal/infrastructure/tokenGenerator/JWTAuthTokenGenerator"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func newUseCase(t *testing.T) (*UseCase, *jwtauth.JWTAuth) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	ja := jwtauth.New("HS256", []byte("secret"), nil)
+	users := user.New(inMemoryUserStorage.New())
+	require.NoError(t, users.RegisterUser("user", "password"))
+	uc := New(log, "request_id", token.New(inMemoryTokenBlacklist.NewBlacklist(time.Second)),
+		JWTAuthTokenGenerator.New(ja, time.Minute, "localhost:8080", "localhost:8080"), users,
+		refreshToken.New(inMemoryRefreshTokenStorage.New(0)), time.Hour)
+	return uc, ja
+}
+
+// jti returns the id of the access token.
+func jti(t *testing.T, ja *jwtauth.JWTAuth, access string) string {
+	tok, err := ja.Decode(access)
+	require.NoError(t, err)
+	return tok.JwtID()
+}
+
+func TestUseCase_Refresh(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc, ja := newUseCase(t)
+
+	login, err := uc.Login(ctx, "user", "password")
+	require.NoError(t, err)
+	require.NotEmpty(t, login.Refresh)
+
+	first, err := uc.Refresh(ctx, login.Refresh)
+	require.NoError(t, err)
+	require.NotEqual(t, login.Refresh, first.Refresh, "refresh token is rotated")
+	second, err := uc.Refresh(ctx, first.Refresh)
+	require.NoError(t, err)
+	for _, access := range []string{login.Access, first.Access, second.Access} {
+		require.False(t, uc.IsTokenRevoked(ctx, jti(t, ja, access)))
+	}
+
+	_, err = uc.Refresh(ctx, "unknown")
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// a rotated token is used again, e.g. after being stolen
+	_, err = uc.Refresh(ctx, first.Refresh)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+	for _, access := range []string{login.Access, first.Access, second.Access} {
+		require.True(t, uc.IsTokenRevoked(ctx, jti(t, ja, access)), "access tokens of the family are revoked")
+	}
+	_, err = uc.Refresh(ctx, second.Refresh)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken, "the latest token of the family is revoked")
+
+	// other logins are not affected
+	other, err := uc.Login(ctx, "user", "password")
+	require.NoError(t, err)
+	require.False(t, uc.IsTokenRevoked(ctx, jti(t, ja, other.Access)))
+	_, err = uc.Refresh(ctx, other.Refresh)
+	require.NoError(t, err)
+}
+
+func TestUseCase_LogoutRevokesFamily(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc, ja := newUseCase(t)
+
+	login, err := uc.Login(ctx, "user", "password")
+	require.NoError(t, err)
+	refreshed, err := uc.Refresh(ctx, login.Refresh)
+	require.NoError(t, err)
+
+	tok, err := ja.Decode(refreshed.Access)
+	require.NoError(t, err)
+	claims, err := tok.AsMap(ctx)
+	require.NoError(t, err)
+	require.NoError(t, uc.Logout(ctx, claims))
+
+	require.True(t, uc.IsTokenRevoked(ctx, jti(t, ja, refreshed.Access)))
+	require.True(t, uc.IsTokenRevoked(ctx, jti(t, ja, login.Access)))
+	_, err = uc.Refresh(ctx, refreshed.Refresh)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestUseCase_Roles(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc, ja := newUseCase(t)
+	require.NoError(t, uc.Register(ctx, "alice", "password"))
+	require.ErrorIs(t, uc.BootstrapAdmin("root", ""), ErrWeakPassword)
+	require.ErrorIs(t, uc.BootstrapAdmin("root", "short"), ErrWeakPassword)
+	require.ErrorIs(t, uc.BootstrapAdmin("root", "long-password-ROOT"), ErrWeakPassword)
+	require.NoError(t, uc.BootstrapAdmin("root", "correct horse battery"))
+	require.NoError(t, uc.BootstrapAdmin("root", "changed horse battery"), "bootstrap is idempotent")
+
+	roles, err := uc.Roles(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, []string{RoleUser}, roles, "registered users get the user role")
+
+	roles, err = uc.GrantRole(ctx, "alice", "auditor")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{RoleUser, "auditor"}, roles)
+	_, err = uc.GrantRole(ctx, "alice", "Auditor")
+	require.ErrorIs(t, err, ErrInvalidRole)
+	_, err = uc.GrantRole(ctx, "bob", "auditor")
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	tokens, err := uc.Login(ctx, "alice", "password")
+	require.NoError(t, err)
+	tok, err := ja.Decode(tokens.Access)
+	require.NoError(t, err)
+	claim, _ := tok.Get("roles")
+	require.ElementsMatch(t, []interface{}{RoleUser, "auditor"}, claim, "the roles are embedded in the token")
+
+	_, err = uc.RevokeRole(ctx, "root", "root", RoleAdmin)
+	require.ErrorIs(t, err, ErrRevokeOwnAdmin)
+	roles, err = uc.RevokeRole(ctx, "root", "alice", "auditor")
+	require.NoError(t, err)
+	require.Equal(t, []string{RoleUser}, roles)
+
+	_, err = uc.Login(ctx, "root", "changed")
+	require.ErrorIs(t, err, ErrInvalidCredentials, "bootstrap keeps the password")
+	roles, err = uc.Roles(ctx, "root")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{RoleUser, RoleAdmin}, roles)
+}
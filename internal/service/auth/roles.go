@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geo/internal/infrastructure/repository/user"
+	"geo/internal/lib/logger/sl"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const (
+	// RoleUser is granted on registration and gives access to the api
+	RoleUser = "user"
+	// RoleAdmin gives access to the service maintenance and to the roles of the users
+	RoleAdmin = "admin"
+
+	// MinAdminPasswordLength is the shortest password the admin is bootstrapped with
+	MinAdminPasswordLength = 12
+)
+
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrInvalidRole    = errors.New("role must be 1 to 32 lowercase letters, digits, '-' or '_' starting with a letter")
+	ErrRevokeOwnAdmin = errors.New("admins cannot revoke their own admin role")
+	ErrAdminBootstrap = errors.New("failed to bootstrap admin")
+	ErrWeakPassword   = fmt.Errorf("admin password must be at least %d characters long and must not contain the login",
+		MinAdminPasswordLength)
+)
+
+var roleName = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// ValidateRole checks the name of a role, custom roles follow the same rules as the built-in ones.
+func ValidateRole(role string) error {
+	if !roleName.MatchString(role) {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// Roles returns the roles of the user.
+func (s *UseCase) Roles(ctx context.Context, login string) ([]string, error) {
+	const op = "service.auth.Roles"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	roles, err := s.us.Roles(login)
+	if errors.Is(err, user.ErrNotFound) {
+		log.Error("user not found", sl.Err(err))
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		log.Error("failed to get user roles", sl.Err(err))
+		return nil, ErrInternal
+	}
+	return roles, nil
+}
+
+// GrantRole adds the role to the user and returns the roles they have now.
+// The tokens issued before keep the roles they were issued with until they expire.
+func (s *UseCase) GrantRole(ctx context.Context, login, role string) ([]string, error) {
+	const op = "service.auth.GrantRole"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if err := ValidateRole(role); err != nil {
+		return nil, err
+	}
+	err := s.us.GrantRole(login, role)
+	if errors.Is(err, user.ErrNotFound) {
+		log.Error("user not found", sl.Err(err))
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		log.Error("failed to grant role", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("role granted", sl.Info(login), slog.String("role", role))
+	return s.Roles(ctx, login)
+}
+
+// RevokeRole removes the role from the user on behalf of actor and returns the roles
+// the user has now. Admins cannot revoke their own admin role so that one is always left.
+func (s *UseCase) RevokeRole(ctx context.Context, actor, login, role string) ([]string, error) {
+	const op = "service.auth.RevokeRole"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if err := ValidateRole(role); err != nil {
+		return nil, err
+	}
+	if actor == login && role == RoleAdmin {
+		return nil, ErrRevokeOwnAdmin
+	}
+	err := s.us.RevokeRole(login, role)
+	if errors.Is(err, user.ErrNotFound) {
+		log.Error("user not found", sl.Err(err))
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		log.Error("failed to revoke role", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("role revoked", sl.Info(login), slog.String("role", role), slog.String("by", actor))
+	return s.Roles(ctx, login)
+}
+
+// BootstrapAdmin registers the admin if needed and grants them the user and admin roles.
+// The password of an admin registered before is left as it is, but it is checked all the same
+// so that a weak one is never accepted from the config.
+func (s *UseCase) BootstrapAdmin(login, password string) error {
+	const op = "service.auth.BootstrapAdmin"
+	log := s.log.With(slog.String("op", op))
+	if len(password) < MinAdminPasswordLength || strings.Contains(strings.ToLower(password), strings.ToLower(login)) {
+		return fmt.Errorf("%w: %w", ErrAdminBootstrap, ErrWeakPassword)
+	}
+	err := s.us.RegisterUser(login, password)
+	if errors.Is(err, user.ErrAlreadyRegistered) {
+		log.Info("admin already registered", sl.Info(login))
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", ErrAdminBootstrap, err)
+	}
+	for _, role := range []string{RoleUser, RoleAdmin} {
+		if err := s.us.GrantRole(login, role); err != nil {
+			return fmt.Errorf("%w: %v", ErrAdminBootstrap, err)
+		}
+	}
+	log.Info("admin bootstrapped", sl.Info(login))
+	return nil
+}
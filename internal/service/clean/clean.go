@@ -0,0 +1,122 @@
+package clean
+
+import (
+	"context"
+	"errors"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service/geo"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSourceLength is the longest address accepted, in characters.
+const MaxSourceLength = 300
+
+var (
+	ErrEmptySource   = errors.New("address cannot be empty")
+	ErrSourceTooLong = errors.New("address is too long")
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Provider
+type Provider interface {
+	// CleanAddress parses a free-form address into a standardized one
+	CleanAddress(ctx context.Context, source string) (*Address, error)
+}
+
+// Address is a standardized address. The quality codes are the ones of DaData:
+// qc 0 is a confidently parsed address, 1 has unparsed parts, 2 is garbage, 3 has alternatives;
+// qc_geo 0 is the exact house, 5 is unknown; qc_complete 0 is suitable for mailing;
+// qc_house 2 is a house found in FIAS, 10 is not found.
+type Address struct {
+	Source string `json:"source" example:"мск сухонская 11 89"`
+	// Result is the standardized address in one line
+	Result     string     `json:"result" example:"г Москва, ул Сухонская, д 11, кв 89"`
+	Components Components `json:"components"`
+	Lat        string     `json:"lat,omitempty" example:"55.8782557"`
+	Lon        string     `json:"lon,omitempty" example:"37.65372"`
+	Qc         string     `json:"qc" example:"0"`
+	QcGeo      string     `json:"qc_geo" example:"0"`
+	QcComplete string     `json:"qc_complete" example:"0"`
+	QcHouse    string     `json:"qc_house" example:"2"`
+	// UnparsedParts are the words of the source that were not recognized
+	UnparsedParts string `json:"unparsed_parts,omitempty" example:"ВОЗЛЕ АВТОВОКЗАЛА"`
+} //@name CleanAddress
+
+type Components struct {
+	PostalCode   string `json:"postal_code,omitempty" example:"127642"`
+	Country      string `json:"country,omitempty" example:"Россия"`
+	Region       string `json:"region,omitempty" example:"Москва"`
+	Area         string `json:"area,omitempty"`
+	City         string `json:"city,omitempty" example:"Москва"`
+	CityDistrict string `json:"city_district,omitempty" example:"Северный"`
+	Settlement   string `json:"settlement,omitempty"`
+	Street       string `json:"street,omitempty" example:"Сухонская"`
+	House        string `json:"house,omitempty" example:"11"`
+	Block        string `json:"block,omitempty"`
+	Flat         string `json:"flat,omitempty" example:"89"`
+	FiasID       string `json:"fias_id,omitempty" example:"5ee84ac0-eb9a-4b49-ad0f-e3a8ca6f5de1"`
+	// FiasLevel is the most detailed FIAS level found, 8 is a house
+	FiasLevel string `json:"fias_level,omitempty" example:"8"`
+	KladrID   string `json:"kladr_id,omitempty" example:"7700000000028360004"`
+	Okato     string `json:"okato,omitempty" example:"45280583000"`
+	Oktmo     string `json:"oktmo,omitempty" example:"45362000"`
+	Timezone  string `json:"timezone,omitempty" example:"UTC+3"`
+} //@name CleanAddressComponents
+
+// ValidateSource checks an address before it is sent to the provider.
+func ValidateSource(source string) error {
+	if strings.TrimSpace(source) == "" {
+		return ErrEmptySource
+	}
+	if utf8.RuneCountInString(source) > MaxSourceLength {
+		return ErrSourceTooLong
+	}
+	return nil
+}
+
+type UseCase struct {
+	log          *slog.Logger
+	requestIdKey string
+	provider     Provider
+	timeout      time.Duration
+}
+
+// New creates clean use case, timeout limits the time spent waiting for the provider.
+func New(log *slog.Logger, requestIdKey string, provider Provider, timeout time.Duration) *UseCase {
+	return &UseCase{log: log, requestIdKey: requestIdKey, provider: provider, timeout: timeout}
+}
+
+func (s *UseCase) Clean(ctx context.Context, source string) (*Address, error) {
+	const op = "service.clean.Clean"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if err := ValidateSource(source); err != nil {
+		log.Error("invalid address", sl.Err(err))
+		return nil, err
+	}
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	address, err := s.provider.CleanAddress(ctx, source)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		log.Error("provider deadline exceeded", sl.Err(err))
+		return nil, geo.ErrUpstreamTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		log.Warn("request cancelled", sl.Err(err))
+		return nil, context.Canceled
+	case err != nil:
+		log.Error("failed to clean address", sl.Err(err))
+		return nil, geo.ErrInternal
+	}
+	log.Info("address cleaned", slog.String("qc", address.Qc))
+	return address, nil
+}
@@ -0,0 +1,95 @@
+package clean
+
+import (
+	"context"
+	"errors"
+	"geo/internal/service/geo"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	address *Address
+	err     error
+	delay   time.Duration
+	calls   int
+}
+
+func (p *stubProvider) CleanAddress(ctx context.Context, source string) (*Address, error) {
+	p.calls++
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return p.address, p.err
+}
+
+func TestUseCase_Clean(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	const requestIdKey = "request_id"
+	cleaned := &Address{Source: "мск сухонская 11", Result: "г Москва, ул Сухонская, д 11", Qc: "0"}
+
+	tests := []struct {
+		name      string
+		source    string
+		provider  *stubProvider
+		want      *Address
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "success",
+			source:    "мск сухонская 11",
+			provider:  &stubProvider{address: cleaned},
+			want:      cleaned,
+			wantCalls: 1,
+		},
+		{
+			name:     "empty",
+			source:   "  ",
+			provider: &stubProvider{},
+			wantErr:  ErrEmptySource,
+		},
+		{
+			name:     "too long",
+			source:   strings.Repeat("я", MaxSourceLength+1),
+			provider: &stubProvider{},
+			wantErr:  ErrSourceTooLong,
+		},
+		{
+			name:      "provider error",
+			source:    "мск сухонская 11",
+			provider:  &stubProvider{err: errors.New("forbidden")},
+			wantErr:   geo.ErrInternal,
+			wantCalls: 1,
+		},
+		{
+			name:      "timeout",
+			source:    "мск сухонская 11",
+			provider:  &stubProvider{address: cleaned, delay: time.Second},
+			wantErr:   geo.ErrUpstreamTimeout,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := New(log, requestIdKey, tt.provider, 50*time.Millisecond)
+			ctx := context.WithValue(context.Background(), requestIdKey, "1")
+
+			got, err := uc.Clean(ctx, tt.source)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Clean() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Clean() got = %v, want %v", got, tt.want)
+			}
+			if tt.provider.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", tt.provider.calls, tt.wantCalls)
+			}
+		})
+	}
+}
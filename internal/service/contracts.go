@@ -2,19 +2,60 @@ package service
 
 import (
 	"context"
+	"geo/internal/service/apikey"
+	"geo/internal/service/auth"
+	"geo/internal/service/clean"
+	"geo/internal/service/distance"
 	"geo/internal/service/geo"
+	"geo/internal/service/place"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Auth
 type Auth interface {
 	Register(ctx context.Context, login, password string) error
 	Logout(ctx context.Context, claims map[string]interface{}) error
-	Login(ctx context.Context, login, password string) (string, error)
+	Login(ctx context.Context, login, password string) (*auth.Tokens, error)
+	Refresh(ctx context.Context, refresh string) (*auth.Tokens, error)
 	IsTokenRevoked(ctx context.Context, jti string) bool
 }
 
+//go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Roles
+type Roles interface {
+	Roles(ctx context.Context, login string) ([]string, error)
+	GrantRole(ctx context.Context, login, role string) ([]string, error)
+	RevokeRole(ctx context.Context, actor, login, role string) ([]string, error)
+}
+
 //go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Geo
 type Geo interface {
-	Geocode(ctx context.Context, lat, lng string) ([]*geo.Address, error)
-	Search(ctx context.Context, query string) ([]*geo.Address, error)
+	Geocode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error)
+	Search(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Distance
+type Distance interface {
+	Matrix(ctx context.Context, origins, destinations []distance.Place, method distance.Method) (*distance.Matrix, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Clean
+type Clean interface {
+	Clean(ctx context.Context, source string) (*clean.Address, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=Places
+type Places interface {
+	Create(ctx context.Context, owner string, in place.Input) (*place.Place, error)
+	List(ctx context.Context, owner, tag string) ([]*place.Place, error)
+	Get(ctx context.Context, owner, id string) (*place.Place, error)
+	Update(ctx context.Context, owner, id string, patch place.Patch) (*place.Place, error)
+	Delete(ctx context.Context, owner, id string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=APIKeys
+type APIKeys interface {
+	Create(ctx context.Context, owner string, in apikey.Input) (*apikey.NewKey, error)
+	List(ctx context.Context, owner string) ([]*apikey.Key, error)
+	Rename(ctx context.Context, owner, id, label string) (*apikey.Key, error)
+	Revoke(ctx context.Context, owner, id string) error
+	Authenticate(ctx context.Context, secret string) (*apikey.Principal, error)
 }
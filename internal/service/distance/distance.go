@@ -0,0 +1,231 @@
+package distance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geo/internal/lib/geodesy"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/service/geo"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidMethod   = errors.New("method must be haversine or vincenty")
+	ErrNoPlaces        = errors.New("origins and destinations cannot be empty")
+	ErrTooManyPlaces   = errors.New("too many places")
+	ErrInvalidPlace    = errors.New("place must have either coordinates or an address")
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+// Method is the formula distances are computed with.
+type Method string
+
+const (
+	// MethodHaversine computes great-circle distances on a sphere
+	MethodHaversine Method = "haversine"
+	// MethodVincenty computes geodesic distances on the WGS 84 ellipsoid
+	MethodVincenty Method = "vincenty"
+)
+
+// Place is a location given either by its point or by a free-text address.
+type Place struct {
+	Point   *geo.Point
+	Address string
+}
+
+// Location is a place resolved to a point.
+type Location struct {
+	Lat float64 `json:"lat" example:"55.8481373"`
+	Lon float64 `json:"lon" example:"37.6414907"`
+	// Address is the address the query was resolved to
+	Address string `json:"address,omitempty" example:"г Москва, ул Снежная, д 4"`
+} //@name DistanceLocation
+
+// Leg is the distance from an origin to a destination.
+type Leg struct {
+	Meters float64 `json:"distance_meters" example:"1523.4"`
+	// Bearing is the initial bearing in degrees clockwise from north
+	Bearing float64 `json:"bearing_degrees" example:"312.7"`
+	// Error is set when the distance cannot be computed, e.g. vincenty for nearly antipodal points
+	Error string `json:"error,omitempty"`
+} //@name DistanceLeg
+
+type Matrix struct {
+	Origins      []Location
+	Destinations []Location
+	// Legs[i][j] is the leg from Origins[i] to Destinations[j]
+	Legs [][]Leg
+}
+
+// Searcher resolves free-text addresses.
+type Searcher interface {
+	Search(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error)
+}
+
+type Settings struct {
+	// MaxOrigins and MaxDestinations bound the matrix size
+	MaxOrigins      int
+	MaxDestinations int
+	// Parallelism is the number of addresses resolved at once
+	Parallelism int
+}
+
+type UseCase struct {
+	log          *slog.Logger
+	requestIdKey string
+	searcher     Searcher
+	settings     Settings
+}
+
+func New(log *slog.Logger, requestIdKey string, searcher Searcher, settings Settings) *UseCase {
+	if settings.Parallelism <= 0 {
+		settings.Parallelism = 1
+	}
+	return &UseCase{log: log, requestIdKey: requestIdKey, searcher: searcher, settings: settings}
+}
+
+// Matrix computes the legs from every origin to every destination, an empty method means haversine.
+func (s *UseCase) Matrix(ctx context.Context, origins, destinations []Place, method Method) (*Matrix, error) {
+	const op = "service.distance.Matrix"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if method == "" {
+		method = MethodHaversine
+	}
+	if method != MethodHaversine && method != MethodVincenty {
+		return nil, ErrInvalidMethod
+	}
+	if len(origins) == 0 || len(destinations) == 0 {
+		return nil, ErrNoPlaces
+	}
+	if s.settings.MaxOrigins > 0 && len(origins) > s.settings.MaxOrigins {
+		return nil, fmt.Errorf("%w: at most %d origins are allowed", ErrTooManyPlaces, s.settings.MaxOrigins)
+	}
+	if s.settings.MaxDestinations > 0 && len(destinations) > s.settings.MaxDestinations {
+		return nil, fmt.Errorf("%w: at most %d destinations are allowed", ErrTooManyPlaces, s.settings.MaxDestinations)
+	}
+
+	locations, err := s.resolve(ctx, log, append(append([]Place{}, origins...), destinations...))
+	if err != nil {
+		return nil, err
+	}
+	m := &Matrix{
+		Origins:      locations[:len(origins)],
+		Destinations: locations[len(origins):],
+		Legs:         make([][]Leg, len(origins)),
+	}
+	for i, from := range m.Origins {
+		m.Legs[i] = make([]Leg, len(m.Destinations))
+		for j, to := range m.Destinations {
+			m.Legs[i][j] = leg(from, to, method)
+		}
+	}
+	log.Info("distances computed", slog.Int("origins", len(origins)), slog.Int("destinations", len(destinations)))
+	return m, nil
+}
+
+// resolve turns places into locations, addresses are searched concurrently
+// and an address repeated in the request is searched once.
+func (s *UseCase) resolve(ctx context.Context, log *slog.Logger, places []Place) ([]Location, error) {
+	locations := make([]Location, len(places))
+	queries := make(map[string][]int)
+	for i, p := range places {
+		switch {
+		case p.Point != nil && p.Address == "":
+			if err := p.Point.Validate(); err != nil {
+				return nil, err
+			}
+			locations[i] = Location{Lat: p.Point.Lat, Lon: p.Point.Lon}
+		case p.Point == nil && strings.TrimSpace(p.Address) != "":
+			queries[p.Address] = append(queries[p.Address], i)
+		default:
+			return nil, ErrInvalidPlace
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, s.settings.Parallelism)
+	for query, indexes := range queries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			location, err := s.search(ctx, query)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			for _, i := range indexes {
+				locations[i] = location
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		log.Error("failed to resolve address", sl.Err(firstErr))
+		return nil, firstErr
+	}
+	return locations, nil
+}
+
+// search resolves an address to the location of its best match.
+func (s *UseCase) search(ctx context.Context, query string) (Location, error) {
+	addresses, err := s.searcher.Search(ctx, query, geo.SearchOptions{Count: 1})
+	if err != nil {
+		return Location{}, err
+	}
+	for _, a := range addresses {
+		lat, latErr := geo.ParseLatitude(a.Lat)
+		lon, lonErr := geo.ParseLongitude(a.Lon)
+		if latErr == nil && lonErr == nil {
+			return Location{Lat: lat, Lon: lon, Address: addressLine(a)}, nil
+		}
+	}
+	return Location{}, fmt.Errorf("%w: %s", ErrAddressNotFound, strconv.Quote(query))
+}
+
+func addressLine(a *geo.Address) string {
+	if a.Value != "" {
+		return a.Value
+	}
+	var parts []string
+	for _, p := range []string{a.City, a.Street, a.House} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func leg(from, to Location, method Method) Leg {
+	if method == MethodVincenty {
+		meters, bearing, err := geodesy.Vincenty(from.Lat, from.Lon, to.Lat, to.Lon)
+		if err != nil {
+			return Leg{Error: err.Error()}
+		}
+		return Leg{Meters: meters, Bearing: bearing}
+	}
+	return Leg{
+		Meters:  geodesy.Haversine(from.Lat, from.Lon, to.Lat, to.Lon),
+		Bearing: geodesy.Bearing(from.Lat, from.Lon, to.Lat, to.Lon),
+	}
+}
@@ -0,0 +1,159 @@
+package distance
+
+import (
+	"context"
+	"errors"
+	"geo/internal/service/geo"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"testing"
+)
+
+type stubSearcher struct {
+	mu      sync.Mutex
+	queries map[string]int
+	err     error
+}
+
+func (s *stubSearcher) Search(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	s.mu.Lock()
+	s.queries[query]++
+	s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	switch query {
+	case "Москва, Снежная 4":
+		return []*geo.Address{{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"}}, nil
+	case "без координат":
+		return []*geo.Address{{City: "Москва", Street: "Снежная"}}, nil
+	}
+	return nil, nil
+}
+
+func TestUseCase_Matrix(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	const requestIdKey = "request_id"
+	snezhnaya := Place{Address: "Москва, Снежная 4"}
+	kremlin := Place{Point: &geo.Point{Lat: 55.751999, Lon: 37.617734}}
+	equator := Place{Point: &geo.Point{Lat: 0, Lon: 0}}
+	antipode := Place{Point: &geo.Point{Lat: 0.5, Lon: 179.7}}
+
+	tests := []struct {
+		name         string
+		origins      []Place
+		destinations []Place
+		method       Method
+		searchErr    error
+		wantMeters   [][]float64
+		wantLegErr   bool
+		wantErr      error
+		wantSearches map[string]int
+	}{
+		{
+			name:         "coordinates and address",
+			origins:      []Place{snezhnaya, kremlin},
+			destinations: []Place{kremlin, snezhnaya},
+			wantMeters:   [][]float64{{10793, 0}, {0, 10793}},
+			wantSearches: map[string]int{"Москва, Снежная 4": 1},
+		},
+		{
+			name:         "vincenty",
+			origins:      []Place{kremlin},
+			destinations: []Place{snezhnaya},
+			method:       MethodVincenty,
+			wantMeters:   [][]float64{{10807}},
+			wantSearches: map[string]int{"Москва, Снежная 4": 1},
+		},
+		{
+			name:         "vincenty failure is reported in the leg",
+			origins:      []Place{equator},
+			destinations: []Place{antipode},
+			method:       MethodVincenty,
+			wantMeters:   [][]float64{{0}},
+			wantLegErr:   true,
+		},
+		{
+			name:         "unknown method",
+			origins:      []Place{kremlin},
+			destinations: []Place{kremlin},
+			method:       "manhattan",
+			wantErr:      ErrInvalidMethod,
+		},
+		{
+			name:         "too many origins",
+			origins:      []Place{kremlin, kremlin, kremlin},
+			destinations: []Place{kremlin},
+			wantErr:      ErrTooManyPlaces,
+		},
+		{
+			name:    "no destinations",
+			origins: []Place{kremlin},
+			wantErr: ErrNoPlaces,
+		},
+		{
+			name:         "place with both point and address",
+			origins:      []Place{{Point: &geo.Point{}, Address: "Москва"}},
+			destinations: []Place{kremlin},
+			wantErr:      ErrInvalidPlace,
+		},
+		{
+			name:         "invalid point",
+			origins:      []Place{{Point: &geo.Point{Lat: 91}}},
+			destinations: []Place{kremlin},
+			wantErr:      geo.ErrInvalidPoint,
+		},
+		{
+			name:         "address not found",
+			origins:      []Place{{Address: "нигде"}},
+			destinations: []Place{kremlin},
+			wantErr:      ErrAddressNotFound,
+		},
+		{
+			name:         "address without coordinates",
+			origins:      []Place{{Address: "без координат"}},
+			destinations: []Place{kremlin},
+			wantErr:      ErrAddressNotFound,
+		},
+		{
+			name:         "search failure",
+			origins:      []Place{snezhnaya},
+			destinations: []Place{kremlin},
+			searchErr:    geo.ErrUpstreamTimeout,
+			wantErr:      geo.ErrUpstreamTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &stubSearcher{queries: make(map[string]int), err: tt.searchErr}
+			uc := New(log, requestIdKey, searcher, Settings{MaxOrigins: 2, MaxDestinations: 2, Parallelism: 2})
+			ctx := context.WithValue(context.Background(), requestIdKey, "1")
+
+			got, err := uc.Matrix(ctx, tt.origins, tt.destinations, tt.method)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Matrix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i, row := range tt.wantMeters {
+				for j, want := range row {
+					leg := got.Legs[i][j]
+					if (leg.Error != "") != tt.wantLegErr {
+						t.Errorf("Legs[%d][%d].Error = %q, want error %v", i, j, leg.Error, tt.wantLegErr)
+					}
+					if math.Abs(leg.Meters-want) > 10 {
+						t.Errorf("Legs[%d][%d].Meters = %v, want %v", i, j, leg.Meters, want)
+					}
+				}
+			}
+			for q, n := range tt.wantSearches {
+				if searcher.queries[q] != n {
+					t.Errorf("%q searched %d times, want %d", q, searcher.queries[q], n)
+				}
+			}
+		})
+	}
+}
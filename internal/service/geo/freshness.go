@@ -0,0 +1,32 @@
+package geo
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type freshnessKey struct{}
+
+// Freshness tells whether the addresses returned for a request come from
+// an expired cache entry because the provider could not be reached.
+type Freshness struct {
+	stale atomic.Bool
+}
+
+// WithFreshness returns a context through which providers can report stale results.
+func WithFreshness(ctx context.Context) (context.Context, *Freshness) {
+	f := &Freshness{}
+	return context.WithValue(ctx, freshnessKey{}, f), f
+}
+
+// MarkStale records that the result of the request bound to ctx is stale,
+// it is a no-op if ctx was not created by WithFreshness.
+func MarkStale(ctx context.Context) {
+	if f, ok := ctx.Value(freshnessKey{}).(*Freshness); ok {
+		f.stale.Store(true)
+	}
+}
+
+func (f *Freshness) Stale() bool {
+	return f.stale.Load()
+}
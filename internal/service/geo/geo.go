@@ -6,16 +6,18 @@ import (
 	"geo/internal/infrastructure/geoProvider"
 	"geo/internal/lib/logger/sl"
 	"log/slog"
+	"time"
 )
 
 var (
-	ErrInternal = errors.New("internal server error")
+	ErrInternal        = errors.New("internal server error")
+	ErrUpstreamTimeout = errors.New("upstream timeout")
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.52.3 --name=GeoProvider
 type Provider interface {
-	AddressSearch(input string) ([]*Address, error)
-	AddressGeoCode(lat, lng string) ([]*Address, error)
+	AddressSearch(ctx context.Context, input string, opts SearchOptions) ([]*Address, error)
+	AddressGeoCode(ctx context.Context, point Point, opts GeocodeOptions) ([]*Address, error)
 }
 
 type Address struct {
@@ -24,56 +26,126 @@ type Address struct {
 	House  string `json:"house"`
 	Lat    string `json:"lat"`
 	Lon    string `json:"lon"`
+
+	// The fields below are filled only by the providers that know them
+
+	// Value is the full address in one line
+	Value      string `json:"value,omitempty" example:"г Москва, ул Снежная, д 4"`
+	PostalCode string `json:"postal_code,omitempty" example:"129323"`
+	Country    string `json:"country,omitempty" example:"Россия"`
+	Region     string `json:"region,omitempty" example:"Москва"`
+	// Area is the district of the region, Settlement is the village or town outside a city
+	Area       string `json:"area,omitempty" example:"Одинцовский"`
+	Settlement string `json:"settlement,omitempty" example:"Жаворонки"`
+	// FiasID is the FIAS code of the most detailed address level found
+	FiasID  string `json:"fias_id,omitempty" example:"93eb5a93-2e58-4d68-ad78-ae4bcb2ab1f2"`
+	KladrID string `json:"kladr_id,omitempty" example:"7700000000028360004"`
+	Okato   string `json:"okato,omitempty" example:"45280583000"`
+	Oktmo   string `json:"oktmo,omitempty" example:"45362000"`
+	// QcGeo is the coordinates precision code: 0 is the exact house, 5 is unknown
+	QcGeo    string `json:"qc_geo,omitempty" example:"0"`
+	Timezone string `json:"timezone,omitempty" example:"UTC+3"`
 } //@name Address
 
 type UseCase struct {
-	log          *slog.Logger
-	requestIdKey string
-	provider     Provider
+	log            *slog.Logger
+	requestIdKey   string
+	provider       Provider
+	searchTimeout  time.Duration
+	geocodeTimeout time.Duration
+	searchRules    SearchRules
 }
 
-func New(log *slog.Logger, requestIDKey string, provider Provider) *UseCase {
+// New creates geo use case. searchTimeout and geocodeTimeout limit the time spent
+// waiting for the provider, zero means no limit besides the request context.
+// searchRules configure the post-processing of the search results.
+func New(log *slog.Logger, requestIDKey string, provider Provider, searchTimeout, geocodeTimeout time.Duration,
+	searchRules SearchRules) *UseCase {
 	return &UseCase{
-		log:          log,
-		requestIdKey: requestIDKey,
-		provider:     provider,
+		log:            log,
+		requestIdKey:   requestIDKey,
+		provider:       provider,
+		searchTimeout:  searchTimeout,
+		geocodeTimeout: geocodeTimeout,
+		searchRules:    searchRules,
 	}
 }
 
-func (s *UseCase) Geocode(ctx context.Context, lat, lng string) ([]*Address, error) {
+func (s *UseCase) Geocode(ctx context.Context, point Point, opts GeocodeOptions) ([]*Address, error) {
 	const op = "service.geo.Geocode"
 	requestID := ctx.Value(s.requestIdKey).(string)
 	log := s.log.With(
 		slog.String("op", op),
 		slog.String("request_id", requestID),
 	)
-	addresses, err := s.provider.AddressGeoCode(lat, lng)
-	if errors.Is(err, geoProvider.ErrUnavailable) {
-		log.Error("failed to get addresses", sl.Err(err))
-		return nil, ErrInternal
-	} else if err != nil {
-		log.Error("failed to get addresses", sl.Err(err))
-		return nil, ErrInternal
+	if err := point.Validate(); err != nil {
+		log.Error("invalid point", sl.Err(err))
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		log.Error("invalid options", sl.Err(err))
+		return nil, err
+	}
+	ctx, cancel := withBudget(ctx, s.geocodeTimeout)
+	defer cancel()
+
+	addresses, err := s.provider.AddressGeoCode(ctx, point, opts)
+	if err != nil {
+		return nil, s.providerError(ctx, log, err)
 	}
 	log.Info("addresses received")
 	return addresses, nil
 }
 
-func (s *UseCase) Search(ctx context.Context, query string) ([]*Address, error) {
+func (s *UseCase) Search(ctx context.Context, query string, opts SearchOptions) ([]*Address, error) {
 	const op = "service.geo.Search"
 	requestID := ctx.Value(s.requestIdKey).(string)
 	log := s.log.With(
 		slog.String("op", op),
 		slog.String("request_id", requestID),
 	)
-	addresses, err := s.provider.AddressSearch(query)
-	if errors.Is(err, geoProvider.ErrUnavailable) {
+	if err := opts.Validate(); err != nil {
+		log.Error("invalid options", sl.Err(err))
+		return nil, err
+	}
+	ctx, cancel := withBudget(ctx, s.searchTimeout)
+	defer cancel()
+
+	addresses, err := s.provider.AddressSearch(ctx, query, opts)
+	if err != nil {
+		return nil, s.providerError(ctx, log, err)
+	}
+	res := s.searchRules.apply(query, opts, addresses)
+	log.Info("addresses received", slog.Int("received", len(addresses)), slog.Int("returned", len(res)))
+	return res, nil
+}
+
+// providerError maps an error returned by the provider to the use case error.
+// The context state takes precedence over the error itself, because providers
+// are not required to wrap context errors.
+func (s *UseCase) providerError(ctx context.Context, log *slog.Logger, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		log.Error("provider deadline exceeded", sl.Err(err))
+		return ErrUpstreamTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		log.Warn("request cancelled", sl.Err(err))
+		return context.Canceled
+	case errors.Is(err, geoProvider.ErrUnsupportedOption):
+		log.Warn("provider rejected options", sl.Err(err))
+		return ErrUnsupportedOption
+	case errors.Is(err, geoProvider.ErrUnavailable):
 		log.Error("failed to get addresses", sl.Err(err))
-		return nil, ErrInternal
-	} else if err != nil {
+		return ErrInternal
+	default:
 		log.Error("failed to get addresses", sl.Err(err))
-		return nil, ErrInternal
+		return ErrInternal
 	}
-	log.Info("addresses received")
-	return addresses, nil
+}
+
+func withBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, budget)
 }
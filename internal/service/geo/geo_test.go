@@ -0,0 +1,190 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"geo/internal/infrastructure/geoProvider"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+type blockingProvider struct {
+	err error
+}
+
+func (p *blockingProvider) AddressSearch(ctx context.Context, input string, opts SearchOptions) ([]*Address, error) {
+	return p.wait(ctx)
+}
+
+func (p *blockingProvider) AddressGeoCode(ctx context.Context, point Point, opts GeocodeOptions) ([]*Address, error) {
+	return p.wait(ctx)
+}
+
+func (p *blockingProvider) wait(ctx context.Context) ([]*Address, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	<-ctx.Done()
+	return nil, geoProvider.ErrUnavailable
+}
+
+func TestUseCase_DeadlineBudget(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	const requestIdKey = "request_id"
+
+	tests := []struct {
+		name     string
+		provider Provider
+		cancel   bool
+		wantErr  error
+	}{
+		{
+			name:     "budget exceeded",
+			provider: &blockingProvider{},
+			wantErr:  ErrUpstreamTimeout,
+		},
+		{
+			name:     "client cancelled",
+			provider: &blockingProvider{},
+			cancel:   true,
+			wantErr:  context.Canceled,
+		},
+		{
+			name:     "provider unavailable",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			wantErr:  ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := New(log, requestIdKey, tt.provider, 50*time.Millisecond, 50*time.Millisecond, SearchRules{})
+			ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestIdKey, "1"))
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			if _, err := uc.Search(ctx, "г Москва", SearchOptions{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := uc.Geocode(ctx, Point{Lat: 55.8481373, Lon: 37.6414907}, GeocodeOptions{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Geocode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUseCase_GeocodeOptions(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	const requestIdKey = "request_id"
+	point := Point{Lat: 55.8481373, Lon: 37.6414907}
+
+	tests := []struct {
+		name     string
+		provider Provider
+		opts     GeocodeOptions
+		wantErr  error
+	}{
+		{
+			name:     "radius out of range",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     GeocodeOptions{RadiusMeters: MaxGeocodeRadius + 1},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "negative count",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     GeocodeOptions{Count: -1},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "malformed language",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     GeocodeOptions{Language: "en'; --"},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "option rejected by provider",
+			provider: &blockingProvider{err: geoProvider.ErrUnsupportedOption},
+			opts:     GeocodeOptions{Language: "de"},
+			wantErr:  ErrUnsupportedOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := New(log, requestIdKey, tt.provider, time.Second, time.Second, SearchRules{})
+			ctx := context.WithValue(context.Background(), requestIdKey, "1")
+
+			if _, err := uc.Geocode(ctx, point, tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Geocode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUseCase_SearchOptions(t *testing.T) {
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	const requestIdKey = "request_id"
+
+	tests := []struct {
+		name     string
+		provider Provider
+		opts     SearchOptions
+		wantErr  error
+	}{
+		{
+			name:     "count out of range",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     SearchOptions{Count: MaxSearchCount + 1},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "unknown granularity",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     SearchOptions{FromBound: "planet"},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "bounds in reverse order",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     SearchOptions{FromBound: GranularityStreet, ToBound: GranularityCity},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "empty location",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     SearchOptions{Locations: []Location{{}}},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "boost without kladr id",
+			provider: &blockingProvider{err: geoProvider.ErrUnavailable},
+			opts:     SearchOptions{LocationsBoost: []BoostLocation{{}}},
+			wantErr:  ErrInvalidOptions,
+		},
+		{
+			name:     "filter rejected by provider",
+			provider: &blockingProvider{err: geoProvider.ErrUnsupportedOption},
+			opts:     SearchOptions{FromBound: GranularityCity, ToBound: GranularityCity},
+			wantErr:  ErrUnsupportedOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := New(log, requestIdKey, tt.provider, time.Second, time.Second, SearchRules{})
+			ctx := context.WithValue(context.Background(), requestIdKey, "1")
+
+			if _, err := uc.Search(ctx, "Снежная", tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
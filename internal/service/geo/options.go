@@ -0,0 +1,218 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// MaxGeocodeRadius is the largest search radius around a point in meters
+	MaxGeocodeRadius = 1000
+	// MaxGeocodeCount is the largest number of addresses returned for a point
+	MaxGeocodeCount = 20
+	// MaxSearchCount is the largest number of addresses returned for a query
+	MaxSearchCount = 20
+	// MaxSearchLocations is the largest number of locations in a search filter
+	MaxSearchLocations = 10
+)
+
+var (
+	ErrInvalidOptions    = errors.New("invalid options")
+	ErrUnsupportedOption = errors.New("option is not supported by the geo provider")
+	ErrRadiusRange       = fmt.Errorf("must be between 1 and %d", MaxGeocodeRadius)
+	ErrCountRange        = fmt.Errorf("must be between 1 and %d", MaxGeocodeCount)
+	ErrLanguage          = errors.New("must be a language code like ru or en")
+	ErrSearchCountRange  = fmt.Errorf("must be between 1 and %d", MaxSearchCount)
+	ErrGranularity       = errors.New("must be one of country, region, area, city, settlement, street, house")
+	ErrBoundOrder        = errors.New("must not be coarser than from_bound")
+	ErrLocations         = fmt.Errorf("must hold at most %d locations with at least one field set", MaxSearchLocations)
+	ErrBoostLocations    = fmt.Errorf("must hold at most %d locations with kladr_id set", MaxSearchLocations)
+)
+
+// Granularity is an address level, from the coarsest to the finest.
+type Granularity string
+
+const (
+	GranularityCountry    Granularity = "country"
+	GranularityRegion     Granularity = "region"
+	GranularityArea       Granularity = "area"
+	GranularityCity       Granularity = "city"
+	GranularitySettlement Granularity = "settlement"
+	GranularityStreet     Granularity = "street"
+	GranularityHouse      Granularity = "house"
+)
+
+var granularityLevel = map[Granularity]int{
+	GranularityCountry:    1,
+	GranularityRegion:     2,
+	GranularityArea:       3,
+	GranularityCity:       4,
+	GranularitySettlement: 5,
+	GranularityStreet:     6,
+	GranularityHouse:      7,
+}
+
+// Location restricts search results to the addresses matching every set field.
+type Location struct {
+	Country    string `json:"country,omitempty" example:"Россия"`
+	Region     string `json:"region,omitempty" example:"Москва"`
+	City       string `json:"city,omitempty" example:"Москва"`
+	Settlement string `json:"settlement,omitempty"`
+	Street     string `json:"street,omitempty"`
+	FiasID     string `json:"fias_id,omitempty"`
+	KladrID    string `json:"kladr_id,omitempty"`
+} //@name Location
+
+// BoostLocation ranks the addresses of the location first without filtering out the others.
+type BoostLocation struct {
+	KladrID string `json:"kladr_id" example:"7700000000000"`
+} //@name BoostLocation
+
+// SearchOptions narrow address search, zero values leave the provider defaults.
+type SearchOptions struct {
+	// Locations keep only the addresses inside any of them
+	Locations []Location
+	// LocationsBoost rank the addresses inside them first, a provider may ignore them
+	LocationsBoost []BoostLocation
+	// FromBound and ToBound limit the address levels returned
+	FromBound Granularity
+	ToBound   Granularity
+	// Count is the maximum number of addresses returned
+	Count int
+}
+
+// IsZero reports whether no option is set.
+func (o SearchOptions) IsZero() bool {
+	return len(o.Locations) == 0 && len(o.LocationsBoost) == 0 &&
+		o.FromBound == "" && o.ToBound == "" && o.Count == 0
+}
+
+// Validate checks that the set options are within limits.
+func (o SearchOptions) Validate() error {
+	if err := ValidateSearchCount(o.Count); err != nil {
+		return fmt.Errorf("%w: count %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateLocations(o.Locations); err != nil {
+		return fmt.Errorf("%w: locations %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateBoostLocations(o.LocationsBoost); err != nil {
+		return fmt.Errorf("%w: locations_boost %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateGranularity(o.FromBound); err != nil {
+		return fmt.Errorf("%w: from_bound %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateGranularity(o.ToBound); err != nil {
+		return fmt.Errorf("%w: to_bound %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateBoundOrder(o.FromBound, o.ToBound); err != nil {
+		return fmt.Errorf("%w: to_bound %w", ErrInvalidOptions, err)
+	}
+	return nil
+}
+
+// ValidateSearchCount checks a result count, zero means the provider default.
+func ValidateSearchCount(count int) error {
+	if count < 0 || count > MaxSearchCount {
+		return ErrSearchCountRange
+	}
+	return nil
+}
+
+// ValidateGranularity checks an address level, empty means no bound.
+func ValidateGranularity(g Granularity) error {
+	if _, ok := granularityLevel[g]; g != "" && !ok {
+		return ErrGranularity
+	}
+	return nil
+}
+
+// ValidateBoundOrder checks that to is not coarser than from when both are set and valid.
+func ValidateBoundOrder(from, to Granularity) error {
+	fromLevel, toLevel := granularityLevel[from], granularityLevel[to]
+	if fromLevel > 0 && toLevel > 0 && toLevel < fromLevel {
+		return ErrBoundOrder
+	}
+	return nil
+}
+
+// ValidateLocations checks the locations of a search filter.
+func ValidateLocations(locations []Location) error {
+	if len(locations) > MaxSearchLocations {
+		return ErrLocations
+	}
+	for _, l := range locations {
+		if l == (Location{}) {
+			return ErrLocations
+		}
+	}
+	return nil
+}
+
+// ValidateBoostLocations checks the locations ranked first.
+func ValidateBoostLocations(locations []BoostLocation) error {
+	if len(locations) > MaxSearchLocations {
+		return ErrBoostLocations
+	}
+	for _, l := range locations {
+		if l.KladrID == "" {
+			return ErrBoostLocations
+		}
+	}
+	return nil
+}
+
+var languageRe = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+
+// GeocodeOptions tune reverse geocoding, zero values leave the provider defaults.
+type GeocodeOptions struct {
+	// RadiusMeters is the search radius around the point
+	RadiusMeters int
+	// Count is the maximum number of addresses returned
+	Count int
+	// Language is the language of the returned addresses
+	Language string
+}
+
+// IsZero reports whether no option is set.
+func (o GeocodeOptions) IsZero() bool {
+	return o == GeocodeOptions{}
+}
+
+// Validate checks that the set options are within limits.
+func (o GeocodeOptions) Validate() error {
+	if err := ValidateRadius(o.RadiusMeters); err != nil {
+		return fmt.Errorf("%w: radius_meters %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateCount(o.Count); err != nil {
+		return fmt.Errorf("%w: count %w", ErrInvalidOptions, err)
+	}
+	if err := ValidateLanguage(o.Language); err != nil {
+		return fmt.Errorf("%w: language %w", ErrInvalidOptions, err)
+	}
+	return nil
+}
+
+// ValidateRadius checks a search radius, zero means the provider default.
+func ValidateRadius(meters int) error {
+	if meters < 0 || meters > MaxGeocodeRadius {
+		return ErrRadiusRange
+	}
+	return nil
+}
+
+// ValidateCount checks a result count, zero means the provider default.
+func ValidateCount(count int) error {
+	if count < 0 || count > MaxGeocodeCount {
+		return ErrCountRange
+	}
+	return nil
+}
+
+// ValidateLanguage checks a language code, empty means the provider default.
+func ValidateLanguage(language string) error {
+	if language != "" && !languageRe.MatchString(language) {
+		return ErrLanguage
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPoint    = errors.New("invalid coordinates")
+	ErrEmptyCoordinate = errors.New("cannot be empty")
+	ErrNotANumber      = errors.New("must be a number")
+	ErrLatitudeRange   = errors.New("must be between -90 and 90")
+	ErrLongitudeRange  = errors.New("must be between -180 and 180")
+)
+
+// Point is a location in WGS 84 degrees.
+type Point struct {
+	Lat float64 `json:"lat" example:"55.8481373"`
+	Lon float64 `json:"lon" example:"37.6414907"`
+} //@name Point
+
+// NewPoint returns the point at lat and lon if they are within range.
+func NewPoint(lat, lon float64) (Point, error) {
+	p := Point{Lat: lat, Lon: lon}
+	if err := p.Validate(); err != nil {
+		return Point{}, err
+	}
+	return p, nil
+}
+
+// Validate checks that the coordinates are finite and within range.
+func (p Point) Validate() error {
+	if err := checkLatitude(p.Lat); err != nil {
+		return fmt.Errorf("%w: lat %w", ErrInvalidPoint, err)
+	}
+	if err := checkLongitude(p.Lon); err != nil {
+		return fmt.Errorf("%w: lon %w", ErrInvalidPoint, err)
+	}
+	return nil
+}
+
+// LatString formats the latitude with the minimal number of digits that keeps its value.
+func (p Point) LatString() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64)
+}
+
+// LonString formats the longitude with the minimal number of digits that keeps its value.
+func (p Point) LonString() string {
+	return strconv.FormatFloat(p.Lon, 'f', -1, 64)
+}
+
+func (p Point) String() string {
+	return p.LatString() + "," + p.LonString()
+}
+
+// ParseLatitude parses a latitude in decimal degrees.
+func ParseLatitude(s string) (float64, error) {
+	lat, err := parseCoordinate(s)
+	if err != nil {
+		return 0, err
+	}
+	return lat, checkLatitude(lat)
+}
+
+// ParseLongitude parses a longitude in decimal degrees.
+func ParseLongitude(s string) (float64, error) {
+	lon, err := parseCoordinate(s)
+	if err != nil {
+		return 0, err
+	}
+	return lon, checkLongitude(lon)
+}
+
+func parseCoordinate(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrEmptyCoordinate
+	}
+	// NaN and Inf literals accepted by ParseFloat are rejected by the range checks
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrNotANumber
+	}
+	return x, nil
+}
+
+func checkLatitude(lat float64) error {
+	if math.IsNaN(lat) || math.IsInf(lat, 0) {
+		return ErrNotANumber
+	}
+	if lat < -90 || lat > 90 {
+		return ErrLatitudeRange
+	}
+	return nil
+}
+
+func checkLongitude(lon float64) error {
+	if math.IsNaN(lon) || math.IsInf(lon, 0) {
+		return ErrNotANumber
+	}
+	if lon < -180 || lon > 180 {
+		return ErrLongitudeRange
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseLatitude(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr error
+	}{
+		{input: "55.8481373", want: 55.8481373},
+		{input: " -90 ", want: -90},
+		{input: "", wantErr: ErrEmptyCoordinate},
+		{input: "north", wantErr: ErrNotANumber},
+		{input: "NaN", wantErr: ErrNotANumber},
+		{input: "-Inf", wantErr: ErrNotANumber},
+		{input: "90.0001", wantErr: ErrLatitudeRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLatitude(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseLatitude() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ParseLatitude() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLongitude(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr error
+	}{
+		{input: "37.6414907", want: 37.6414907},
+		{input: "180", want: 180},
+		{input: "-180.1", wantErr: ErrLongitudeRange},
+		{input: "1e3", wantErr: ErrLongitudeRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLongitude(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseLongitude() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ParseLongitude() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		lat     float64
+		lon     float64
+		wantErr bool
+	}{
+		{name: "valid", lat: 55.8481373, lon: 37.6414907},
+		{name: "poles and antimeridian", lat: -90, lon: 180},
+		{name: "latitude out of range", lat: 90.5, lon: 0, wantErr: true},
+		{name: "longitude out of range", lat: 0, lon: -181, wantErr: true},
+		{name: "NaN", lat: math.NaN(), lon: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPoint(tt.lat, tt.lon)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPoint) {
+				t.Errorf("NewPoint() error = %v, want ErrInvalidPoint", err)
+			}
+		})
+	}
+}
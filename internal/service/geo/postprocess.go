@@ -0,0 +1,132 @@
+package geo
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SearchRules configure the post-processing of the search results, applied in this order:
+// the city falls back to the settlement or the area, the results missing required parts are dropped,
+// the results are ranked by how well they match the query and the duplicates are collapsed.
+type SearchRules struct {
+	// RequireCity drops the results left without a city after the fallback
+	RequireCity bool
+	// RequireStreet drops the results without a street, unless the search is limited by bounds
+	RequireStreet bool
+	// Rank orders the results by how well they match the query, unless locations are boosted
+	Rank bool
+	// Dedupe collapses the results sharing a FIAS ID, or sharing coordinates, street and house
+	Dedupe bool
+	// DedupePrecision is the number of decimal places of the compared coordinates
+	DedupePrecision int
+}
+
+func (sr SearchRules) apply(query string, opts SearchOptions, addresses []*Address) []*Address {
+	bounded := opts.FromBound != "" || opts.ToBound != ""
+	res := make([]*Address, 0, len(addresses))
+	for _, a := range addresses {
+		if a.City == "" && (a.Settlement != "" || a.Area != "") {
+			// providers and caches share the addresses, the fallback is applied to a copy
+			c := *a
+			c.City = c.Settlement
+			if c.City == "" {
+				c.City = c.Area
+			}
+			a = &c
+		}
+		if sr.RequireCity && a.City == "" && !bounded {
+			continue
+		}
+		if sr.RequireStreet && a.Street == "" && !bounded {
+			continue
+		}
+		res = append(res, a)
+	}
+	if sr.Rank && len(opts.LocationsBoost) == 0 {
+		rank(query, res)
+	}
+	if sr.Dedupe {
+		res = dedupe(res, sr.DedupePrecision)
+	}
+	return res
+}
+
+// rank sorts addresses by the share of the query words they match, then by the number
+// of their words the query does not mention, keeping the provider order of equal matches.
+func rank(query string, addresses []*Address) {
+	words := tokenize(query)
+	if len(words) == 0 {
+		return
+	}
+	type score struct {
+		matched, extra int
+	}
+	scores := make(map[*Address]score, len(addresses))
+	for _, a := range addresses {
+		tokens := tokenize(addressLine(a))
+		var sc score
+		used := make([]bool, len(tokens))
+		for _, w := range words {
+			for i, t := range tokens {
+				if !used[i] && strings.HasPrefix(t, w) {
+					used[i] = true
+					sc.matched++
+					break
+				}
+			}
+		}
+		sc.extra = len(tokens) - sc.matched
+		scores[a] = sc
+	}
+	sort.SliceStable(addresses, func(i, j int) bool {
+		si, sj := scores[addresses[i]], scores[addresses[j]]
+		if si.matched != sj.matched {
+			return si.matched > sj.matched
+		}
+		return si.extra < sj.extra
+	})
+}
+
+// dedupe keeps the first of the addresses sharing a FIAS ID, or sharing coordinates, street and house.
+func dedupe(addresses []*Address, precision int) []*Address {
+	seen := make(map[string]bool, 2*len(addresses))
+	res := addresses[:0]
+	for _, a := range addresses {
+		var keys []string
+		if a.FiasID != "" {
+			keys = append(keys, "fias:"+a.FiasID)
+		}
+		if lat, err := strconv.ParseFloat(a.Lat, 64); err == nil {
+			if lon, err := strconv.ParseFloat(a.Lon, 64); err == nil {
+				keys = append(keys, "point:"+strconv.FormatFloat(lat, 'f', precision, 64)+","+
+					strconv.FormatFloat(lon, 'f', precision, 64)+";"+
+					strings.Join(tokenize(a.Street+" "+a.House), " "))
+			}
+		}
+		duplicate := false
+		for _, k := range keys {
+			duplicate = duplicate || seen[k]
+			seen[k] = true
+		}
+		if !duplicate {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func addressLine(a *Address) string {
+	if a.Value != "" {
+		return a.Value
+	}
+	return strings.Join([]string{a.Region, a.Area, a.City, a.Settlement, a.Street, a.House}, " ")
+}
+
+// tokenize splits s into lower case words of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(strings.ReplaceAll(s, "ё", "е")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
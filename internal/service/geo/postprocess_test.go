@@ -0,0 +1,104 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchRules_apply(t *testing.T) {
+	village := &Address{Region: "Московская", Area: "Одинцовский", Settlement: "Жаворонки", Street: "Лесная", House: "1"}
+	area := &Address{Region: "Московская", Area: "Одинцовский"}
+	region := &Address{Region: "Московская"}
+	snezhnaya := &Address{Value: "г Москва, ул Снежная", City: "Москва", Street: "Снежная", Lat: "55.8515097", Lon: "37.6465391"}
+	snezhnaya4 := &Address{City: "Москва", Street: "Снежная", House: "4", FiasID: "a", Lat: "55.8481373", Lon: "37.6414907"}
+	moscow := &Address{Value: "г Москва", City: "Москва", FiasID: "b", Lat: "55.75", Lon: "37.61"}
+	// the same house at another FIAS level and with rounding noise in the coordinates
+	snezhnaya4Again := &Address{City: "Москва", Street: "Снежная", House: "4", Lat: "55.848137", Lon: "37.641491"}
+	snezhnaya4Fias := &Address{City: "Москва", Street: "ул Снежная", House: "д 4", FiasID: "a"}
+
+	withCity := func(a *Address, city string) *Address {
+		c := *a
+		c.City = city
+		return &c
+	}
+
+	tests := []struct {
+		name      string
+		rules     SearchRules
+		query     string
+		opts      SearchOptions
+		addresses []*Address
+		want      []*Address
+	}{
+		{
+			name:      "no rules",
+			addresses: []*Address{snezhnaya, region},
+			want:      []*Address{snezhnaya, region},
+		},
+		{
+			name:      "city falls back to settlement and area",
+			rules:     SearchRules{RequireCity: true},
+			addresses: []*Address{village, area, region},
+			want:      []*Address{withCity(village, "Жаворонки"), withCity(area, "Одинцовский")},
+		},
+		{
+			name:      "street required",
+			rules:     SearchRules{RequireStreet: true},
+			addresses: []*Address{moscow, snezhnaya},
+			want:      []*Address{snezhnaya},
+		},
+		{
+			name:      "bounds lift the requirements",
+			rules:     SearchRules{RequireCity: true, RequireStreet: true},
+			opts:      SearchOptions{FromBound: GranularityRegion, ToBound: GranularityRegion},
+			addresses: []*Address{region},
+			want:      []*Address{region},
+		},
+		{
+			name:      "ranked by match",
+			rules:     SearchRules{Rank: true},
+			query:     "снежная 4",
+			addresses: []*Address{moscow, snezhnaya, snezhnaya4},
+			want:      []*Address{snezhnaya4, snezhnaya, moscow},
+		},
+		{
+			name:      "shorter address first on equal match",
+			rules:     SearchRules{Rank: true},
+			query:     "Москва",
+			addresses: []*Address{snezhnaya, moscow},
+			want:      []*Address{moscow, snezhnaya},
+		},
+		{
+			name:      "boosted locations keep the provider order",
+			rules:     SearchRules{Rank: true},
+			query:     "снежная 4",
+			opts:      SearchOptions{LocationsBoost: []BoostLocation{{KladrID: "77"}}},
+			addresses: []*Address{moscow, snezhnaya4},
+			want:      []*Address{moscow, snezhnaya4},
+		},
+		{
+			name:      "duplicates by FIAS ID and coordinates",
+			rules:     SearchRules{Dedupe: true, DedupePrecision: 5},
+			addresses: []*Address{snezhnaya4, snezhnaya, snezhnaya4Again, snezhnaya4Fias},
+			want:      []*Address{snezhnaya4, snezhnaya},
+		},
+		{
+			name:      "coordinates compared at the precision",
+			rules:     SearchRules{Dedupe: true, DedupePrecision: 7},
+			addresses: []*Address{snezhnaya4, snezhnaya4Again},
+			want:      []*Address{snezhnaya4, snezhnaya4Again},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addresses := append([]*Address(nil), tt.addresses...)
+			got := tt.rules.apply(tt.query, tt.opts, addresses)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if village.City != "" {
+		t.Errorf("the fallback changed the provider address")
+	}
+}
@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	auth "geo/internal/service/auth"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -32,22 +34,24 @@ func (_m *Auth) IsTokenRevoked(ctx context.Context, jti string) bool {
 }
 
 // Login provides a mock function with given fields: ctx, login, password
-func (_m *Auth) Login(ctx context.Context, login string, password string) (string, error) {
+func (_m *Auth) Login(ctx context.Context, login string, password string) (*auth.Tokens, error) {
 	ret := _m.Called(ctx, login, password)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Login")
 	}
 
-	var r0 string
+	var r0 *auth.Tokens
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*auth.Tokens, error)); ok {
 		return rf(ctx, login, password)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *auth.Tokens); ok {
 		r0 = rf(ctx, login, password)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Tokens)
+		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
@@ -77,6 +81,36 @@ func (_m *Auth) Logout(ctx context.Context, claims map[string]interface{}) error
 	return r0
 }
 
+// Refresh provides a mock function with given fields: ctx, refresh
+func (_m *Auth) Refresh(ctx context.Context, refresh string) (*auth.Tokens, error) {
+	ret := _m.Called(ctx, refresh)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *auth.Tokens
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*auth.Tokens, error)); ok {
+		return rf(ctx, refresh)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *auth.Tokens); ok {
+		r0 = rf(ctx, refresh)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Tokens)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refresh)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Register provides a mock function with given fields: ctx, login, password
 func (_m *Auth) Register(ctx context.Context, login string, password string) error {
 	ret := _m.Called(ctx, login, password)
@@ -14,9 +14,9 @@ type Geo struct {
 	mock.Mock
 }
 
-// Geocode provides a mock function with given fields: ctx, lat, lng
-func (_m *Geo) Geocode(ctx context.Context, lat string, lng string) ([]*geo.Address, error) {
-	ret := _m.Called(ctx, lat, lng)
+// Geocode provides a mock function with given fields: ctx, point, opts
+func (_m *Geo) Geocode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	ret := _m.Called(ctx, point, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Geocode")
@@ -24,19 +24,19 @@ func (_m *Geo) Geocode(ctx context.Context, lat string, lng string) ([]*geo.Addr
 
 	var r0 []*geo.Address
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*geo.Address, error)); ok {
-		return rf(ctx, lat, lng)
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Point, geo.GeocodeOptions) ([]*geo.Address, error)); ok {
+		return rf(ctx, point, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*geo.Address); ok {
-		r0 = rf(ctx, lat, lng)
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Point, geo.GeocodeOptions) []*geo.Address); ok {
+		r0 = rf(ctx, point, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*geo.Address)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, lat, lng)
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Point, geo.GeocodeOptions) error); ok {
+		r1 = rf(ctx, point, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -44,9 +44,9 @@ func (_m *Geo) Geocode(ctx context.Context, lat string, lng string) ([]*geo.Addr
 	return r0, r1
 }
 
-// Search provides a mock function with given fields: ctx, query
-func (_m *Geo) Search(ctx context.Context, query string) ([]*geo.Address, error) {
-	ret := _m.Called(ctx, query)
+// Search provides a mock function with given fields: ctx, query, opts
+func (_m *Geo) Search(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	ret := _m.Called(ctx, query, opts)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Search")
@@ -54,19 +54,19 @@ func (_m *Geo) Search(ctx context.Context, query string) ([]*geo.Address, error)
 
 	var r0 []*geo.Address
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*geo.Address, error)); ok {
-		return rf(ctx, query)
+	if rf, ok := ret.Get(0).(func(context.Context, string, geo.SearchOptions) ([]*geo.Address, error)); ok {
+		return rf(ctx, query, opts)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []*geo.Address); ok {
-		r0 = rf(ctx, query)
+	if rf, ok := ret.Get(0).(func(context.Context, string, geo.SearchOptions) []*geo.Address); ok {
+		r0 = rf(ctx, query, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*geo.Address)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, query)
+	if rf, ok := ret.Get(1).(func(context.Context, string, geo.SearchOptions) error); ok {
+		r1 = rf(ctx, query, opts)
 	} else {
 		r1 = ret.Error(1)
 	}
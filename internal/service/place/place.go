@@ -0,0 +1,380 @@
+package place
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"geo/db/placeStorage"
+	"geo/internal/infrastructure/repository/place"
+	"geo/internal/lib/logger/sl"
+	"geo/internal/lib/uuid"
+	"geo/internal/service/geo"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxLabelLength = 100
+	MaxTags        = 20
+	MaxTagLength   = 50
+	MaxPlaces      = 1000
+)
+
+var (
+	ErrNotFound        = errors.New("place not found")
+	ErrEmptyLabel      = errors.New("label cannot be empty")
+	ErrLabelTooLong    = fmt.Errorf("label cannot be longer than %d characters", MaxLabelLength)
+	ErrTooManyTags     = fmt.Errorf("at most %d tags are allowed", MaxTags)
+	ErrInvalidTag      = fmt.Errorf("tag cannot be empty or longer than %d characters", MaxTagLength)
+	ErrTooManyPlaces   = fmt.Errorf("at most %d places are allowed", MaxPlaces)
+	ErrInvalidSource   = errors.New("place must have exactly one of address, search or geocode")
+	ErrInvalidAddress  = errors.New("address must have valid lat and lon")
+	ErrAddressNotFound = errors.New("address not found")
+	ErrInternal        = errors.New("internal server error")
+)
+
+// Place is a place saved by a user.
+type Place struct {
+	ID      string      `json:"id" example:"1b4e28ba-2fa1-41d2-883f-0016d3cca427"`
+	Label   string      `json:"label" example:"Склад на Снежной"`
+	Tags    []string    `json:"tags" example:"depot,north"`
+	Address geo.Address `json:"address"`
+	// CreatedAt and UpdatedAt are in UTC
+	CreatedAt time.Time `json:"created_at" example:"2024-05-01T09:30:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-05-01T09:30:00Z"`
+} //@name Place
+
+// Source is where the address of a place comes from, exactly one of the fields is set.
+type Source struct {
+	// Address is an address as search or geocode returned it
+	Address *geo.Address
+	// Search is a query whose best match is saved
+	Search string
+	// Geocode is a point whose nearest address is saved
+	Geocode *geo.Point
+}
+
+type Input struct {
+	Label  string
+	Tags   []string
+	Source Source
+}
+
+// Patch changes the fields that are set, nil Tags keep the tags and empty Tags remove them.
+type Patch struct {
+	Label  *string
+	Tags   []string
+	Source *Source
+}
+
+type Storage interface {
+	Add(p placeStorage.Place) error
+	Get(owner, id string) (placeStorage.Place, error)
+	List(owner string) ([]placeStorage.Place, error)
+	Update(p placeStorage.Place) error
+	Delete(owner, id string) error
+}
+
+// Resolver finds the addresses places are created from.
+type Resolver interface {
+	Geocode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error)
+	Search(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error)
+}
+
+type UseCase struct {
+	log          *slog.Logger
+	requestIdKey string
+	storage      Storage
+	resolver     Resolver
+}
+
+func New(log *slog.Logger, requestIdKey string, storage Storage, resolver Resolver) *UseCase {
+	return &UseCase{log: log, requestIdKey: requestIdKey, storage: storage, resolver: resolver}
+}
+
+// ValidateLabel checks the label of a place.
+func ValidateLabel(label string) error {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return ErrEmptyLabel
+	}
+	if utf8.RuneCountInString(label) > MaxLabelLength {
+		return ErrLabelTooLong
+	}
+	return nil
+}
+
+// ValidateTags checks the tags of a place.
+func ValidateTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return ErrTooManyTags
+	}
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || utf8.RuneCountInString(t) > MaxTagLength {
+			return ErrInvalidTag
+		}
+	}
+	return nil
+}
+
+// ValidateSource checks that exactly one source is set and the set one is valid.
+func ValidateSource(src Source) error {
+	n := 0
+	if src.Address != nil {
+		n++
+	}
+	if strings.TrimSpace(src.Search) != "" {
+		n++
+	}
+	if src.Geocode != nil {
+		n++
+	}
+	if n != 1 {
+		return ErrInvalidSource
+	}
+	switch {
+	case src.Address != nil:
+		if _, err := addressPoint(src.Address); err != nil {
+			return ErrInvalidAddress
+		}
+	case src.Geocode != nil:
+		return src.Geocode.Validate()
+	}
+	return nil
+}
+
+// normalizeTags trims the tags and drops the ones repeated regardless of case.
+func normalizeTags(tags []string) []string {
+	res := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if !hasTag(res, t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func addressPoint(a *geo.Address) (geo.Point, error) {
+	lat, err := geo.ParseLatitude(a.Lat)
+	if err != nil {
+		return geo.Point{}, err
+	}
+	lon, err := geo.ParseLongitude(a.Lon)
+	if err != nil {
+		return geo.Point{}, err
+	}
+	return geo.Point{Lat: lat, Lon: lon}, nil
+}
+
+// Create saves a place of owner, its address is resolved from the source.
+func (s *UseCase) Create(ctx context.Context, owner string, in Input) (*Place, error) {
+	const op = "service.place.Create"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if err := ValidateLabel(in.Label); err != nil {
+		return nil, err
+	}
+	if err := ValidateTags(in.Tags); err != nil {
+		return nil, err
+	}
+	places, err := s.storage.List(owner)
+	if err != nil {
+		log.Error("failed to list places", sl.Err(err))
+		return nil, ErrInternal
+	}
+	if len(places) >= MaxPlaces {
+		return nil, ErrTooManyPlaces
+	}
+	address, err := s.resolve(ctx, in.Source)
+	if err != nil {
+		log.Error("failed to resolve place address", sl.Err(err))
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	p := placeStorage.Place{
+		ID:        uuid.New(),
+		Owner:     owner,
+		Label:     strings.TrimSpace(in.Label),
+		Tags:      normalizeTags(in.Tags),
+		Address:   address,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.storage.Add(p); err != nil {
+		log.Error("failed to save place", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("place created", slog.String("id", p.ID), slog.String("owner", owner))
+	return newPlace(p), nil
+}
+
+// List returns the places of owner oldest first, only the ones tagged with tag if it is set.
+func (s *UseCase) List(ctx context.Context, owner, tag string) ([]*Place, error) {
+	const op = "service.place.List"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	places, err := s.storage.List(owner)
+	if err != nil {
+		log.Error("failed to list places", sl.Err(err))
+		return nil, ErrInternal
+	}
+	tag = strings.TrimSpace(tag)
+	res := make([]*Place, 0, len(places))
+	for _, p := range places {
+		if tag == "" || hasTag(p.Tags, tag) {
+			res = append(res, newPlace(p))
+		}
+	}
+	log.Info("places listed", slog.String("owner", owner), slog.Int("count", len(res)))
+	return res, nil
+}
+
+func (s *UseCase) Get(ctx context.Context, owner, id string) (*Place, error) {
+	const op = "service.place.Get"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	p, err := s.storage.Get(owner, id)
+	if errors.Is(err, place.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Error("failed to get place", sl.Err(err))
+		return nil, ErrInternal
+	}
+	return newPlace(p), nil
+}
+
+// Update applies patch to a place of owner, a new source replaces the address.
+func (s *UseCase) Update(ctx context.Context, owner, id string, patch Patch) (*Place, error) {
+	const op = "service.place.Update"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	if patch.Label != nil {
+		if err := ValidateLabel(*patch.Label); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateTags(patch.Tags); err != nil {
+		return nil, err
+	}
+	p, err := s.storage.Get(owner, id)
+	if errors.Is(err, place.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Error("failed to get place", sl.Err(err))
+		return nil, ErrInternal
+	}
+	if patch.Source != nil {
+		address, err := s.resolve(ctx, *patch.Source)
+		if err != nil {
+			log.Error("failed to resolve place address", sl.Err(err))
+			return nil, err
+		}
+		p.Address = address
+	}
+	if patch.Label != nil {
+		p.Label = strings.TrimSpace(*patch.Label)
+	}
+	if patch.Tags != nil {
+		p.Tags = normalizeTags(patch.Tags)
+	}
+	p.UpdatedAt = time.Now().UTC()
+
+	err = s.storage.Update(p)
+	if errors.Is(err, place.ErrNotFound) {
+		// deleted while the address was resolved
+		return nil, ErrNotFound
+	} else if err != nil {
+		log.Error("failed to update place", sl.Err(err))
+		return nil, ErrInternal
+	}
+	log.Info("place updated", slog.String("id", id), slog.String("owner", owner))
+	return newPlace(p), nil
+}
+
+func (s *UseCase) Delete(ctx context.Context, owner, id string) error {
+	const op = "service.place.Delete"
+	requestID := ctx.Value(s.requestIdKey).(string)
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	err := s.storage.Delete(owner, id)
+	if errors.Is(err, place.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		log.Error("failed to delete place", sl.Err(err))
+		return ErrInternal
+	}
+	log.Info("place deleted", slog.String("id", id), slog.String("owner", owner))
+	return nil
+}
+
+// resolve returns the address of the source, the best match with coordinates
+// is taken from the search or geocode results.
+func (s *UseCase) resolve(ctx context.Context, src Source) (geo.Address, error) {
+	if err := ValidateSource(src); err != nil {
+		return geo.Address{}, err
+	}
+	var (
+		addresses []*geo.Address
+		err       error
+	)
+	switch {
+	case src.Address != nil:
+		return *src.Address, nil
+	case src.Geocode != nil:
+		addresses, err = s.resolver.Geocode(ctx, *src.Geocode, geo.GeocodeOptions{Count: 1})
+	default:
+		addresses, err = s.resolver.Search(ctx, src.Search, geo.SearchOptions{Count: 1})
+	}
+	if err != nil {
+		return geo.Address{}, err
+	}
+	for _, a := range addresses {
+		if _, err := addressPoint(a); err == nil {
+			return *a, nil
+		}
+	}
+	return geo.Address{}, ErrAddressNotFound
+}
+
+func newPlace(p placeStorage.Place) *Place {
+	tags := p.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &Place{
+		ID:        p.ID,
+		Label:     p.Label,
+		Tags:      tags,
+		Address:   p.Address,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
@@ -0,0 +1,221 @@
+package place
+
+import (
+	"context"
+	"errors"
+	"geo/db/placeStorage/inMemoryPlaceStorage"
+	"geo/internal/infrastructure/repository/place"
+	"geo/internal/service/geo"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stubResolver struct {
+	err error
+}
+
+func (s *stubResolver) Geocode(ctx context.Context, point geo.Point, opts geo.GeocodeOptions) ([]*geo.Address, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if point.Lat == 0 && point.Lon == 0 {
+		return nil, nil
+	}
+	return []*geo.Address{{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"}}, nil
+}
+
+func (s *stubResolver) Search(ctx context.Context, query string, opts geo.SearchOptions) ([]*geo.Address, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	switch query {
+	case "Москва, Снежная 4":
+		return []*geo.Address{
+			{City: "Москва", Street: "Снежная"},
+			{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"},
+		}, nil
+	case "без координат":
+		return []*geo.Address{{City: "Москва", Street: "Снежная"}}, nil
+	}
+	return nil, nil
+}
+
+func newUseCase(resolver Resolver) *UseCase {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	return New(log, "request_id", place.New(inMemoryPlaceStorage.New()), resolver)
+}
+
+func TestUseCase_Create(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	snezhnaya := &geo.Address{City: "Москва", Street: "Снежная", House: "4", Lat: "55.8481373", Lon: "37.6414907"}
+
+	tests := []struct {
+		name        string
+		in          Input
+		resolverErr error
+		wantHouse   string
+		wantTags    []string
+		wantErr     error
+	}{
+		{
+			name:      "address",
+			in:        Input{Label: " Склад ", Tags: []string{"depot", " Depot", "north"}, Source: Source{Address: snezhnaya}},
+			wantHouse: "4",
+			wantTags:  []string{"depot", "north"},
+		},
+		{
+			name:      "search takes the first match with coordinates",
+			in:        Input{Label: "Склад", Source: Source{Search: "Москва, Снежная 4"}},
+			wantHouse: "4",
+			wantTags:  []string{},
+		},
+		{
+			name:      "geocode",
+			in:        Input{Label: "Склад", Source: Source{Geocode: &geo.Point{Lat: 55.8481373, Lon: 37.6414907}}},
+			wantHouse: "4",
+			wantTags:  []string{},
+		},
+		{
+			name:    "search without coordinates",
+			in:      Input{Label: "Склад", Source: Source{Search: "без координат"}},
+			wantErr: ErrAddressNotFound,
+		},
+		{
+			name:    "nothing found",
+			in:      Input{Label: "Склад", Source: Source{Geocode: &geo.Point{}}},
+			wantErr: ErrAddressNotFound,
+		},
+		{
+			name:    "two sources",
+			in:      Input{Label: "Склад", Source: Source{Address: snezhnaya, Search: "Москва, Снежная 4"}},
+			wantErr: ErrInvalidSource,
+		},
+		{
+			name:    "address without coordinates",
+			in:      Input{Label: "Склад", Source: Source{Address: &geo.Address{City: "Москва"}}},
+			wantErr: ErrInvalidAddress,
+		},
+		{
+			name:    "invalid point",
+			in:      Input{Label: "Склад", Source: Source{Geocode: &geo.Point{Lat: 91}}},
+			wantErr: geo.ErrInvalidPoint,
+		},
+		{
+			name:    "empty label",
+			in:      Input{Label: "  ", Source: Source{Address: snezhnaya}},
+			wantErr: ErrEmptyLabel,
+		},
+		{
+			name:    "empty tag",
+			in:      Input{Label: "Склад", Tags: []string{""}, Source: Source{Address: snezhnaya}},
+			wantErr: ErrInvalidTag,
+		},
+		{
+			name:        "upstream timeout",
+			in:          Input{Label: "Склад", Source: Source{Search: "Москва, Снежная 4"}},
+			resolverErr: geo.ErrUpstreamTimeout,
+			wantErr:     geo.ErrUpstreamTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newUseCase(&stubResolver{err: tt.resolverErr})
+			got, err := uc.Create(ctx, "alice", tt.in)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), "error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, got.ID)
+			require.Equal(t, "Склад", got.Label)
+			require.Equal(t, tt.wantTags, got.Tags)
+			require.Equal(t, tt.wantHouse, got.Address.House)
+			require.False(t, got.CreatedAt.IsZero())
+
+			stored, err := uc.Get(ctx, "alice", got.ID)
+			require.NoError(t, err)
+			require.Equal(t, got, stored)
+		})
+	}
+}
+
+func TestUseCase_Owner(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(&stubResolver{})
+	address := &geo.Address{City: "Москва", Lat: "55.75", Lon: "37.61"}
+
+	p, err := uc.Create(ctx, "alice", Input{Label: "Офис", Tags: []string{"work"}, Source: Source{Address: address}})
+	require.NoError(t, err)
+	_, err = uc.Create(ctx, "alice", Input{Label: "Дом", Source: Source{Address: address}})
+	require.NoError(t, err)
+
+	_, err = uc.Get(ctx, "bob", p.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = uc.Update(ctx, "bob", p.ID, Patch{Tags: []string{}})
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, uc.Delete(ctx, "bob", p.ID), ErrNotFound)
+
+	places, err := uc.List(ctx, "bob", "")
+	require.NoError(t, err)
+	require.Empty(t, places)
+	places, err = uc.List(ctx, "alice", "")
+	require.NoError(t, err)
+	require.Len(t, places, 2)
+	places, err = uc.List(ctx, "alice", "WORK")
+	require.NoError(t, err)
+	require.Len(t, places, 1)
+	require.Equal(t, p.ID, places[0].ID)
+
+	require.NoError(t, uc.Delete(ctx, "alice", p.ID))
+	_, err = uc.Get(ctx, "alice", p.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUseCase_MaxPlaces(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(&stubResolver{})
+	address := &geo.Address{City: "Москва", Lat: "55.75", Lon: "37.61"}
+	for i := 0; i < MaxPlaces; i++ {
+		_, err := uc.Create(ctx, "alice", Input{Label: "Склад", Source: Source{Address: address}})
+		require.NoError(t, err)
+	}
+	_, err := uc.Create(ctx, "alice", Input{Label: "Склад", Source: Source{Address: address}})
+	require.ErrorIs(t, err, ErrTooManyPlaces)
+	_, err = uc.Create(ctx, "bob", Input{Label: "Склад", Source: Source{Address: address}})
+	require.NoError(t, err, "the limit is per owner")
+}
+
+func TestUseCase_Update(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "request_id", "test")
+	uc := newUseCase(&stubResolver{})
+	p, err := uc.Create(ctx, "alice", Input{
+		Label:  "Офис",
+		Tags:   []string{"work"},
+		Source: Source{Address: &geo.Address{City: "Москва", Lat: "55.75", Lon: "37.61"}},
+	})
+	require.NoError(t, err)
+
+	label := "Склад"
+	got, err := uc.Update(ctx, "alice", p.ID, Patch{Label: &label})
+	require.NoError(t, err)
+	require.Equal(t, "Склад", got.Label)
+	require.Equal(t, []string{"work"}, got.Tags, "nil tags keep the tags")
+	require.Equal(t, "Москва", got.Address.City)
+	require.False(t, got.UpdatedAt.Before(p.UpdatedAt))
+	require.Equal(t, p.CreatedAt, got.CreatedAt)
+
+	got, err = uc.Update(ctx, "alice", p.ID, Patch{Tags: []string{}, Source: &Source{Search: "Москва, Снежная 4"}})
+	require.NoError(t, err)
+	require.Equal(t, "Склад", got.Label)
+	require.Empty(t, got.Tags)
+	require.Equal(t, "Снежная", got.Address.Street)
+
+	empty := ""
+	_, err = uc.Update(ctx, "alice", p.ID, Patch{Label: &empty})
+	require.ErrorIs(t, err, ErrEmptyLabel)
+	_, err = uc.Update(ctx, "alice", p.ID, Patch{Source: &Source{}})
+	require.ErrorIs(t, err, ErrInvalidSource)
+}